@@ -8,6 +8,7 @@ import (
 	"github.com/MarBalueva/dashboard_efficiency/internal/config"
 	"github.com/MarBalueva/dashboard_efficiency/internal/db"
 	"github.com/MarBalueva/dashboard_efficiency/internal/routes"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -36,6 +37,24 @@ func main() {
 		log.Fatal("failed to load config:", err)
 	}
 
+	keys, err := services.LoadKeySet(services.KeySetConfig{
+		Algorithm:      cfg.JWTAlgorithm,
+		SigningKeyFile: cfg.JWTSigningKeyFile,
+		SigningKeyID:   cfg.JWTKeyID,
+		VerifyKeyFiles: cfg.JWTVerifyKeyFiles,
+		Secret:         cfg.JWTSecret,
+	})
+	if err != nil {
+		log.Fatal("jwt keys error: ", err)
+	}
+	services.InitKeySet(keys)
+
+	fieldKeys, err := services.NewFieldKeyring(cfg.FieldEncryptionKeys, cfg.FieldEncryptionKeyID)
+	if err != nil {
+		log.Fatal("field encryption keys error: ", err)
+	}
+	services.InitFieldEncryption(fieldKeys)
+
 	if err := db.Connect(cfg); err != nil {
 		log.Fatal("db connect error:", err)
 	}
@@ -43,13 +62,104 @@ func main() {
 	if err := db.Migrate(); err != nil {
 		log.Fatal("migration error:", err)
 	}
+	if n, err := services.RotateFieldEncryption(db.DB); err != nil {
+		log.Fatal("field encryption rotation error:", err)
+	} else if n > 0 {
+		log.Printf("field encryption: re-encrypted %d employee HR records with key %q", n, fieldKeys.ActiveKeyID())
+	}
+
+	services.InitPermissions(db.DB)
+	if err := services.SeedPermissions(db.DB); err != nil {
+		log.Fatal("permissions seed error:", err)
+	}
+
+	// Счётчик без блокировки нужен, пока действует пауза и пока ошибки
+	// могут привести к блокировке.
+	retention := max(cfg.LoginLockoutDuration, cfg.LoginBackoffMax)
+	var attempts services.AttemptStore = services.NewMemoryAttemptStore(retention, services.DefaultMemoryAttemptEntries)
+	if cfg.LoginAttemptStore == "postgres" {
+		attempts = services.NewPostgresAttemptStore(db.DB)
+	}
+	services.InitLoginThrottle(&services.LoginThrottle{
+		Store:           attempts,
+		MaxFailures:     cfg.LoginMaxFailures,
+		IPMaxFailures:   cfg.LoginIPMaxFailures,
+		LockoutDuration: cfg.LoginLockoutDuration,
+		BackoffBase:     cfg.LoginBackoffBase,
+		BackoffMax:      cfg.LoginBackoffMax,
+	})
+
+	services.InitTwoFactor(cfg.TwoFactorIssuer, cfg.TwoFactorRequiredGroups)
+
+	denyList, err := services.LoadDenyList(cfg.PasswordDenyListFile)
+	if err != nil {
+		log.Fatal("password deny list error:", err)
+	}
+	services.InitPasswordPolicy(&services.PasswordPolicy{
+		MinLength:  cfg.PasswordMinLength,
+		MinClasses: cfg.PasswordMinClasses,
+		History:    cfg.PasswordHistory,
+		DenyList:   denyList,
+	})
+	services.InitPasswordReset(cfg.PasswordResetTTL, cfg.AppBaseURL)
+	if cfg.MailSender == "file" {
+		services.InitMailSender(services.FileMailSender{Dir: cfg.MailDir})
+	}
+
+	if cfg.OIDCIssuer != "" {
+		services.InitOIDC(services.NewOIDCProvider(services.OIDCConfig{
+			Issuer:              cfg.OIDCIssuer,
+			ClientID:            cfg.OIDCClientID,
+			ClientSecret:        cfg.OIDCClientSecret,
+			RedirectURL:         cfg.OIDCRedirectURL,
+			Scopes:              cfg.OIDCScopes,
+			GroupsClaim:         cfg.OIDCGroupsClaim,
+			GroupMap:            cfg.OIDCGroupMap,
+			AutoProvision:       cfg.OIDCAutoProvision,
+			SyncGroups:          cfg.OIDCSyncGroups,
+			TrustedMFA:          cfg.OIDCTrustedMFA,
+			FrontendRedirectURL: cfg.OIDCFrontendRedirect,
+		}, nil))
+	}
+
+	services.InitAPITokens(cfg.APITokenMaxTTL)
+	services.InitImpersonation(cfg.ImpersonationTTL)
+	services.InitTrash(cfg.TrashRetention)
+	services.StartTrashPurger(db.DB, cfg.TrashPurgeInterval)
+
+	var providers []services.AuthProvider
+	for _, name := range cfg.AuthProviders {
+		switch name {
+		case "local":
+			providers = append(providers, &services.LocalProvider{AllowedGroups: cfg.AuthLocalGroups})
+		case "ldap":
+			providers = append(providers, services.NewLDAPProvider(services.LDAPConfig{
+				URL:                cfg.LDAPURL,
+				StartTLS:           cfg.LDAPStartTLS,
+				InsecureSkipVerify: cfg.LDAPInsecureSkipVerify,
+				CAFile:             cfg.LDAPCAFile,
+				Timeout:            cfg.LDAPTimeout,
+				BindDN:             cfg.LDAPBindDN,
+				BindPassword:       cfg.LDAPBindPassword,
+				BaseDN:             cfg.LDAPBaseDN,
+				UserFilter:         cfg.LDAPUserFilter,
+				LoginAttribute:     cfg.LDAPLoginAttribute,
+				GroupAttribute:     cfg.LDAPGroupAttribute,
+				GroupMap:           cfg.LDAPGroupMap,
+				AutoProvision:      cfg.LDAPAutoProvision,
+				SyncGroups:         cfg.LDAPSyncGroups,
+				LocalGroups:        cfg.AuthLocalGroups,
+			}))
+		}
+	}
+	services.InitAuthProviders(providers...)
 
 	r := gin.Default()
 	corsCfg := cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173"}, // фронт dev
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
@@ -23,6 +23,134 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Набор ключей (JWKS, RFC 7517) для проверки подписи access-токенов другими сервисами.\nТем же ключом подписываются служебные токены: сервисам нужно проверять aud = employee-dashboard-api.\nКлюч выбирается по заголовку kid токена. При ротации здесь публикуются и предыдущие ключи.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Открытые ключи проверки токенов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает события изменения данных, новые сверху.\nПериод задаётся датами в формате YYYY-MM-DD (date_to включительно) или RFC3339.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Журнал аудита",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя, выполнившего действие",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID администратора, действовавшего от имени пользователя",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Действие (create, update, delete, import, ...)",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тип сущности (employee, department, user, ...)",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID сущности",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода",
+                        "name": "date_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей (по умолчанию 50, максимум 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuditListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/api/dashboard/summary": {
             "get": {
                 "security": [
@@ -30,7 +158,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает показатели сотрудников (admin/manager — все, employee — свои)",
+                "description": "Возвращает показатели сотрудников: с правом dashboard.read_all — по закреплённым отделам\n(с правом scope.all_departments — по всем), иначе — только свои. Линейный руководитель всегда видит данные своих подчинённых",
                 "consumes": [
                     "application/json"
                 ],
@@ -74,6 +202,63 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/dashboard/turnover": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Численность на начало и конец периода, приёмы, повторные приёмы и увольнения (с разбивкой по причинам)\nи помесячная динамика. Считается по истории кадровых записей, поэтому уволенные сотрудники\nучитываются в периодах, когда работали. Период по умолчанию — последние 12 месяцев, не более 36.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dashboard"
+                ],
+                "summary": "Текучесть кадров",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (YYYY-MM-DD)",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (YYYY-MM-DD, включительно)",
+                        "name": "date_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TurnoverResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/api/dict/access-groups": {
             "get": {
                 "security": [
@@ -83,29 +268,34 @@ const docTemplate = `{
                     {
                         "BearerAuth": []
                     },
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает список отделов (без удалённых)\nВозвращает список должностей (без удалённых)\nВозвращает групп доступа (без удалённых)",
+                "description": "Возвращает список отделов (без удалённых)\nВозвращает список должностей (без удалённых)\nВозвращает групп доступа (без удалённых)\nВозвращает список причин увольнения (без удалённых)",
                 "produces": [
+                    "application/json",
                     "application/json",
                     "application/json",
                     "application/json"
                 ],
                 "tags": [
+                    "dictionary",
                     "dictionary",
                     "dictionary",
                     "dictionary"
                 ],
-                "summary": "Получить список групп доступа",
+                "summary": "Получить список причин увольнения",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.AccessGroup"
+                                "$ref": "#/definitions/models.TerminationReason"
                             }
                         }
                     },
@@ -128,27 +318,33 @@ const docTemplate = `{
                     {
                         "BearerAuth": []
                     },
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Создание новой записи в справочнике отделов\nДобавляет новую должность в справочник\nСоздаёт новую группу доступа",
+                "description": "Создание новой записи в справочнике отделов\nДобавляет новую должность в справочник\nСоздаёт новую группу доступа\nДобавляет причину увольнения в справочник",
                 "consumes": [
+                    "application/json",
                     "application/json",
                     "application/json",
                     "application/json"
                 ],
                 "produces": [
+                    "application/json",
                     "application/json",
                     "application/json",
                     "application/json"
                 ],
                 "tags": [
+                    "dictionary",
                     "dictionary",
                     "dictionary",
                     "dictionary"
                 ],
-                "summary": "Создать группу доступа",
+                "summary": "Создать причину увольнения",
                 "parameters": [
                     {
                         "description": "Данные справочника",
@@ -176,6 +372,15 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.DictionaryRequest"
                         }
+                    },
+                    {
+                        "description": "Данные причины увольнения",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -217,6 +422,12 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
                     }
                 }
             }
@@ -230,27 +441,33 @@ const docTemplate = `{
                     {
                         "BearerAuth": []
                     },
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновляет существующую запись справочника\nОбновляет данные должности\nОбновляет данные группы доступа",
+                "description": "Обновляет существующую запись справочника\nОбновляет данные должности\nОбновляет данные группы доступа. Код группы, в которой есть пользователи, изменить нельзя (409).\nОбновляет причину увольнения",
                 "consumes": [
+                    "application/json",
                     "application/json",
                     "application/json",
                     "application/json"
                 ],
                 "produces": [
+                    "application/json",
                     "application/json",
                     "application/json",
                     "application/json"
                 ],
                 "tags": [
+                    "dictionary",
                     "dictionary",
                     "dictionary",
                     "dictionary"
                 ],
-                "summary": "Обновить группу доступа",
+                "summary": "Обновить причину увольнения",
                 "parameters": [
                     {
                         "type": "integer",
@@ -299,6 +516,22 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.DictionaryRequest"
                         }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID причины увольнения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные причины увольнения",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -349,6 +582,21 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
                     }
                 }
             },
@@ -360,22 +608,27 @@ const docTemplate = `{
                     {
                         "BearerAuth": []
                     },
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Помечает запись как удалённую (soft delete)\nПомечает должность как удалённую\nПомечает группу доступа как удалённую",
+                "description": "Помечает запись как удалённую (soft delete)\nПомечает должность как удалённую\nПомечает группу доступа как удалённую\nПомечает причину увольнения как удалённую; в истории сотрудников она сохраняется",
                 "produces": [
+                    "application/json",
                     "application/json",
                     "application/json",
                     "application/json"
                 ],
                 "tags": [
+                    "dictionary",
                     "dictionary",
                     "dictionary",
                     "dictionary"
                 ],
-                "summary": "Удалить должность",
+                "summary": "Удалить причину увольнения",
                 "parameters": [
                     {
                         "type": "integer",
@@ -397,6 +650,13 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID причины увольнения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -442,43 +702,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/dict/departments": {
+        "/api/dict/access-groups/{id}/permissions": {
             "get": {
                 "security": [
-                    {
-                        "BearerAuth": []
-                    },
-                    {
-                        "BearerAuth": []
-                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает список отделов (без удалённых)\nВозвращает список должностей (без удалённых)\nВозвращает групп доступа (без удалённых)",
                 "produces": [
-                    "application/json",
-                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "dictionary",
-                    "dictionary",
-                    "dictionary"
+                    "permissions"
+                ],
+                "summary": "Права группы доступа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID группы доступа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Получить список групп доступа",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.AccessGroup"
+                                "$ref": "#/definitions/models.PermissionResponse"
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -488,61 +755,38 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
-                    {
-                        "BearerAuth": []
-                    },
-                    {
-                        "BearerAuth": []
-                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Создание новой записи в справочнике отделов\nДобавляет новую должность в справочник\nСоздаёт новую группу доступа",
+                "description": "Полностью заменяет набор прав группы. Изменения применяются без перевыпуска токенов.",
                 "consumes": [
-                    "application/json",
-                    "application/json",
                     "application/json"
                 ],
                 "produces": [
-                    "application/json",
-                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "dictionary",
-                    "dictionary",
-                    "dictionary"
+                    "permissions"
                 ],
-                "summary": "Создать группу доступа",
+                "summary": "Задать права группы доступа",
                 "parameters": [
                     {
-                        "description": "Данные справочника",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.DictionaryRequest"
-                        }
-                    },
-                    {
-                        "description": "Данные должности",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.DictionaryRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID группы доступа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "description": "Данные группы доступа",
+                        "description": "Коды прав",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.DictionaryRequest"
+                            "$ref": "#/definitions/models.AccessGroupPermissionsRequest"
                         }
                     }
                 ],
@@ -552,19 +796,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "allOf": [
-                                    {
-                                        "type": "string"
-                                    },
-                                    {
-                                        "type": "object",
-                                        "properties": {
-                                            "message": {
-                                                "type": "string"
-                                            }
-                                        }
-                                    }
-                                ]
+                                "type": "string"
                             }
                         }
                     },
@@ -585,87 +817,150 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/api/dict/departments/{id}": {
-            "put": {
+        "/api/dict/attributes": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает действующие дополнительные поля карточки сотрудника со значениями справочников для enum",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dictionary"
+                ],
+                "summary": "Дополнительные поля сотрудников",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CustomAttributeResponse"
+                            }
+                        }
                     },
-                    {
-                        "BearerAuth": []
-                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновляет существующую запись справочника\nОбновляет данные должности\nОбновляет данные группы доступа",
+                "description": "Добавляет поле карточки сотрудника. Значения справочника для enum добавляются отдельно.\nОбязательное поле проверяется при создании сотрудника и при изменении его дополнительных полей.",
                 "consumes": [
-                    "application/json",
-                    "application/json",
                     "application/json"
                 ],
                 "produces": [
-                    "application/json",
-                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "dictionary",
-                    "dictionary",
                     "dictionary"
                 ],
-                "summary": "Обновить группу доступа",
+                "summary": "Создать дополнительное поле",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID записи",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Данные справочника",
+                        "description": "Описание поля",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.DictionaryRequest"
+                            "$ref": "#/definitions/models.CustomAttributeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomAttributeResponse"
                         }
                     },
-                    {
-                        "type": "integer",
-                        "description": "ID должности",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
-                    {
-                        "description": "Новые данные должности",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/models.DictionaryRequest"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/dict/attributes/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изменяет описание поля. Тип поля, у которого уже есть значения, изменить нельзя;\nновые ограничения применяются к значениям при следующем их изменении.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "dictionary"
+                ],
+                "summary": "Обновить дополнительное поле",
+                "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID группы доступа",
+                        "description": "ID поля",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Новые данные группы доступа",
+                        "description": "Описание поля",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.DictionaryRequest"
+                            "$ref": "#/definitions/models.CustomAttributeRequest"
                         }
                     }
                 ],
@@ -673,22 +968,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "allOf": [
-                                    {
-                                        "type": "string"
-                                    },
-                                    {
-                                        "type": "object",
-                                        "properties": {
-                                            "message": {
-                                                "type": "string"
-                                            }
-                                        }
-                                    }
-                                ]
-                            }
+                            "$ref": "#/definitions/models.CustomAttributeResponse"
                         }
                     },
                     "400": {
@@ -717,51 +997,33 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
                     }
                 }
             },
             "delete": {
                 "security": [
-                    {
-                        "BearerAuth": []
-                    },
-                    {
-                        "BearerAuth": []
-                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Помечает запись как удалённую (soft delete)\nПомечает должность как удалённую\nПомечает группу доступа как удалённую",
+                "description": "Помечает поле как удалённое: оно пропадает из карточек, значения сохраняются и\nвозвращаются при восстановлении поля через /api/trash",
                 "produces": [
-                    "application/json",
-                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "dictionary",
-                    "dictionary",
                     "dictionary"
                 ],
-                "summary": "Удалить должность",
+                "summary": "Удалить дополнительное поле",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID записи",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "ID должности",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "ID группы доступа",
+                        "description": "ID поля",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -810,134 +1072,69 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/dict/positions": {
-            "get": {
+        "/api/dict/attributes/{id}/options": {
+            "post": {
                 "security": [
-                    {
-                        "BearerAuth": []
-                    },
-                    {
-                        "BearerAuth": []
-                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает список отделов (без удалённых)\nВозвращает список должностей (без удалённых)\nВозвращает групп доступа (без удалённых)",
+                "description": "Добавляет значение в справочник дополнительного поля типа enum",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "application/json",
-                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "dictionary",
-                    "dictionary",
                     "dictionary"
                 ],
-                "summary": "Получить список групп доступа",
+                "summary": "Добавить значение справочника поля",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID поля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Значение справочника",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CustomAttributeOptionRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.AccessGroup"
-                            }
+                            "$ref": "#/definitions/models.CustomAttributeOptionResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            },
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
                     },
-                    {
-                        "BearerAuth": []
-                    },
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Создание новой записи в справочнике отделов\nДобавляет новую должность в справочник\nСоздаёт новую группу доступа",
-                "consumes": [
-                    "application/json",
-                    "application/json",
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json",
-                    "application/json",
-                    "application/json"
-                ],
-                "tags": [
-                    "dictionary",
-                    "dictionary",
-                    "dictionary"
-                ],
-                "summary": "Создать группу доступа",
-                "parameters": [
-                    {
-                        "description": "Данные справочника",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.DictionaryRequest"
-                        }
-                    },
-                    {
-                        "description": "Данные должности",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.DictionaryRequest"
-                        }
-                    },
-                    {
-                        "description": "Данные группы доступа",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.DictionaryRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "allOf": [
-                                    {
-                                        "type": "string"
-                                    },
-                                    {
-                                        "type": "object",
-                                        "properties": {
-                                            "message": {
-                                                "type": "string"
-                                            }
-                                        }
-                                    }
-                                ]
+                                "type": "string"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -945,95 +1142,55 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/dict/positions/{id}": {
+        "/api/dict/attributes/{id}/options/{option_id}": {
             "put": {
                 "security": [
-                    {
-                        "BearerAuth": []
-                    },
-                    {
-                        "BearerAuth": []
-                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновляет существующую запись справочника\nОбновляет данные должности\nОбновляет данные группы доступа",
+                "description": "Изменяет значение справочника поля типа enum; при смене кода он меняется и у сотрудников",
                 "consumes": [
-                    "application/json",
-                    "application/json",
                     "application/json"
                 ],
                 "produces": [
-                    "application/json",
-                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "dictionary",
-                    "dictionary",
                     "dictionary"
                 ],
-                "summary": "Обновить группу доступа",
+                "summary": "Обновить значение справочника поля",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID записи",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Данные справочника",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.DictionaryRequest"
-                        }
-                    },
-                    {
-                        "type": "integer",
-                        "description": "ID должности",
+                        "description": "ID поля",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
-                    {
-                        "description": "Новые данные должности",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.DictionaryRequest"
-                        }
-                    },
                     {
                         "type": "integer",
-                        "description": "ID группы доступа",
-                        "name": "id",
+                        "description": "ID значения",
+                        "name": "option_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Новые данные группы доступа",
+                        "description": "Значение справочника",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.DictionaryRequest"
+                            "$ref": "#/definitions/models.CustomAttributeOptionRequest"
                         }
                     }
                 ],
@@ -1041,22 +1198,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "allOf": [
-                                    {
-                                        "type": "string"
-                                    },
-                                    {
-                                        "type": "object",
-                                        "properties": {
-                                            "message": {
-                                                "type": "string"
-                                            }
-                                        }
-                                    }
-                                ]
-                            }
+                            "$ref": "#/definitions/models.CustomAttributeOptionResponse"
                         }
                     },
                     "400": {
@@ -1085,52 +1227,41 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
                     }
                 }
             },
             "delete": {
                 "security": [
-                    {
-                        "BearerAuth": []
-                    },
-                    {
-                        "BearerAuth": []
-                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Помечает запись как удалённую (soft delete)\nПомечает должность как удалённую\nПомечает группу доступа как удалённую",
+                "description": "Помечает значение как удалённое: у сотрудников оно сохраняется, но новым не назначается",
                 "produces": [
-                    "application/json",
-                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "dictionary",
-                    "dictionary",
                     "dictionary"
                 ],
-                "summary": "Удалить должность",
+                "summary": "Удалить значение справочника поля",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID записи",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "ID должности",
+                        "description": "ID поля",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "ID группы доступа",
-                        "name": "id",
+                        "description": "ID значения",
+                        "name": "option_id",
                         "in": "path",
                         "required": true
                     }
@@ -1178,28 +1309,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/employees": {
+        "/api/dict/departments": {
             "get": {
                 "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "admin и manager видят всех, employee — только себя",
+                "description": "Возвращает список отделов (без удалённых)\nВозвращает список должностей (без удалённых)\nВозвращает групп доступа (без удалённых)\nВозвращает список причин увольнения (без удалённых)",
                 "produces": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "employees"
+                    "dictionary",
+                    "dictionary",
+                    "dictionary",
+                    "dictionary"
                 ],
-                "summary": "Список сотрудников",
+                "summary": "Получить список причин увольнения",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.EmployeeFullResponse"
+                                "$ref": "#/definitions/models.TerminationReason"
                             }
                         }
                     },
@@ -1216,29 +1362,74 @@ const docTemplate = `{
             },
             "post": {
                 "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Создаёт сотрудника и связанные кадровые данные (Department, Position, HR-поля)",
+                "description": "Создание новой записи в справочнике отделов\nДобавляет новую должность в справочник\nСоздаёт новую группу доступа\nДобавляет причину увольнения в справочник",
                 "consumes": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
                     "application/json"
                 ],
                 "produces": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "employees"
+                    "dictionary",
+                    "dictionary",
+                    "dictionary",
+                    "dictionary"
                 ],
-                "summary": "Создать сотрудника",
+                "summary": "Создать причину увольнения",
                 "parameters": [
                     {
-                        "description": "Данные сотрудника и кадровые данные",
+                        "description": "Данные справочника",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.EmployeeCreateRequest"
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    },
+                    {
+                        "description": "Данные должности",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    },
+                    {
+                        "description": "Данные группы доступа",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    },
+                    {
+                        "description": "Данные причины увольнения",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
                         }
                     }
                 ],
@@ -1282,210 +1473,114 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/employees/work": {
-            "get": {
+        "/api/dict/departments/{id}": {
+            "put": {
                 "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "employee видит только свои данные, admin и manager — все",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "employees"
-                ],
-                "summary": "Получить таблицу сотрудников",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.EmployeeWorkSummary"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            }
-        },
-        "/api/employees/work/{employee_id}": {
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Удаляет рабочий день, процессы и метрики сотрудника по employee_id (soft delete)",
+                "description": "Обновляет существующую запись справочника\nОбновляет данные должности\nОбновляет данные группы доступа. Код группы, в которой есть пользователи, изменить нельзя (409).\nОбновляет причину увольнения",
                 "consumes": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
                     "application/json"
                 ],
                 "produces": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "employees"
+                    "dictionary",
+                    "dictionary",
+                    "dictionary",
+                    "dictionary"
                 ],
-                "summary": "Удалить сотрудника",
+                "summary": "Обновить причину увольнения",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID сотрудника",
-                        "name": "employee_id",
+                        "description": "ID записи",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    {
+                        "description": "Данные справочника",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.DictionaryRequest"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            }
-        },
-        "/api/employees/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "employees"
-                ],
-                "summary": "Получить сотрудника по ID",
-                "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID сотрудника",
+                        "description": "ID должности",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.EmployeeFullResponse"
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    {
+                        "description": "Новые данные должности",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.DictionaryRequest"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                    {
+                        "type": "integer",
+                        "description": "ID группы доступа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
-                    "404": {
-                        "description": "Not Found",
+                    {
+                        "description": "Новые данные группы доступа",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.DictionaryRequest"
                         }
-                    }
-                }
-            },
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Обновляет персональные и кадровые данные сотрудника",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "employees"
-                ],
-                "summary": "Обновить сотрудника",
-                "parameters": [
+                    },
                     {
                         "type": "integer",
-                        "description": "ID сотрудника",
+                        "description": "ID причины увольнения",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Новые данные сотрудника",
+                        "description": "Новые данные причины увольнения",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.EmployeeCreateRequest"
+                            "$ref": "#/definitions/models.DictionaryRequest"
                         }
                     }
                 ],
@@ -1538,35 +1633,77 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
                     }
                 }
             },
             "delete": {
                 "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Помечает сотрудника и его кадровые данные как удалённые (soft delete)",
+                "description": "Помечает запись как удалённую (soft delete)\nПомечает должность как удалённую\nПомечает группу доступа как удалённую\nПомечает причину увольнения как удалённую; в истории сотрудников она сохраняется",
                 "produces": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "employees"
+                    "dictionary",
+                    "dictionary",
+                    "dictionary",
+                    "dictionary"
                 ],
-                "summary": "Удалить сотрудника",
+                "summary": "Удалить причину увольнения",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID сотрудника",
+                        "description": "ID записи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID должности",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID группы доступа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID причины увольнения",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1611,53 +1748,52 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
                     }
                 }
             }
         },
-        "/api/profile": {
+        "/api/dict/positions": {
             "get": {
                 "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает данные авторизованного пользователя и связанного с ним сотрудника.\n\nДанные пользователя:\n- login\n\nДанные сотрудника:\n- фамилия\n- имя\n- отчество",
+                "description": "Возвращает список отделов (без удалённых)\nВозвращает список должностей (без удалённых)\nВозвращает групп доступа (без удалённых)\nВозвращает список причин увольнения (без удалённых)",
                 "produces": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "profile"
+                    "dictionary",
+                    "dictionary",
+                    "dictionary",
+                    "dictionary"
                 ],
-                "summary": "Получить профиль текущего пользователя",
+                "summary": "Получить список причин увольнения",
                 "responses": {
                     "200": {
-                        "description": "Профиль пользователя",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "401": {
-                        "description": "Неавторизован",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TerminationReason"
                             }
                         }
                     },
-                    "404": {
-                        "description": "Пользователь или сотрудник не найден",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1667,55 +1803,103 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Позволяет изменить пароль пользователя и/или ФИО сотрудника.\n\nМожно передавать только те поля, которые требуется изменить.",
+                "description": "Создание новой записи в справочнике отделов\nДобавляет новую должность в справочник\nСоздаёт новую группу доступа\nДобавляет причину увольнения в справочник",
                 "consumes": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
                     "application/json"
                 ],
                 "produces": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "profile"
+                    "dictionary",
+                    "dictionary",
+                    "dictionary",
+                    "dictionary"
                 ],
-                "summary": "Обновить профиль текущего пользователя",
+                "summary": "Создать причину увольнения",
                 "parameters": [
                     {
-                        "description": "Данные для обновления профиля",
-                        "name": "profile",
+                        "description": "Данные справочника",
+                        "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ProfileUpdateRequest"
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    },
+                    {
+                        "description": "Данные должности",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    },
+                    {
+                        "description": "Данные группы доступа",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    },
+                    {
+                        "description": "Данные причины увольнения",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Профиль успешно обновлён",
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "string"
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
                             }
                         }
                     },
                     "400": {
-                        "description": "Неверные данные",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Неавторизован",
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1723,8 +1907,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Пользователь или сотрудник не найден",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1732,116 +1916,141 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Ошибка при обновлении профиля",
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/upload": {
-            "post": {
+        "/api/dict/positions/{id}": {
+            "put": {
                 "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Загружает CSV или Excel файл и возвращает данные для предпросмотра, без записи в БД",
+                "description": "Обновляет существующую запись справочника\nОбновляет данные должности\nОбновляет данные группы доступа. Код группы, в которой есть пользователи, изменить нельзя (409).\nОбновляет причину увольнения",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json",
+                    "application/json",
+                    "application/json",
+                    "application/json"
                 ],
                 "produces": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "upload"
+                    "dictionary",
+                    "dictionary",
+                    "dictionary",
+                    "dictionary"
                 ],
-                "summary": "Предпросмотр загруженных данных",
+                "summary": "Обновить причину увольнения",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Файл CSV/Excel",
-                        "name": "file",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "ID записи",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Данные для предпросмотра",
+                    },
+                    {
+                        "description": "Данные справочника",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.DictionaryRequest"
                         }
                     },
-                    "400": {
-                        "description": "Ошибка при обработке файла",
+                    {
+                        "type": "integer",
+                        "description": "ID должности",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные должности",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.DictionaryRequest"
                         }
                     },
-                    "401": {
-                        "description": "Пользователь не авторизован",
+                    {
+                        "type": "integer",
+                        "description": "ID группы доступа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные группы доступа",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.DictionaryRequest"
                         }
-                    }
-                }
-            }
-        },
-        "/api/upload/confirm": {
-            "post": {
-                "security": [
+                    },
                     {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Получает массив объектов с данными сотрудников и сохраняет их в БД",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "upload"
-                ],
-                "summary": "Подтверждение загрузки данных сотрудников",
-                "parameters": [
+                        "type": "integer",
+                        "description": "ID причины увольнения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
-                        "description": "Данные сотрудников для записи",
+                        "description": "Новые данные причины увольнения",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object"
-                            }
+                            "$ref": "#/definitions/models.DictionaryRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Сообщение о добавленных/обновленных записях",
+                        "description": "OK",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
                         }
                     },
                     "400": {
-                        "description": "Ошибка при обработке данных",
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1849,39 +2058,264 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Пользователь не авторизован",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/auth/login": {
-            "post": {
-                "description": "Аутентификация пользователя и выдача JWT-токена",
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Помечает запись как удалённую (soft delete)\nПомечает должность как удалённую\nПомечает группу доступа как удалённую\nПомечает причину увольнения как удалённую; в истории сотрудников она сохраняется",
+                "produces": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
+                    "application/json"
+                ],
+                "tags": [
+                    "dictionary",
+                    "dictionary",
+                    "dictionary",
+                    "dictionary"
+                ],
+                "summary": "Удалить причину увольнения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID записи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID должности",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID группы доступа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID причины увольнения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/dict/termination-reasons": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список отделов (без удалённых)\nВозвращает список должностей (без удалённых)\nВозвращает групп доступа (без удалённых)\nВозвращает список причин увольнения (без удалённых)",
+                "produces": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
+                    "application/json"
+                ],
+                "tags": [
+                    "dictionary",
+                    "dictionary",
+                    "dictionary",
+                    "dictionary"
+                ],
+                "summary": "Получить список причин увольнения",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TerminationReason"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создание новой записи в справочнике отделов\nДобавляет новую должность в справочник\nСоздаёт новую группу доступа\nДобавляет причину увольнения в справочник",
                 "consumes": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
                     "application/json"
                 ],
                 "produces": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "dictionary",
+                    "dictionary",
+                    "dictionary",
+                    "dictionary"
                 ],
-                "summary": "Вход пользователя",
+                "summary": "Создать причину увольнения",
                 "parameters": [
                     {
-                        "description": "Login payload",
-                        "name": "payload",
+                        "description": "Данные справочника",
+                        "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.LoginRequest"
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    },
+                    {
+                        "description": "Данные должности",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    },
+                    {
+                        "description": "Данные группы доступа",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    },
+                    {
+                        "description": "Данные причины увольнения",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
                         }
                     }
                 ],
@@ -1891,7 +2325,19 @@ const docTemplate = `{
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "string"
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
                             }
                         }
                     },
@@ -1904,152 +2350,6355 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.DashboardSummaryResponse": {
-            "type": "object",
-            "properties": {
-                "avgLoad": {
-                    "type": "number"
+        },
+        "/api/dict/termination-reasons/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновляет существующую запись справочника\nОбновляет данные должности\nОбновляет данные группы доступа. Код группы, в которой есть пользователи, изменить нельзя (409).\nОбновляет причину увольнения",
+                "consumes": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
+                    "application/json"
+                ],
+                "tags": [
+                    "dictionary",
+                    "dictionary",
+                    "dictionary",
+                    "dictionary"
+                ],
+                "summary": "Обновить причину увольнения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID записи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные справочника",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID должности",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные должности",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID группы доступа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные группы доступа",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID причины увольнения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные причины увольнения",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DictionaryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Помечает запись как удалённую (soft delete)\nПомечает должность как удалённую\nПомечает группу доступа как удалённую\nПомечает причину увольнения как удалённую; в истории сотрудников она сохраняется",
+                "produces": [
+                    "application/json",
+                    "application/json",
+                    "application/json",
+                    "application/json"
+                ],
+                "tags": [
+                    "dictionary",
+                    "dictionary",
+                    "dictionary",
+                    "dictionary"
+                ],
+                "summary": "Удалить причину увольнения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID записи",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID должности",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID группы доступа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID причины увольнения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Пользователи с правом employees.read_all видят сотрудников закреплённых за ними отделов\n(с правом scope.all_departments — всех), остальные — только себя. Линейный руководитель видит и своих подчинённых (прямых и косвенных).\nСортировка: sort=поле или sort=-поле (по убыванию), несколько полей через запятую;\nполя — как в ответе (last_name, department, hire_date, ...), кроме зашифрованных salary и birth_date.\nОклад и дата рождения возвращаются только пользователям с правом employees.salary.read, у остальных — null.\nФильтр по дополнительным полям: attr.\u003cкод\u003e=значение (для enum — коды через запятую),\nдля числовых полей и дат — также attr.\u003cкод\u003e.from и attr.\u003cкод\u003e.to (включительно).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Список сотрудников",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы (с 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (по умолчанию 50, максимум 500)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сортировка, например -hire_date,last_name",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поиск по фамилии, имени и отчеству",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID отделов через запятую",
+                        "name": "department_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID должностей через запятую",
+                        "name": "position_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID руководителей через запятую — их прямые подчинённые",
+                        "name": "manager_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Удалённая работа",
+                        "name": "is_remote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата приёма с (YYYY-MM-DD)",
+                        "name": "hire_date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата приёма по (YYYY-MM-DD, включительно)",
+                        "name": "hire_date_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "active — работающие, fired — уволенные, all — все (по умолчанию)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт сотрудника и связанные кадровые данные (Department, Position, HR-поля)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Создать сотрудника",
+                "parameters": [
+                    {
+                        "description": "Данные сотрудника и кадровые данные",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/org-chart": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Дерево подчинения работающих сотрудников в области видимости пользователя. Корни — сотрудники\nбез руководителя или с руководителем вне области видимости. С root_id возвращается поддерево сотрудника.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Оргструктура",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника — корня поддерева",
+                        "name": "root_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OrgChartNode"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/work": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Пользователи с правом work.read_all видят данные сотрудников закреплённых за ними отделов, остальные — только свои; линейный руководитель видит и данные подчинённых",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Получить таблицу сотрудников",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EmployeeWorkSummary"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/work/{employee_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет рабочие дни, процессы и метрики сотрудника по employee_id (soft delete); восстановить их можно через /api/trash",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Удалить сотрудника",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "employee_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Пользователи с правом employees.read_all могут получать сотрудников закреплённых за ними отделов\n(с правом scope.all_departments — любого), остальные — только себя и своих подчинённых",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Получить сотрудника по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeFullResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия карточки для If-Match"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет персональные и кадровые данные сотрудника целиком. Изменение отдела, должности,\nоклада или формата работы добавляет запись в историю с сегодняшнего дня.\nС заголовком If-Match (ETag из GET) изменение применяется, только если карточку никто не изменил.\nБез права employees.salary.read оклад и дату рождения можно не передавать: сохраняются текущие значения; их изменение — 403.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Обновить сотрудника",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag карточки",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Новые данные сотрудника",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия карточки"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Помечает сотрудника, его кадровые и рабочие данные как удалённые (soft delete); их можно восстановить\nчерез /api/trash. Удалённые сотрудники пропадают и из исторической аналитики, поэтому для увольнения\nиспользуйте /api/employees/{id}/terminate.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Удалить сотрудника",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Применяет JSON Merge Patch (RFC 7396) к карточке сотрудника: переданные поля заменяются,\nnull очищает необязательное поле (middle_name, attributes), для остальных полей null — ошибка 422;\nотсутствующие поля не меняются; false и 0 — обычные значения.\nБез If-Match изменение сверяется с версией, прочитанной при обработке запроса.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Частично обновить сотрудника",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag карточки",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия карточки"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Кадровые записи сотрудника от новых к старым: приём, переводы, повышения, изменения оклада.\nЗапись действует с valid_from по valid_to (не включительно); у текущей valid_to = null.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "История работы сотрудника",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EmploymentRecordResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/{id}/manager": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Записывает руководителя в историю сотрудника с указанной даты (по умолчанию сегодня).\nРуководитель должен работать и не может подчиняться самому сотруднику; manager_id = null снимает руководителя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Назначить линейного руководителя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag карточки сотрудника",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Руководитель и дата назначения",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeManagerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/{id}/promotion": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Закрывает текущую кадровую запись и открывает новую с другой должностью\n(и окладом, если он указан). Дата по умолчанию — сегодня.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Назначение на другую должность",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag карточки сотрудника",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Новая должность и дата назначения",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeePromotionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/{id}/rehire": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Открывает новую кадровую запись уволенного сотрудника с даты повторного приёма (позже даты увольнения).\nУчётная запись сотрудника не включается автоматически.\nБез права employees.salary.read оклад не передаётся и остаётся прежним.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Повторный приём сотрудника",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag карточки сотрудника",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Условия и дата повторного приёма",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeRehireRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/{id}/reports": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Работающие сотрудники, у которых указанный сотрудник — линейный руководитель.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Прямые подчинённые",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID руководителя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OrgEmployee"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/{id}/salary": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Закрывает текущую кадровую запись и открывает новую с другим окладом. Дата по умолчанию — сегодня.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Изменение оклада",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag карточки сотрудника",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Новый оклад и дата изменения",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeSalaryChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/{id}/subtree": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Прямые и косвенные подчинённые сотрудника; depth — уровень подчинения (1 — прямой подчинённый).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Все подчинённые",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID руководителя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OrgEmployee"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/{id}/terminate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Закрывает текущую кадровую запись датой увольнения и открывает запись с fire_date и причиной\nиз справочника termination-reasons. Сотрудник перестаёт учитываться в текущей численности,\nно остаётся в исторических данных. Связанная учётная запись отключается, её сессии отзываются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Увольнение сотрудника",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag карточки сотрудника",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Дата и причина увольнения",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeTerminationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/employees/{id}/transfer": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Закрывает текущую кадровую запись датой перевода и открывает новую. Можно одновременно\nсменить должность. Дата по умолчанию — сегодня; будущие даты не принимаются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Перевод сотрудника в другой отдел",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сотрудника",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag карточки сотрудника",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Новый отдел и дата перевода",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeTransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/impersonation/stop": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Закрывает сессию имперсонации; её токен сразу перестаёт действовать.\nВызывается с токеном имперсонации.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Завершить просмотр от имени пользователя",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все права, которые можно назначить группам доступа",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions"
+                ],
+                "summary": "Список прав доступа",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PermissionResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает данные авторизованного пользователя и связанного с ним сотрудника.\n\nДанные пользователя:\n- login\n\nДанные сотрудника:\n- фамилия\n- имя\n- отчество",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Получить профиль текущего пользователя",
+                "responses": {
+                    "200": {
+                        "description": "Профиль пользователя",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Неавторизован",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь или сотрудник не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Позволяет изменить пароль пользователя и/или ФИО сотрудника.\nДля смены пароля нужно указать текущий пароль (current_password); новый пароль проверяется по политике паролей.\n\nМожно передавать только те поля, которые требуется изменить.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Обновить профиль текущего пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные для обновления профиля",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProfileUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Профиль успешно обновлён",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные данные",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Неавторизован",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь или сотрудник не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неверных попыток ввода пароля",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при обновлении профиля",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/2fa": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Состояние двухфакторной аутентификации",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorStatusResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/2fa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Включает 2FA после проверки кода из приложения и возвращает коды восстановления.\nКоды показываются один раз.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Подтвердить подключение 2FA",
+                "parameters": [
+                    {
+                        "description": "Код из приложения",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Требует текущий пароль и код из приложения (или код восстановления).\nНедоступно, если 2FA обязательна для группы пользователя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Отключить 2FA",
+                "parameters": [
+                    {
+                        "description": "Пароль и код",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorDisableRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/2fa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет коды восстановления новыми; прежние перестают действовать.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Новые коды восстановления",
+                "parameters": [
+                    {
+                        "description": "Код из приложения",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/2fa/setup": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт новый секрет TOTP. В приложение-аутентификатор его можно добавить\nвручную или отсканировав QR-код, построенный из otpauth_uri.\n2FA включается только после подтверждения кодом (/api/profile/2fa/confirm).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Начать подключение 2FA",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorSetupResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/logout-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает все токены текущего пользователя, включая токен этого запроса",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Выйти на всех устройствах",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Устройства, на которых выполнен вход: браузер и ОС, IP, время входа и последнего обращения.\nТекущая сессия отмечена current = true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Активные сессии",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SessionResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/sessions/revoke-others": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Завершить все сессии, кроме текущей",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает сессию на другом устройстве (или текущую — это равносильно выходу).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Завершить сессию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Токены текущего пользователя, включая отозванные и истёкшие. Значения токенов не возвращаются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Список API-токенов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.APITokenResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт долгоживущий токен для скриптов: передаётся в заголовке Authorization: Bearer dft_...\nТокен действует от имени пользователя, но только в пределах указанных прав (scopes).\nЗначение токена показывается один раз.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Создать API-токен",
+                "parameters": [
+                    {
+                        "description": "Параметры токена",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.APITokenCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.APITokenCreatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/tokens/scopes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Права, которые сейчас есть у текущего пользователя; токену можно выдать только их.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Права, доступные для API-токена",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PermissionResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/tokens/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Отозвать API-токен",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID токена",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "По умолчанию возвращает заявки в статусе pending. Параметр status=all возвращает все заявки.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrations"
+                ],
+                "summary": "Список заявок на регистрацию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending | approved | rejected | all",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RegistrationResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrations/invites": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выдаёт код, по которому сотрудник может зарегистрироваться. Срок действия по умолчанию — 7 дней.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrations"
+                ],
+                "summary": "Создать приглашение на регистрацию",
+                "parameters": [
+                    {
+                        "description": "Сотрудник и срок действия",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RegistrationInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrations/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт пользователя, привязанного к сотруднику, и назначает ему группу доступа employee.\nЕсли заявка создана без кода приглашения, employee_id обязателен.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrations"
+                ],
+                "summary": "Подтвердить заявку на регистрацию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заявки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Сотрудник для привязки",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegistrationApproveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/registrations/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Приглашение, по которому подана заявка, снова становится действительным до истечения срока.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registrations"
+                ],
+                "summary": "Отклонить заявку на регистрацию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заявки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удалённые записи: сотрудники (вместе с кадровыми и рабочими данными), рабочие данные сотрудников\nи записи справочников. deleted_by — кто удалил запись по журналу аудита, purge_at — когда запись\nбудет удалена окончательно.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Корзина",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Тип записей: employees, work_days, departments, positions, access_groups, termination_reasons, custom_attributes",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TrashListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/trash/{type}/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Безвозвратно удаляет запись из корзины вместе с зависимыми данными. Справочник, на который\nссылаются кадровые записи, и сотрудника с активной учётной записью удалить нельзя (409).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Удалить запись окончательно",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Тип записи",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID записи (для work_days — ID сотрудника)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/trash/{type}/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Восстанавливает запись вместе с зависимыми: сотрудника — с кадровыми записями и рабочими данными,\nудалёнными вместе с ним; рабочие данные — с процессами и метриками. Дополнительное поле\nне восстанавливается, если его код занят новым полем (409).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Восстановить удалённую запись",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Тип записи",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID записи (для work_days — ID сотрудника)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "allOf": [
+                                    {
+                                        "type": "string"
+                                    },
+                                    {
+                                        "type": "object",
+                                        "properties": {
+                                            "message": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                ]
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/upload": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Загружает CSV или Excel файл и возвращает данные для предпросмотра, без записи в БД.\nСтолбцы после восьмого, заголовок которых совпадает с кодом дополнительного поля,\nпопадают в attributes строки.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Предпросмотр загруженных данных",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Файл CSV/Excel",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Данные для предпросмотра",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка при обработке файла",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/upload/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получает массив объектов с данными сотрудников и сохраняет их в БД. Значения attributes\nзаписываются в дополнительные поля сотрудника; пустые значения пропускаются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Подтверждение загрузки данных сотрудников",
+                "parameters": [
+                    {
+                        "description": "Данные сотрудников для записи",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сообщение о добавленных/обновленных записях",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка при обработке данных",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Есть сотрудники вне доступных отделов",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все учётные записи, включая отключённые, с группами доступа",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Список пользователей",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создаёт пользователя, привязанного к сотруднику. Если группы не переданы, назначается группа employee.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Создать учётную запись для сотрудника",
+                "parameters": [
+                    {
+                        "description": "Данные пользователя",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/locked": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Логины, временно заблокированные после серии неудачных попыток входа.\nВ список попадают и логины, для которых нет учётной записи (user_id = null).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Заблокированные учётные записи",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LockedAccountResponse"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Помечает учётную запись как отключённую (заполняет deleted_at). Вход для неё становится невозможен.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Отключить пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/2fa": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отключает 2FA (например, при утере устройства и кодов восстановления) и завершает все сессии.\nЕсли 2FA обязательна, пользователь подключит её заново при следующем входе.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Сбросить 2FA пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/departments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Менеджер видит и изменяет данные только сотрудников этих отделов",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Отделы, закреплённые за пользователем",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Department"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Полностью заменяет список отделов пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Закрепить отделы за пользователем",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID отделов",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserDepartmentsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Снимает отметку об отключении учётной записи",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Включить пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/groups": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Добавить пользователя в группу доступа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Код группы доступа",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserGroupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/groups/{code}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Исключить пользователя из группы доступа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Код группы доступа",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/impersonate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выдаёт временный access-токен, с которым все запросы выполняются от имени пользователя:\nс его группами, отделами и областью видимости данных. Администратор сохраняется в токене,\nсобытия аудита записываются с actor_id. По умолчанию доступен только просмотр (allow_write = false).\nНельзя действовать от имени пользователя, который сам может использовать имперсонацию.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Просмотр системы от имени пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина и режим",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ImpersonationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImpersonationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Устанавливает пользователю новый пароль; при следующем входе пользователь должен будет его сменить",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Задать временный пароль пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый пароль",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/password-reset": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отправляет пользователю письмо с одноразовой ссылкой для установки нового пароля.\nТекущий пароль перестаёт действовать и сессии пользователя завершаются: войти можно\nтолько после установки нового пароля по ссылке.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Сбросить пароль пользователя по email",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PasswordResetResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Активные сессии пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SessionResponse"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает все сессии и токены пользователя; API-токены не затрагиваются.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Завершить все сессии пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/sessions/{sid}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Завершить сессию пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID сессии",
+                        "name": "sid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сбрасывает счётчик неудачных попыток входа и снимает временную блокировку",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Разблокировать вход пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Аутентификация пользователя и выдача JWT-токена.\nПароль проверяется провайдерами из AUTH_PROVIDERS по очереди (например, LDAP, затем локальные учётные записи).\nПосле неудачной попытки следующая возможна только через растущую паузу,\nпосле нескольких подряд логин блокируется на время (ответ 429 с заголовком Retry-After).\nЕсли у пользователя подключена 2FA (или она обязательна для его группы), вместо токенов\nвозвращается challenge_token для /auth/login/2fa (step = \"2fa\") или /auth/login/2fa/setup (step = \"2fa_setup\").\nЕсли пароль был сброшен администратором, сначала нужно сменить его через /auth/login/password (step = \"password_change\").",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход пользователя",
+                "parameters": [
+                    {
+                        "description": "Login payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Токены или models.LoginChallengeResponse",
+                        "schema": {
+                            "$ref": "#/definitions/models.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/2fa": {
+            "post": {
+                "description": "Принимает challenge_token с первого шага и код из приложения-аутентификатора\nлибо один из кодов восстановления.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Второй шаг входа",
+                "parameters": [
+                    {
+                        "description": "Challenge-токен и код",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/2fa/setup": {
+            "post": {
+                "description": "Для пользователей, которым 2FA обязательна, но ещё не подключена.\nВозвращает секрет и otpauth URI для приложения-аутентификатора.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Подключение 2FA при входе",
+                "parameters": [
+                    {
+                        "description": "Challenge-токен",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginTwoFactorSetupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorSetupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/2fa/setup/confirm": {
+            "post": {
+                "description": "Подтверждает секрет первым кодом из приложения, включает 2FA и выдаёт токены.\nКоды восстановления возвращаются один раз.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Завершение подключения 2FA при входе",
+                "parameters": [
+                    {
+                        "description": "Challenge-токен и код",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginTwoFactorSetupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/password": {
+            "post": {
+                "description": "Шаг входа после сброса пароля администратором (step = \"password_change\").\nПосле смены пароля вход продолжается: выдаются токены или challenge для 2FA.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Обязательная смена пароля при входе",
+                "parameters": [
+                    {
+                        "description": "Challenge-токен и новый пароль",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginPasswordChangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Токены или models.LoginChallengeResponse",
+                        "schema": {
+                            "$ref": "#/definitions/models.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Отзывает refresh-токен текущей сессии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Выход из системы",
+                "parameters": [
+                    {
+                        "description": "Refresh-токен",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Обменивает код авторизации на ID-токен, находит (или создаёт) пользователя\nи перенаправляет на страницу фронтенда, передавая токены во фрагменте URL\n(#token=...\u0026refresh_token=...\u0026expires_in=...) или код ошибки (#error=...).\nЕсли пользователю нужна 2FA и провайдер не подтвердил многофакторный вход\n(OIDC_TRUSTED_MFA), вместо токенов передаётся шаг входа\n(#step=...\u0026challenge_token=...\u0026expires_in=...), как в ответе /auth/login.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Возврат от OIDC-провайдера",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код авторизации",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Значение state",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    }
+                }
+            }
+        },
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Перенаправляет на страницу входа провайдера (authorization code flow с PKCE).\nПосле входа провайдер возвращает пользователя на /auth/oidc/callback.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход через внешний OIDC-провайдер",
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password-reset": {
+            "post": {
+                "description": "Принимает одноразовый токен сброса и новый пароль, который должен соответствовать политике паролей.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Установить пароль по ссылке из письма",
+                "parameters": [
+                    {
+                        "description": "Токен и новый пароль",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PasswordResetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Обменивает refresh-токен на новую пару токенов. Предъявленный refresh-токен становится недействительным.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Обновление токенов",
+                "parameters": [
+                    {
+                        "description": "Refresh-токен",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Создаёт заявку на регистрацию. Учётная запись становится активной после подтверждения администратором.\nЕсли передан код приглашения, заявка сразу привязывается к сотруднику из приглашения.\nПриглашение занимается заявкой; если заявку отклонят, его можно использовать снова.\n\nКоды ошибок: invalid_input, email_exists, invalid_invite_code, employee_already_linked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Регистрация пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные регистрации",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.DashboardSummaryResponse": {
+            "type": "object",
+            "properties": {
+                "avgLoad": {
+                    "type": "number"
+                },
+                "avgProductivity": {
+                    "type": "number"
+                },
+                "deptEfficiency": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.DeptEfficiencyItem"
+                    }
+                },
+                "headcount": {
+                    "description": "Headcount — текущая численность: работающие (не уволенные) сотрудники.",
+                    "type": "integer"
+                },
+                "monthlyStats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.MonthlyStat"
+                    }
+                },
+                "overtime": {
+                    "type": "number"
+                },
+                "satisfaction": {
+                    "type": "number"
+                },
+                "topEfficiency": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.TopEfficiencyItem"
+                    }
+                },
+                "topOvertime": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.TopOvertimeItem"
+                    }
+                }
+            }
+        },
+        "controllers.DeptEfficiencyItem": {
+            "type": "object",
+            "properties": {
+                "avg_productivity": {
+                    "type": "number"
+                },
+                "department": {
+                    "type": "string"
+                },
+                "job_level": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.MonthlyStat": {
+            "type": "object",
+            "properties": {
+                "load": {
+                    "type": "number"
+                },
+                "month": {
+                    "type": "string"
+                },
+                "overtime": {
+                    "type": "number"
+                }
+            }
+        },
+        "controllers.TopEfficiencyItem": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "overtime": {
+                    "type": "number"
+                },
+                "productivity": {
+                    "type": "number"
+                },
+                "tasks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.TopOvertimeItem": {
+            "type": "object",
+            "properties": {
+                "job_satisfaction": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "overtime_hours": {
+                    "type": "number"
+                },
+                "tasks_completed_per_day": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.TurnoverMonth": {
+            "type": "object",
+            "properties": {
+                "headcount": {
+                    "type": "integer"
+                },
+                "hires": {
+                    "type": "integer"
+                },
+                "month": {
+                    "type": "string"
+                },
+                "terminations": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.TurnoverReasonItem": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.TurnoverResponse": {
+            "type": "object",
+            "properties": {
+                "average_headcount": {
+                    "type": "number"
+                },
+                "by_reason": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.TurnoverReasonItem"
+                    }
+                },
+                "date_from": {
+                    "type": "string"
+                },
+                "date_to": {
+                    "type": "string"
+                },
+                "headcount_end": {
+                    "type": "integer"
+                },
+                "headcount_start": {
+                    "type": "integer"
+                },
+                "hires": {
+                    "type": "integer"
+                },
+                "monthly": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.TurnoverMonth"
+                    }
+                },
+                "rehires": {
+                    "type": "integer"
+                },
+                "terminations": {
+                    "type": "integer"
+                },
+                "turnover_rate": {
+                    "description": "TurnoverRate — увольнения в процентах от средней численности за период.",
+                    "type": "number"
+                }
+            }
+        },
+        "controllers.ValidationErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "validation_failed"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.APITokenCreateRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "description": "ExpiresInDays — срок действия; 0 — максимальный допустимый срок.",
+                    "type": "integer",
+                    "example": 90
+                },
+                "name": {
+                    "type": "string",
+                    "example": "nightly export"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "work.read_all",
+                        "dashboard.read_all"
+                    ]
+                }
+            }
+        },
+        "models.APITokenCreatedResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "last_used_ip": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.APITokenResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "last_used_ip": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.AccessGroup": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AccessGroupPermissionsRequest": {
+            "type": "object",
+            "properties": {
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "employees.read_all",
+                        "work.read_all"
+                    ]
+                }
+            }
+        },
+        "models.AuditEventResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "diff": {
+                    "type": "object"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "entity_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AuditListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEventResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CustomAttributeOptionRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "name"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "senior"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Senior"
+                }
+            }
+        },
+        "models.CustomAttributeOptionResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CustomAttributeRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "name",
+                "type"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "grade"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "description": "Min и Max — границы числа или длины строки.",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Грейд"
+                },
+                "pattern": {
+                    "description": "Pattern — регулярное выражение для строк.",
+                    "type": "string",
+                    "example": "^[A-Z]{2}-\\d{4}$"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "string",
+                        "number",
+                        "date",
+                        "enum"
+                    ],
+                    "example": "enum"
+                }
+            }
+        },
+        "models.CustomAttributeResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "options": {
+                    "description": "Options — значения справочника для типа enum.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CustomAttributeOptionResponse"
+                    }
+                },
+                "pattern": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Department": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DictionaryRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "max_salary": {
+                    "type": "number"
+                },
+                "min_salary": {
+                    "description": "MinSalary и MaxSalary задают вилку оклада; используются только для должностей.",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EmployeeCreateRequest": {
+            "type": "object",
+            "required": [
+                "department_id",
+                "first_name",
+                "hire_date",
+                "last_name",
+                "position_id"
+            ],
+            "properties": {
+                "attributes": {
+                    "description": "Attributes — значения дополнительных полей по коду: число, строка или дата YYYY-MM-DD,\nдля enum — код значения. Заменяют все текущие значения; если поле не передано,\nзначения не меняются. null или пустая строка очищает значение.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "birth_date": {
+                    "description": "BirthDate обязательна при создании. Без права employees.salary.read при изменении\nBirthDate и Salary можно не передавать: сохраняются текущие значения.",
+                    "type": "string"
+                },
+                "department_id": {
+                    "type": "integer"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "hire_date": {
+                    "type": "string"
+                },
+                "is_remote": {
+                    "type": "boolean"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "middle_name": {
+                    "type": "string"
+                },
+                "position_id": {
+                    "type": "integer"
+                },
+                "salary": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.EmployeeFullResponse": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "description": "Attributes — дополнительные поля по коду: number — числом, остальные типы — строкой.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "birth_date": {
+                    "description": "BirthDate и Salary равны null, если у пользователя нет права employees.salary.read.",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "department": {
+                    "type": "string"
+                },
+                "fire_date": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "hire_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_remote": {
+                    "type": "boolean"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "manager_id": {
+                    "type": "integer"
+                },
+                "middle_name": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "string"
+                },
+                "salary": {
+                    "type": "number"
+                },
+                "version": {
+                    "description": "Version — версия карточки; передаётся в If-Match при изменении.",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "models.EmployeeListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EmployeeFullResponse"
+                    }
+                },
+                "meta": {
+                    "$ref": "#/definitions/models.PageMeta"
+                }
+            }
+        },
+        "models.EmployeeManagerRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "effective_date": {
+                    "type": "string",
+                    "example": "2024-03-01"
+                },
+                "manager_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.EmployeePromotionRequest": {
+            "type": "object",
+            "required": [
+                "position_id"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "effective_date": {
+                    "type": "string",
+                    "example": "2024-03-01"
+                },
+                "position_id": {
+                    "type": "integer"
+                },
+                "salary": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.EmployeeRehireRequest": {
+            "type": "object",
+            "required": [
+                "department_id",
+                "position_id"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "department_id": {
+                    "type": "integer"
+                },
+                "hire_date": {
+                    "description": "HireDate — дата повторного приёма (YYYY-MM-DD), по умолчанию сегодня.",
+                    "type": "string",
+                    "example": "2024-09-01"
+                },
+                "is_remote": {
+                    "type": "boolean"
+                },
+                "position_id": {
+                    "type": "integer"
+                },
+                "salary": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.EmployeeSalaryChangeRequest": {
+            "type": "object",
+            "required": [
+                "salary"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "effective_date": {
+                    "type": "string",
+                    "example": "2024-03-01"
+                },
+                "salary": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.EmployeeTerminationRequest": {
+            "type": "object",
+            "required": [
+                "termination_reason_id"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "fire_date": {
+                    "description": "FireDate — дата увольнения (YYYY-MM-DD), по умолчанию сегодня.",
+                    "type": "string",
+                    "example": "2024-03-01"
+                },
+                "termination_reason_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.EmployeeTransferRequest": {
+            "type": "object",
+            "required": [
+                "department_id"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "department_id": {
+                    "type": "integer"
+                },
+                "effective_date": {
+                    "description": "EffectiveDate — дата вступления в силу (YYYY-MM-DD), по умолчанию сегодня.",
+                    "type": "string",
+                    "example": "2024-03-01"
+                },
+                "position_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.EmployeeWorkSummary": {
+            "type": "object",
+            "properties": {
+                "calls_count": {
+                    "type": "integer"
+                },
+                "completed_tasks": {
+                    "type": "integer"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "end_work_day": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "productivity": {
+                    "type": "integer"
+                },
+                "satisfaction": {
+                    "type": "integer"
+                },
+                "start_work_day": {
+                    "type": "string"
+                },
+                "work_life_balance": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.EmploymentRecordResponse": {
+            "type": "object",
+            "properties": {
+                "change_type": {
+                    "type": "string",
+                    "example": "transfer"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "department": {
+                    "type": "string"
+                },
+                "department_id": {
+                    "type": "integer"
+                },
+                "fire_date": {
+                    "type": "string"
+                },
+                "hire_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_remote": {
+                    "type": "boolean"
+                },
+                "manager_id": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "string"
+                },
+                "position_id": {
+                    "type": "integer"
+                },
+                "salary": {
+                    "description": "Salary равен null без права employees.salary.read.",
+                    "type": "number"
+                },
+                "termination_reason": {
+                    "description": "TerminationReason — название причины увольнения для записи об увольнении.",
+                    "type": "string"
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "valid_to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImpersonationRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "allow_write": {
+                    "description": "AllowWrite разрешает изменяющие запросы; по умолчанию доступен только просмотр.",
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Проверка обращения: не отображаются данные за март"
+                }
+            }
+        },
+        "models.ImpersonationResponse": {
+            "type": "object",
+            "properties": {
+                "allow_write": {
+                    "type": "boolean"
+                },
+                "expires_in": {
+                    "type": "integer"
                 },
-                "avgProductivity": {
-                    "type": "number"
+                "login": {
+                    "type": "string"
                 },
-                "deptEfficiency": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/controllers.DeptEfficiencyItem"
-                    }
+                "session_id": {
+                    "type": "integer"
                 },
-                "monthlyStats": {
+                "token": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string",
+                    "example": "RS256"
+                },
+                "crv": {
+                    "description": "Ed25519 (kty = OKP) и EC",
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string",
+                    "example": "RSA"
+                },
+                "n": {
+                    "description": "RSA",
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string",
+                    "example": "sig"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/controllers.MonthlyStat"
+                        "$ref": "#/definitions/models.JWK"
                     }
+                }
+            }
+        },
+        "models.LockedAccountResponse": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "type": "integer"
                 },
-                "overtime": {
-                    "type": "number"
+                "locked_until": {
+                    "type": "string"
                 },
-                "satisfaction": {
-                    "type": "number"
+                "login": {
+                    "type": "string"
                 },
-                "topEfficiency": {
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LoginPasswordChangeRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "password"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginTwoFactorSetupRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginTwoFactorSetupResponse": {
+            "type": "object",
+            "properties": {
+                "expires_in": {
+                    "type": "integer"
+                },
+                "recovery_codes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/controllers.TopEfficiencyItem"
+                        "type": "string"
                     }
                 },
-                "topOvertime": {
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrgChartNode": {
+            "type": "object",
+            "properties": {
+                "department": {
+                    "type": "string"
+                },
+                "depth": {
+                    "description": "Depth — уровень подчинения относительно запрошенного руководителя (1 — прямой подчинённый).",
+                    "type": "integer"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "manager_id": {
+                    "type": "integer"
+                },
+                "middle_name": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "string"
+                },
+                "reports": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/controllers.TopOvertimeItem"
+                        "$ref": "#/definitions/models.OrgChartNode"
                     }
                 }
             }
         },
-        "controllers.DeptEfficiencyItem": {
+        "models.OrgEmployee": {
             "type": "object",
             "properties": {
-                "avg_productivity": {
-                    "type": "number"
-                },
                 "department": {
                     "type": "string"
                 },
-                "job_level": {
+                "depth": {
+                    "description": "Depth — уровень подчинения относительно запрошенного руководителя (1 — прямой подчинённый).",
+                    "type": "integer"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "manager_id": {
+                    "type": "integer"
+                },
+                "middle_name": {
+                    "type": "string"
+                },
+                "position": {
                     "type": "string"
                 }
             }
         },
-        "controllers.LoginRequest": {
+        "models.PageMeta": {
             "type": "object",
             "properties": {
-                "login": {
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "pages": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PasswordResetRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "N3w-Secret!"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PasswordResetResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PermissionResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Position": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "maxSalary": {
+                    "type": "number",
+                    "format": "float64"
+                },
+                "minSalary": {
+                    "description": "MinSalary и MaxSalary — вилка оклада; nil — без ограничения.",
+                    "type": "number",
+                    "format": "float64"
+                },
+                "name": {
                     "type": "string"
+                }
+            }
+        },
+        "models.ProfileUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "description": "CurrentPassword обязателен при смене пароля.",
+                    "type": "string",
+                    "example": "0ld-Secret!"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "Иван"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Иванов"
+                },
+                "middle_name": {
+                    "type": "string",
+                    "example": "Иванович"
                 },
                 "password": {
+                    "type": "string",
+                    "example": "N3w-Secret!"
+                }
+            }
+        },
+        "models.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.RefreshRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
                     "type": "string"
                 }
             }
         },
-        "controllers.MonthlyStat": {
+        "models.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "ivanov@example.com"
+                },
+                "invite_code": {
+                    "type": "string",
+                    "example": "7F3A9C21BD"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Иван Иванов"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "secret123"
+                }
+            }
+        },
+        "models.RegistrationApproveRequest": {
             "type": "object",
             "properties": {
-                "load": {
-                    "type": "number"
-                },
-                "month": {
-                    "type": "string"
-                },
-                "overtime": {
-                    "type": "number"
+                "employee_id": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.TopEfficiencyItem": {
+        "models.RegistrationInviteRequest": {
             "type": "object",
+            "required": [
+                "employee_id"
+            ],
             "properties": {
-                "name": {
-                    "type": "string"
-                },
-                "overtime": {
-                    "type": "number"
-                },
-                "productivity": {
-                    "type": "number"
-                },
-                "tasks": {
+                "employee_id": {
                     "type": "integer"
+                },
+                "ttl_days": {
+                    "type": "integer",
+                    "example": 7
                 }
             }
         },
-        "controllers.TopOvertimeItem": {
+        "models.RegistrationResponse": {
             "type": "object",
             "properties": {
-                "job_satisfaction": {
+                "created_at": {
+                    "type": "string"
+                },
+                "employee_id": {
                     "type": "integer"
                 },
-                "name": {
+                "full_name": {
                     "type": "string"
                 },
-                "overtime_hours": {
-                    "type": "number"
-                },
-                "tasks_completed_per_day": {
+                "id": {
                     "type": "integer"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
                 }
             }
         },
-        "models.AccessGroup": {
+        "models.SessionResponse": {
             "type": "object",
             "properties": {
-                "code": {
+                "created_at": {
                     "type": "string"
                 },
-                "createdAt": {
+                "current": {
+                    "description": "Current — сессия, с которой выполнен запрос.",
+                    "type": "boolean"
+                },
+                "device": {
+                    "type": "string",
+                    "example": "Chrome, Windows"
+                },
+                "expires_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "user_agent": {
                     "type": "string"
                 }
             }
         },
-        "models.Department": {
+        "models.TerminationReason": {
             "type": "object",
             "properties": {
                 "code": {
@@ -2066,168 +8715,249 @@ const docTemplate = `{
                 }
             }
         },
-        "models.DictionaryRequest": {
+        "models.TokenResponse": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "code": {
+                "expires_in": {
+                    "type": "integer"
+                },
+                "refresh_token": {
                     "type": "string"
                 },
-                "name": {
+                "token": {
                     "type": "string"
                 }
             }
         },
-        "models.EmployeeCreateRequest": {
+        "models.TrashItem": {
             "type": "object",
-            "required": [
-                "birth_date",
-                "department_id",
-                "first_name",
-                "hire_date",
-                "last_name",
-                "position_id"
-            ],
             "properties": {
-                "birth_date": {
+                "count": {
+                    "description": "Count — число удалённых рабочих дней (только для work_days).",
+                    "type": "integer"
+                },
+                "deleted_at": {
                     "type": "string"
                 },
-                "department_id": {
+                "deleted_by": {
+                    "description": "DeletedBy — пользователь, удаливший запись, по журналу аудита.",
                     "type": "integer"
                 },
-                "first_name": {
+                "deleted_by_login": {
                     "type": "string"
                 },
-                "hire_date": {
-                    "type": "string"
+                "id": {
+                    "description": "ID — ID записи; для рабочих данных — ID сотрудника.",
+                    "type": "integer"
                 },
-                "is_remote": {
-                    "type": "boolean"
+                "name": {
+                    "type": "string",
+                    "example": "Иванов Иван Иванович"
                 },
-                "last_name": {
+                "purge_at": {
+                    "description": "PurgeAt — когда запись будет удалена окончательно; null — автоочистка отключена.",
                     "type": "string"
                 },
-                "middle_name": {
-                    "type": "string"
+                "type": {
+                    "type": "string",
+                    "example": "employees"
+                }
+            }
+        },
+        "models.TrashListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TrashItem"
+                    }
                 },
-                "position_id": {
+                "retention_days": {
+                    "description": "RetentionDays — срок хранения удалённых записей; 0 — автоочистка отключена.",
                     "type": "integer"
-                },
-                "salary": {
-                    "type": "number"
                 }
             }
         },
-        "models.EmployeeFullResponse": {
+        "models.TwoFactorCodeRequest": {
             "type": "object",
+            "required": [
+                "code"
+            ],
             "properties": {
-                "birth_date": {
-                    "type": "string"
-                },
-                "created_at": {
+                "code": {
                     "type": "string"
-                },
-                "department": {
+                }
+            }
+        },
+        "models.TwoFactorDisableRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
                     "type": "string"
                 },
-                "fire_date": {
+                "password": {
                     "type": "string"
-                },
-                "first_name": {
+                }
+            }
+        },
+        "models.TwoFactorSetupResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
                     "type": "string"
                 },
-                "hire_date": {
+                "secret": {
                     "type": "string"
+                }
+            }
+        },
+        "models.TwoFactorStatusResponse": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
                 },
-                "id": {
+                "recovery_codes_left": {
                     "type": "integer"
                 },
-                "is_remote": {
+                "required": {
                     "type": "boolean"
-                },
-                "last_name": {
-                    "type": "string"
-                },
-                "middle_name": {
-                    "type": "string"
-                },
-                "position": {
-                    "type": "string"
-                },
-                "salary": {
-                    "type": "number"
                 }
             }
         },
-        "models.EmployeeWorkSummary": {
+        "models.UserCreateRequest": {
             "type": "object",
+            "required": [
+                "employee_id",
+                "login",
+                "password"
+            ],
             "properties": {
-                "calls_count": {
-                    "type": "integer"
-                },
-                "completed_tasks": {
-                    "type": "integer"
-                },
                 "employee_id": {
                     "type": "integer"
                 },
-                "end_work_day": {
-                    "type": "string"
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "employee"
+                    ]
                 },
-                "full_name": {
-                    "type": "string"
+                "login": {
+                    "type": "string",
+                    "example": "ivanov"
                 },
-                "productivity": {
-                    "type": "integer"
+                "password": {
+                    "type": "string",
+                    "example": "secret123"
+                }
+            }
+        },
+        "models.UserDepartmentsRequest": {
+            "type": "object",
+            "properties": {
+                "department_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.UserGroupRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "manager"
+                }
+            }
+        },
+        "models.UserGroupResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
                 },
-                "satisfaction": {
+                "id": {
                     "type": "integer"
                 },
-                "start_work_day": {
+                "name": {
                     "type": "string"
-                },
-                "work_life_balance": {
-                    "type": "integer"
                 }
             }
         },
-        "models.Position": {
+        "models.UserPasswordRequest": {
             "type": "object",
+            "required": [
+                "password"
+            ],
             "properties": {
-                "code": {
+                "password": {
+                    "type": "string",
+                    "example": "secret123"
+                }
+            }
+        },
+        "models.UserResponse": {
+            "type": "object",
+            "properties": {
+                "access_groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserGroupResponse"
+                    }
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "createdAt": {
+                "disabled": {
+                    "type": "boolean"
+                },
+                "disabled_at": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "locked": {
+                    "type": "boolean"
+                },
+                "locked_until": {
+                    "type": "string"
+                },
+                "login": {
                     "type": "string"
                 }
             }
         },
-        "models.ProfileUpdateRequest": {
+        "services.FieldError": {
             "type": "object",
             "properties": {
-                "first_name": {
-                    "type": "string",
-                    "example": "Иван"
-                },
-                "last_name": {
-                    "type": "string",
-                    "example": "Иванов"
+                "code": {
+                    "type": "string"
                 },
-                "middle_name": {
-                    "type": "string",
-                    "example": "Иванович"
+                "field": {
+                    "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "example": "secret123"
+                "message": {
+                    "type": "string"
                 }
             }
         }
@@ -1,6 +1,6 @@
 import axios from 'axios'
 
-const API_BASE = import.meta.env.VITE_API_BASE || 'http://localhost:8080'
+export const API_BASE = import.meta.env.VITE_API_BASE || 'http://localhost:8080'
 
 const api = axios.create({
   baseURL: API_BASE,
@@ -13,4 +13,52 @@ if (token) {
   api.defaults.headers.common.Authorization = `Bearer ${token}`
 }
 
+export function setTokens(data) {
+  localStorage.setItem('token', data.token)
+  if (data.refresh_token) {
+    localStorage.setItem('refresh_token', data.refresh_token)
+  }
+  api.defaults.headers.common.Authorization = `Bearer ${data.token}`
+}
+
+export function clearTokens() {
+  localStorage.removeItem('token')
+  localStorage.removeItem('refresh_token')
+  delete api.defaults.headers.common.Authorization
+}
+
+// Один общий запрос на обновление, даже если 401 пришёл сразу на несколько запросов
+let refreshing = null
+
+api.interceptors.response.use(
+  (res) => res,
+  async (error) => {
+    const original = error.config
+    const refreshToken = localStorage.getItem('refresh_token')
+
+    if (
+      error.response?.status !== 401 ||
+      !refreshToken ||
+      original._retry ||
+      original.url?.startsWith('/auth/')
+    ) {
+      return Promise.reject(error)
+    }
+
+    original._retry = true
+    try {
+      refreshing = refreshing || axios.post(`${API_BASE}/auth/refresh`, { refresh_token: refreshToken })
+      const res = await refreshing
+      setTokens(res.data)
+      original.headers.Authorization = `Bearer ${res.data.token}`
+      return api(original)
+    } catch (e) {
+      clearTokens()
+      return Promise.reject(error)
+    } finally {
+      refreshing = null
+    }
+  }
+)
+
 export default api
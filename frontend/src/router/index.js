@@ -1,6 +1,8 @@
 import { createRouter, createWebHistory } from 'vue-router'
 import Login from '../components/Login.vue'
 import Register from '../components/Register.vue'
+import ResetPassword from '../components/ResetPassword.vue'
+import OidcCallback from '../components/OidcCallback.vue'
 import Home from '../components/Home.vue'
 import UploadData from '../components/UploadData.vue'
 import EmployeeList from '../components/EmployeeList.vue'
@@ -14,6 +16,8 @@ const routes = [
   { path: '/', name: 'home', component: Home },
   { path: '/login', name: 'login', component: Login },
   { path: '/register', name: 'register', component: Register },
+  { path: '/login/oidc', name: 'OidcCallback', component: OidcCallback },
+  { path: '/reset-password', name: 'ResetPassword', component: ResetPassword },
   { path: '/upload-data', name: 'UploadData', component: UploadData },
   { path: '/employees', name: 'EmployeeList', component: EmployeeList},
   { path: '/profile', name: 'Profile', component: Profile },
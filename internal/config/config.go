@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +18,86 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	DBSSLMode  string
+
+	// Подпись токенов. Рекомендуется RS256 или EdDSA с ключом из файла;
+	// HS256 с JWT_SECRET оставлен для совместимости.
+	JWTAlgorithm      string
+	JWTSigningKeyFile string
+	JWTKeyID          string
+	JWTVerifyKeyFiles []string
+	JWTSecret         string
+
+	// Шифрование оклада и даты рождения: пары kid=ключ в hex (32 байта).
+	// Для ротации добавьте новый ключ и сделайте его активным — старые значения
+	// перешифруются при запуске, после чего старый ключ можно убрать.
+	FieldEncryptionKeys  map[string]string
+	FieldEncryptionKeyID string
+
+	// Защита входа от подбора пароля
+	LoginAttemptStore    string // memory | postgres
+	LoginMaxFailures     int
+	LoginIPMaxFailures   int
+	LoginLockoutDuration time.Duration
+	LoginBackoffBase     time.Duration
+	LoginBackoffMax      time.Duration
+
+	// Двухфакторная аутентификация
+	TwoFactorIssuer         string
+	TwoFactorRequiredGroups []string
+
+	// Политика паролей и сброс пароля
+	PasswordMinLength    int
+	PasswordMinClasses   int
+	PasswordHistory      int
+	PasswordDenyListFile string
+	PasswordResetTTL     time.Duration
+	AppBaseURL           string // адрес фронтенда для ссылок в письмах
+
+	// Отправка писем
+	MailSender string // log | file
+	MailDir    string
+
+	// Вход через внешний OIDC-провайдер; включается заданием OIDC_ISSUER
+	OIDCIssuer           string
+	OIDCClientID         string
+	OIDCClientSecret     string
+	OIDCRedirectURL      string
+	OIDCScopes           []string
+	OIDCGroupsClaim      string
+	OIDCGroupMap         map[string]string // группа провайдера → код группы доступа
+	OIDCAutoProvision    bool
+	OIDCSyncGroups       bool
+	OIDCFrontendRedirect string
+	OIDCTrustedMFA       []string // значения amr/acr, при которых собственная 2FA не запрашивается
+
+	// API-токены для скриптов и интеграций
+	APITokenMaxTTL time.Duration
+
+	// Срок сессии просмотра от имени пользователя
+	ImpersonationTTL time.Duration
+
+	// Корзина: срок хранения удалённых записей (0 — без автоочистки) и период очистки
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+
+	// Проверка пароля: провайдеры в порядке опроса (local, ldap)
+	AuthProviders   []string
+	AuthLocalGroups []string // если задано, локальный вход только для этих групп
+
+	LDAPURL                string
+	LDAPStartTLS           bool
+	LDAPInsecureSkipVerify bool
+	LDAPCAFile             string
+	LDAPTimeout            time.Duration
+	LDAPBindDN             string
+	LDAPBindPassword       string
+	LDAPBaseDN             string
+	LDAPUserFilter         string
+	LDAPLoginAttribute     string
+	LDAPGroupAttribute     string
+	LDAPGroupMap           map[string]string // DN группы → код группы доступа
+	LDAPAutoProvision      bool
+	LDAPSyncGroups         bool
 }
 
 func Load() (*Config, error) {
@@ -28,7 +111,153 @@ func Load() (*Config, error) {
 		DBPassword: getEnv("DB_PASSWORD", "postgres"),
 		DBName:     getEnv("DB_NAME", "employee_dashboard"),
 		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+		JWTAlgorithm:      getEnv("JWT_ALGORITHM", ""),
+		JWTSigningKeyFile: getEnv("JWT_SIGNING_KEY_FILE", ""),
+		JWTKeyID:          getEnv("JWT_KEY_ID", ""),
+		JWTVerifyKeyFiles: getEnvList("JWT_VERIFY_KEY_FILES", ""),
+		JWTSecret:         getEnv("JWT_SECRET", ""),
+
+		FieldEncryptionKeyID: getEnv("FIELD_ENCRYPTION_KEY_ID", ""),
+
+		LoginAttemptStore: getEnv("LOGIN_ATTEMPT_STORE", "memory"),
+
+		TwoFactorIssuer:         getEnv("TWO_FACTOR_ISSUER", "Employee Dashboard"),
+		TwoFactorRequiredGroups: getEnvList("TWO_FACTOR_REQUIRED_GROUPS", "admin,manager"),
+
+		PasswordDenyListFile: getEnv("PASSWORD_DENY_LIST_FILE", ""),
+		AppBaseURL:           getEnv("APP_BASE_URL", "http://localhost:5173"),
+
+		MailSender: getEnv("MAIL_SENDER", "log"),
+		MailDir:    getEnv("MAIL_DIR", "mail"),
+
+		OIDCIssuer:       getEnv("OIDC_ISSUER", ""),
+		OIDCClientID:     getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:  getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/auth/oidc/callback"),
+		OIDCScopes:       getEnvList("OIDC_SCOPES", "openid,email,profile"),
+		OIDCGroupsClaim:  getEnv("OIDC_GROUPS_CLAIM", "groups"),
+		OIDCTrustedMFA:   getEnvList("OIDC_TRUSTED_MFA", ""),
+	}
+	cfg.AuthProviders = getEnvList("AUTH_PROVIDERS", "local")
+	cfg.AuthLocalGroups = getEnvList("AUTH_LOCAL_GROUPS", "")
+	cfg.LDAPURL = getEnv("LDAP_URL", "")
+	cfg.LDAPCAFile = getEnv("LDAP_CA_FILE", "")
+	cfg.LDAPBindDN = getEnv("LDAP_BIND_DN", "")
+	cfg.LDAPBindPassword = getEnv("LDAP_BIND_PASSWORD", "")
+	cfg.LDAPBaseDN = getEnv("LDAP_BASE_DN", "")
+	cfg.LDAPUserFilter = getEnv("LDAP_USER_FILTER", "(&(objectClass=user)(sAMAccountName={login}))")
+	cfg.LDAPLoginAttribute = getEnv("LDAP_LOGIN_ATTRIBUTE", "sAMAccountName")
+	cfg.LDAPGroupAttribute = getEnv("LDAP_GROUP_ATTRIBUTE", "memberOf")
+	cfg.OIDCFrontendRedirect = getEnv("OIDC_FRONTEND_REDIRECT", cfg.AppBaseURL+"/login/oidc")
+
+	var err error
+	if cfg.LoginMaxFailures, err = getEnvInt("LOGIN_MAX_FAILURES", 5); err != nil {
+		return nil, err
+	}
+	if cfg.LoginIPMaxFailures, err = getEnvInt("LOGIN_IP_MAX_FAILURES", 50); err != nil {
+		return nil, err
+	}
+	if cfg.LoginLockoutDuration, err = getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.LoginBackoffBase, err = getEnvDuration("LOGIN_BACKOFF_BASE", time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.LoginBackoffMax, err = getEnvDuration("LOGIN_BACKOFF_MAX", time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.PasswordMinLength, err = getEnvInt("PASSWORD_MIN_LENGTH", 8); err != nil {
+		return nil, err
+	}
+	if cfg.PasswordMinClasses, err = getEnvInt("PASSWORD_MIN_CLASSES", 3); err != nil {
+		return nil, err
+	}
+	if cfg.PasswordHistory, err = getEnvInt("PASSWORD_HISTORY", 5); err != nil {
+		return nil, err
+	}
+	if cfg.PasswordResetTTL, err = getEnvDuration("PASSWORD_RESET_TTL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.MailSender != "log" && cfg.MailSender != "file" {
+		return nil, fmt.Errorf("MAIL_SENDER: unknown sender %q", cfg.MailSender)
+	}
+	if cfg.OIDCAutoProvision, err = getEnvBool("OIDC_AUTO_PROVISION", false); err != nil {
+		return nil, err
+	}
+	if cfg.OIDCSyncGroups, err = getEnvBool("OIDC_SYNC_GROUPS", false); err != nil {
+		return nil, err
+	}
+	if cfg.OIDCGroupMap, err = getEnvMap("OIDC_GROUP_MAP"); err != nil {
+		return nil, err
+	}
+	if cfg.FieldEncryptionKeys, err = getEnvMap("FIELD_ENCRYPTION_KEYS"); err != nil {
+		return nil, err
+	}
+	if cfg.APITokenMaxTTL, err = getEnvDuration("API_TOKEN_MAX_TTL", 365*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.APITokenMaxTTL < 24*time.Hour {
+		return nil, fmt.Errorf("API_TOKEN_MAX_TTL must be at least 24h")
+	}
+	if cfg.ImpersonationTTL, err = getEnvDuration("IMPERSONATION_TTL", 30*time.Minute); err != nil {
+		return nil, err
 	}
+	if cfg.TrashRetention, err = getEnvDuration("TRASH_RETENTION", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.TrashPurgeInterval, err = getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.TrashRetention < 0 || cfg.TrashPurgeInterval <= 0 {
+		return nil, fmt.Errorf("TRASH_RETENTION must not be negative and TRASH_PURGE_INTERVAL must be positive")
+	}
+	if cfg.LDAPStartTLS, err = getEnvBool("LDAP_STARTTLS", false); err != nil {
+		return nil, err
+	}
+	if cfg.LDAPInsecureSkipVerify, err = getEnvBool("LDAP_INSECURE_SKIP_VERIFY", false); err != nil {
+		return nil, err
+	}
+	if cfg.LDAPTimeout, err = getEnvDuration("LDAP_TIMEOUT", 5*time.Second); err != nil {
+		return nil, err
+	}
+	// DN групп содержат запятые, поэтому пары разделяются точкой с запятой.
+	if cfg.LDAPGroupMap, err = getEnvMapSep("LDAP_GROUP_MAP", ";"); err != nil {
+		return nil, err
+	}
+	if cfg.LDAPAutoProvision, err = getEnvBool("LDAP_AUTO_PROVISION", false); err != nil {
+		return nil, err
+	}
+	if cfg.LDAPSyncGroups, err = getEnvBool("LDAP_SYNC_GROUPS", false); err != nil {
+		return nil, err
+	}
+	for _, name := range cfg.AuthProviders {
+		switch name {
+		case "local":
+		case "ldap":
+			if cfg.LDAPURL == "" || cfg.LDAPBaseDN == "" {
+				return nil, fmt.Errorf("LDAP_URL and LDAP_BASE_DN are required for the ldap auth provider")
+			}
+		default:
+			return nil, fmt.Errorf("AUTH_PROVIDERS: unknown provider %q", name)
+		}
+	}
+	if len(cfg.AuthProviders) == 0 {
+		return nil, fmt.Errorf("AUTH_PROVIDERS must not be empty")
+	}
+	if cfg.OIDCIssuer != "" && cfg.OIDCClientID == "" {
+		return nil, fmt.Errorf("OIDC_CLIENT_ID is required when OIDC_ISSUER is set")
+	}
+
+	switch cfg.JWTAlgorithm {
+	case "", "RS256", "EdDSA", "HS256":
+	default:
+		return nil, fmt.Errorf("JWT_ALGORITHM: unsupported algorithm %q", cfg.JWTAlgorithm)
+	}
+	if cfg.LoginAttemptStore != "memory" && cfg.LoginAttemptStore != "postgres" {
+		return nil, fmt.Errorf("LOGIN_ATTEMPT_STORE: unknown store %q", cfg.LoginAttemptStore)
+	}
+
 	return cfg, nil
 }
 
@@ -39,6 +268,73 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getEnvList(key, fallback string) []string {
+	var result []string
+	for _, item := range strings.Split(getEnv(key, fallback), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getEnvMap разбирает значение вида "ключ=значение,ключ=значение".
+func getEnvMap(key string) (map[string]string, error) {
+	return getEnvMapSep(key, ",")
+}
+
+func getEnvMapSep(key, sep string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, item := range strings.Split(getEnv(key, ""), sep) {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		// Ключ может содержать "=" (DN группы), значение — нет.
+		i := strings.LastIndex(item, "=")
+		if i < 0 || strings.TrimSpace(item[:i]) == "" || strings.TrimSpace(item[i+1:]) == "" {
+			return nil, fmt.Errorf("%s: invalid entry %q", key, item)
+		}
+		result[strings.TrimSpace(item[:i])] = strings.TrimSpace(item[i+1:])
+	}
+	return result, nil
+}
+
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", key, err)
+	}
+	return b, nil
+}
+
+func getEnvInt(key string, fallback int) (int, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return n, nil
+}
+
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return d, nil
+}
+
 func (c *Config) PostgresDSN() string {
 	return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
 		c.DBHost, c.DBPort, c.DBUser, c.DBName, c.DBPassword, c.DBSSLMode)
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListAPITokens godoc
+// @Summary Список API-токенов
+// @Description Токены текущего пользователя, включая отозванные и истёкшие. Значения токенов не возвращаются.
+// @Tags profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.APITokenResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/profile/tokens [get]
+func ListAPITokens(c *gin.Context) {
+	var tokens []models.APIToken
+	if err := db.DB.Where("user_id = ?", c.GetUint("user_id")).
+		Order("created_at DESC").
+		Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	result := make([]models.APITokenResponse, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, apiTokenResponse(t))
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ListAPITokenScopes godoc
+// @Summary Права, доступные для API-токена
+// @Description Права, которые сейчас есть у текущего пользователя; токену можно выдать только их.
+// @Tags profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.PermissionResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/profile/tokens/scopes [get]
+func ListAPITokenScopes(c *gin.Context) {
+	scopes, err := services.AvailableScopes(c.GetStringSlice("groups"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	var items []models.Permission
+	if err := db.DB.Where("code IN ?", scopes).Order("code").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	result := make([]models.PermissionResponse, 0, len(items))
+	for _, p := range items {
+		result = append(result, models.PermissionResponse{Code: p.Code, Name: p.Name})
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateAPIToken godoc
+// @Summary Создать API-токен
+// @Description Создаёт долгоживущий токен для скриптов: передаётся в заголовке Authorization: Bearer dft_...
+// @Description Токен действует от имени пользователя, но только в пределах указанных прав (scopes).
+// @Description Значение токена показывается один раз.
+// @Tags profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param payload body models.APITokenCreateRequest true "Параметры токена"
+// @Success 201 {object} models.APITokenCreatedResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/profile/tokens [post]
+func CreateAPIToken(c *gin.Context) {
+	var req models.APITokenCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Укажите название токена"})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Название токена должно быть от 1 до 100 символов"})
+		return
+	}
+
+	maxDays := int(services.APITokenMaxTTL() / (24 * time.Hour))
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxDays {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_expiry",
+			"message": "Срок действия должен быть от 1 до " + strconv.Itoa(maxDays) + " дней",
+		})
+		return
+	}
+
+	var token *models.APIToken
+	var raw string
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var err error
+		token, raw, err = services.CreateAPIToken(tx, c.GetUint("user_id"), c.GetStringSlice("groups"),
+			req.Name, req.Scopes, time.Duration(req.ExpiresInDays)*24*time.Hour)
+		if err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditCreate, "api_token", token.ID, nil, apiTokenResponse(*token))
+	})
+	if errors.Is(err, services.ErrAPITokenScope) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_scope", "message": "Токену нельзя выдать право, которого нет у пользователя"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось создать токен"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APITokenCreatedResponse{
+		APITokenResponse: apiTokenResponse(*token),
+		Token:            raw,
+	})
+}
+
+// RevokeAPIToken godoc
+// @Summary Отозвать API-токен
+// @Tags profile
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID токена"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/profile/tokens/{id} [delete]
+func RevokeAPIToken(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid token id"})
+		return
+	}
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := services.RevokeAPIToken(tx, c.GetUint("user_id"), uint(id)); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditDelete, "api_token", uint(id), nil, nil)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Токен не найден"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось отозвать токен"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Токен отозван"})
+}
+
+func apiTokenResponse(t models.APIToken) models.APITokenResponse {
+	return models.APITokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     services.APITokenScopes(t),
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		LastUsedIP: t.LastUsedIP,
+		RevokedAt:  t.RevokedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
@@ -0,0 +1,442 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListCustomAttributes godoc
+// @Summary Дополнительные поля сотрудников
+// @Description Возвращает действующие дополнительные поля карточки сотрудника со значениями справочников для enum
+// @Tags dictionary
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.CustomAttributeResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/dict/attributes [get]
+func ListCustomAttributes(c *gin.Context) {
+	attrs, err := services.LoadAttributes(db.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения справочника"})
+		return
+	}
+
+	items := make([]models.CustomAttributeResponse, 0, len(attrs))
+	for _, a := range attrs {
+		items = append(items, customAttributeResponse(a))
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// CreateCustomAttribute godoc
+// @Summary Создать дополнительное поле
+// @Description Добавляет поле карточки сотрудника. Значения справочника для enum добавляются отдельно.
+// @Description Обязательное поле проверяется при создании сотрудника и при изменении его дополнительных полей.
+// @Tags dictionary
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param data body models.CustomAttributeRequest true "Описание поля"
+// @Success 200 {object} models.CustomAttributeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 422 {object} controllers.ValidationErrorResponse
+// @Router /api/dict/attributes [post]
+func CreateCustomAttribute(c *gin.Context) {
+	var input models.CustomAttributeRequest
+	if err := bindJSON(c, &input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if err := services.ValidateAttributeDefinition(input); err != nil {
+		respondValidation(c, err)
+		return
+	}
+
+	attr := models.CustomAttribute{
+		Code:     input.Code,
+		Name:     input.Name,
+		Type:     input.Type,
+		Required: input.Required,
+		Pattern:  input.Pattern,
+		Min:      input.Min,
+		Max:      input.Max,
+	}
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := checkAttributeCodeFree(tx, input.Code, 0); err != nil {
+			return err
+		}
+		if err := tx.Create(&attr).Error; err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditCreate, "custom_attribute", attr.ID, nil, customAttributeResponse(attr))
+	})
+	if respondValidation(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка создания записи"})
+		return
+	}
+
+	c.JSON(http.StatusOK, customAttributeResponse(attr))
+}
+
+// UpdateCustomAttribute godoc
+// @Summary Обновить дополнительное поле
+// @Description Изменяет описание поля. Тип поля, у которого уже есть значения, изменить нельзя;
+// @Description новые ограничения применяются к значениям при следующем их изменении.
+// @Tags dictionary
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID поля"
+// @Param data body models.CustomAttributeRequest true "Описание поля"
+// @Success 200 {object} models.CustomAttributeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} controllers.ValidationErrorResponse
+// @Router /api/dict/attributes/{id} [put]
+func UpdateCustomAttribute(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var input models.CustomAttributeRequest
+	if err := bindJSON(c, &input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if err := services.ValidateAttributeDefinition(input); err != nil {
+		respondValidation(c, err)
+		return
+	}
+
+	var attr models.CustomAttribute
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := tx.Preload("Options").First(&attr, id).Error; err != nil {
+			return err
+		}
+		before := customAttributeResponse(attr)
+
+		if err := checkAttributeCodeFree(tx, input.Code, attr.ID); err != nil {
+			return err
+		}
+		if input.Type != attr.Type {
+			var used bool
+			if err := tx.Raw("SELECT EXISTS (SELECT 1 FROM employee_attribute_values WHERE attribute_id = ?)", attr.ID).
+				Scan(&used).Error; err != nil {
+				return err
+			}
+			if used {
+				v := &services.ValidationError{}
+				v.Add("type", "immutable", "Нельзя изменить тип поля, у которого есть значения")
+				return v
+			}
+		}
+
+		attr.Code = input.Code
+		attr.Name = input.Name
+		attr.Type = input.Type
+		attr.Required = input.Required
+		attr.Pattern = input.Pattern
+		attr.Min = input.Min
+		attr.Max = input.Max
+		if err := tx.Omit("Options").Save(&attr).Error; err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditUpdate, "custom_attribute", attr.ID, before, customAttributeResponse(attr))
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Запись не найдена"})
+		return
+	}
+	if respondValidation(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления"})
+		return
+	}
+
+	c.JSON(http.StatusOK, customAttributeResponse(attr))
+}
+
+// DeleteCustomAttribute godoc
+// @Summary Удалить дополнительное поле
+// @Description Помечает поле как удалённое: оно пропадает из карточек, значения сохраняются и
+// @Description возвращаются при восстановлении поля через /api/trash
+// @Tags dictionary
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID поля"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/dict/attributes/{id} [delete]
+func DeleteCustomAttribute(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var attr models.CustomAttribute
+		if err := tx.Preload("Options").First(&attr, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&attr).Error; err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditDelete, "custom_attribute", attr.ID, customAttributeResponse(attr), nil)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Запись не найдена"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка удаления"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Запись удалена"})
+}
+
+// CreateCustomAttributeOption godoc
+// @Summary Добавить значение справочника поля
+// @Description Добавляет значение в справочник дополнительного поля типа enum
+// @Tags dictionary
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID поля"
+// @Param data body models.CustomAttributeOptionRequest true "Значение справочника"
+// @Success 200 {object} models.CustomAttributeOptionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} controllers.ValidationErrorResponse
+// @Router /api/dict/attributes/{id}/options [post]
+func CreateCustomAttributeOption(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var input models.CustomAttributeOptionRequest
+	if err := bindJSON(c, &input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if err := services.ValidateAttributeOption(input); err != nil {
+		respondValidation(c, err)
+		return
+	}
+
+	var option models.CustomAttributeOption
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		attr, err := loadEnumAttribute(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkOptionCodeFree(tx, attr.ID, input.Code, 0); err != nil {
+			return err
+		}
+		option = models.CustomAttributeOption{AttributeID: attr.ID, Code: input.Code, Name: input.Name}
+		if err := tx.Create(&option).Error; err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditCreate, "custom_attribute_option", option.ID, nil, customAttributeOptionResponse(option))
+	})
+	if respondAttributeOptionError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, customAttributeOptionResponse(option))
+}
+
+// UpdateCustomAttributeOption godoc
+// @Summary Обновить значение справочника поля
+// @Description Изменяет значение справочника поля типа enum; при смене кода он меняется и у сотрудников
+// @Tags dictionary
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID поля"
+// @Param option_id path int true "ID значения"
+// @Param data body models.CustomAttributeOptionRequest true "Значение справочника"
+// @Success 200 {object} models.CustomAttributeOptionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} controllers.ValidationErrorResponse
+// @Router /api/dict/attributes/{id}/options/{option_id} [put]
+func UpdateCustomAttributeOption(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	optionID, _ := strconv.Atoi(c.Param("option_id"))
+
+	var input models.CustomAttributeOptionRequest
+	if err := bindJSON(c, &input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if err := services.ValidateAttributeOption(input); err != nil {
+		respondValidation(c, err)
+		return
+	}
+
+	var option models.CustomAttributeOption
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND attribute_id = ?", optionID, id).First(&option).Error; err != nil {
+			return err
+		}
+		before := customAttributeOptionResponse(option)
+		if err := checkOptionCodeFree(tx, option.AttributeID, input.Code, option.ID); err != nil {
+			return err
+		}
+
+		// У сотрудников хранится код значения.
+		if input.Code != option.Code {
+			if err := tx.Model(&models.EmployeeAttributeValue{}).
+				Where("attribute_id = ? AND value = ?", option.AttributeID, option.Code).
+				Update("value", input.Code).Error; err != nil {
+				return err
+			}
+		}
+		option.Code = input.Code
+		option.Name = input.Name
+		if err := tx.Save(&option).Error; err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditUpdate, "custom_attribute_option", option.ID, before, customAttributeOptionResponse(option))
+	})
+	if respondAttributeOptionError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, customAttributeOptionResponse(option))
+}
+
+// DeleteCustomAttributeOption godoc
+// @Summary Удалить значение справочника поля
+// @Description Помечает значение как удалённое: у сотрудников оно сохраняется, но новым не назначается
+// @Tags dictionary
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID поля"
+// @Param option_id path int true "ID значения"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/dict/attributes/{id}/options/{option_id} [delete]
+func DeleteCustomAttributeOption(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	optionID, _ := strconv.Atoi(c.Param("option_id"))
+
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var option models.CustomAttributeOption
+		if err := tx.Where("id = ? AND attribute_id = ?", optionID, id).First(&option).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&option).Error; err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditDelete, "custom_attribute_option", option.ID, customAttributeOptionResponse(option), nil)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Запись не найдена"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка удаления"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Запись удалена"})
+}
+
+// loadEnumAttribute загружает действующее поле id; для полей других типов возвращает ошибку проверки.
+func loadEnumAttribute(tx *gorm.DB, id int) (models.CustomAttribute, error) {
+	var attr models.CustomAttribute
+	if err := tx.First(&attr, id).Error; err != nil {
+		return attr, err
+	}
+	if attr.Type != models.AttributeEnum {
+		v := &services.ValidationError{}
+		v.Add("type", "not_enum", "Справочник значений есть только у полей типа enum")
+		return attr, v
+	}
+	return attr, nil
+}
+
+// checkAttributeCodeFree проверяет, что код не занят другим действующим полем.
+func checkAttributeCodeFree(tx *gorm.DB, code string, exceptID uint) error {
+	var taken int64
+	if err := tx.Model(&models.CustomAttribute{}).Where("code = ? AND id <> ?", code, exceptID).Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		v := &services.ValidationError{}
+		v.Add("code", "taken", "Поле с таким кодом уже есть")
+		return v
+	}
+	return nil
+}
+
+// checkOptionCodeFree проверяет, что код не занят другим действующим значением справочника поля.
+func checkOptionCodeFree(tx *gorm.DB, attributeID uint, code string, exceptID uint) error {
+	var taken int64
+	if err := tx.Model(&models.CustomAttributeOption{}).
+		Where("attribute_id = ? AND code = ? AND id <> ?", attributeID, code, exceptID).
+		Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		v := &services.ValidationError{}
+		v.Add("code", "taken", "Значение с таким кодом уже есть")
+		return v
+	}
+	return nil
+}
+
+// respondAttributeOptionError отвечает на ошибку изменения справочника поля; false — ошибки нет.
+func respondAttributeOptionError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Запись не найдена"})
+	case respondValidation(c, err):
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сохранения"})
+	}
+	return true
+}
+
+func customAttributeResponse(a models.CustomAttribute) models.CustomAttributeResponse {
+	options := make([]models.CustomAttributeOptionResponse, 0, len(a.Options))
+	for _, o := range a.Options {
+		options = append(options, customAttributeOptionResponse(o))
+	}
+	return models.CustomAttributeResponse{
+		ID:        a.ID,
+		Code:      a.Code,
+		Name:      a.Name,
+		Type:      a.Type,
+		Required:  a.Required,
+		Pattern:   a.Pattern,
+		Min:       a.Min,
+		Max:       a.Max,
+		Options:   options,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+func customAttributeOptionResponse(o models.CustomAttributeOption) models.CustomAttributeOptionResponse {
+	return models.CustomAttributeOptionResponse{ID: o.ID, Code: o.Code, Name: o.Name}
+}
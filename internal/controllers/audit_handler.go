@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ListAuditEvents godoc
+// @Summary Журнал аудита
+// @Description Возвращает события изменения данных, новые сверху.
+// @Description Период задаётся датами в формате YYYY-MM-DD (date_to включительно) или RFC3339.
+// @Tags audit
+// @Security BearerAuth
+// @Produce json
+// @Param user_id query int false "ID пользователя, выполнившего действие"
+// @Param actor_id query int false "ID администратора, действовавшего от имени пользователя"
+// @Param action query string false "Действие (create, update, delete, import, ...)"
+// @Param entity_type query string false "Тип сущности (employee, department, user, ...)"
+// @Param entity_id query string false "ID сущности"
+// @Param date_from query string false "Начало периода"
+// @Param date_to query string false "Конец периода"
+// @Param limit query int false "Количество записей (по умолчанию 50, максимум 500)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} models.AuditListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/audit [get]
+func ListAuditEvents(c *gin.Context) {
+	query := db.DB.Model(&models.AuditEvent{})
+
+	if v := c.Query("user_id"); v != "" {
+		userID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+		query = query.Where("user_id = ?", userID)
+	}
+	if v := c.Query("actor_id"); v != "" {
+		actorID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid actor_id"})
+			return
+		}
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if v := c.Query("action"); v != "" {
+		query = query.Where("action = ?", v)
+	}
+	if v := c.Query("entity_type"); v != "" {
+		query = query.Where("entity_type = ?", v)
+	}
+	if v := c.Query("entity_id"); v != "" {
+		query = query.Where("entity_id = ?", v)
+	}
+	if v := c.Query("date_from"); v != "" {
+		from, _, err := parseDateParam(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_from"})
+			return
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if v := c.Query("date_to"); v != "" {
+		to, dateOnly, err := parseDateParam(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_to"})
+			return
+		}
+		if dateOnly {
+			query = query.Where("created_at < ?", to.AddDate(0, 0, 1))
+		} else {
+			query = query.Where("created_at <= ?", to)
+		}
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	var events []models.AuditEvent
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	items := make([]models.AuditEventResponse, 0, len(events))
+	for _, e := range events {
+		items = append(items, models.AuditEventResponse{
+			ID:         e.ID,
+			UserID:     e.UserID,
+			ActorID:    e.ActorID,
+			Action:     e.Action,
+			EntityType: e.EntityType,
+			EntityID:   e.EntityID,
+			Before:     rawJSON(e.Before),
+			After:      rawJSON(e.After),
+			Diff:       rawJSON(e.Diff),
+			IP:         e.IP,
+			UserAgent:  e.UserAgent,
+			Method:     e.Method,
+			Path:       e.Path,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.AuditListResponse{Items: items, Total: total})
+}
+
+// parseDateParam принимает дату YYYY-MM-DD или время RFC3339.
+func parseDateParam(v string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
+
+func rawJSON(s *string) json.RawMessage {
+	if s == nil {
+		return nil
+	}
+	return json.RawMessage(*s)
+}
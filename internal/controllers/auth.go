@@ -1,13 +1,19 @@
 package controllers
 
 import (
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/MarBalueva/dashboard_efficiency/internal/db"
 	"github.com/MarBalueva/dashboard_efficiency/internal/models"
 	"github.com/MarBalueva/dashboard_efficiency/internal/services"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type LoginRequest struct {
@@ -17,14 +23,22 @@ type LoginRequest struct {
 
 // Login godoc
 // @Summary Вход пользователя
-// @Description Аутентификация пользователя и выдача JWT-токена
+// @Description Аутентификация пользователя и выдача JWT-токена.
+// @Description Пароль проверяется провайдерами из AUTH_PROVIDERS по очереди (например, LDAP, затем локальные учётные записи).
+// @Description После неудачной попытки следующая возможна только через растущую паузу,
+// @Description после нескольких подряд логин блокируется на время (ответ 429 с заголовком Retry-After).
+// @Description Если у пользователя подключена 2FA (или она обязательна для его группы), вместо токенов
+// @Description возвращается challenge_token для /auth/login/2fa (step = "2fa") или /auth/login/2fa/setup (step = "2fa_setup").
+// @Description Если пароль был сброшен администратором, сначала нужно сменить его через /auth/login/password (step = "password_change").
 // @Tags auth
 // @Accept  json
 // @Produce  json
 // @Param   payload body LoginRequest true "Login payload"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} models.TokenResponse "Токены или models.LoginChallengeResponse"
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 503 {object} map[string]string
 // @Router /auth/login [post]
 func Login(c *gin.Context) {
 	var req LoginRequest
@@ -39,29 +53,399 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-	if err := db.DB.Preload("AccessGroups.AccessGroup").
-		Where("login = ?", req.Login).
-		First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_not_found", "message": "Пользователь с таким логином не найден"})
+	ip := c.ClientIP()
+	now := time.Now()
+	wait, err := services.CheckLoginAllowed(req.Login, ip, now)
+	if err != nil && !errors.Is(err, services.ErrLoginThrottled) && !errors.Is(err, services.ErrLoginLocked) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось выполнить вход"})
+		return
+	}
+	if err != nil {
+		respondLoginThrottled(c, err, wait)
+		return
+	}
+
+	user, provider, err := services.Authenticate(c.Request.Context(), db.DB, req.Login, req.Password)
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrInvalidCredentials):
+		if err := services.RegisterLoginFailure(req.Login, ip, now); err != nil {
+			log.Println("login attempts:", err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_credentials", "message": "Неверный логин или пароль"})
+		return
+	case errors.Is(err, services.ErrProviderUnavailable):
+		log.Println("login:", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "auth_unavailable", "message": "Сервис аутентификации временно недоступен"})
+		return
+	default:
+		log.Println("login:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось выполнить вход"})
+		return
+	}
+
+	// После сброса пароля администратором сначала нужно задать новый пароль.
+	// Пароль внешнего каталога здесь не меняется.
+	if provider == services.LocalProviderName && user.MustChangePassword {
+		respondLoginChallenge(c, user, services.ChallengePasswordChange)
+		return
+	}
+
+	finishLogin(c, user)
+}
+
+// LoginPasswordChange godoc
+// @Summary Обязательная смена пароля при входе
+// @Description Шаг входа после сброса пароля администратором (step = "password_change").
+// @Description После смены пароля вход продолжается: выдаются токены или challenge для 2FA.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   payload body models.LoginPasswordChangeRequest true "Challenge-токен и новый пароль"
+// @Success 200 {object} models.TokenResponse "Токены или models.LoginChallengeResponse"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/login/password [post]
+func LoginPasswordChange(c *gin.Context) {
+	var req models.LoginPasswordChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Неверный формат запроса"})
+		return
+	}
+
+	user, ok := loadChallengeUser(c, req.ChallengeToken, services.ChallengePasswordChange)
+	if !ok {
+		return
+	}
+
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := services.SetPassword(tx, user, req.Password); err != nil {
+			return err
+		}
+		if err := services.RevokeUserTokens(tx, user.ID); err != nil {
+			return err
+		}
+		if err := services.Audit(tx, c, models.AuditUpdate, "user", user.ID, nil, gin.H{"password_changed": true}); err != nil {
+			return err
+		}
+		return tx.Preload("AccessGroups.AccessGroup").First(&user, user.ID).Error
+	})
+	if err != nil {
+		if !respondPasswordError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось сменить пароль"})
+		}
+		return
+	}
+
+	finishLogin(c, user)
+}
+
+// LoginTwoFactor godoc
+// @Summary Второй шаг входа
+// @Description Принимает challenge_token с первого шага и код из приложения-аутентификатора
+// @Description либо один из кодов восстановления.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   payload body models.LoginTwoFactorRequest true "Challenge-токен и код"
+// @Success 200 {object} models.TokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /auth/login/2fa [post]
+func LoginTwoFactor(c *gin.Context) {
+	var req models.LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Неверный формат запроса"})
+		return
+	}
+
+	user, ok := loadChallengeUser(c, req.ChallengeToken, services.ChallengeTwoFactor)
+	if !ok {
 		return
 	}
 
-	if !services.CheckPassword(req.Password, user.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "wrong_password", "message": "Неверный пароль"})
+	var tokens *models.TokenResponse
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := services.VerifySecondFactor(tx, user, req.Code, time.Now()); err != nil {
+			return err
+		}
+		var err error
+		tokens, err = services.StartSession(tx, user, c.Request.UserAgent(), c.ClientIP())
+		return err
+	})
+	if err != nil {
+		respondSecondFactorError(c, user, err)
 		return
 	}
 
-	groups := make([]string, 0, len(user.AccessGroups))
-	for _, ug := range user.AccessGroups {
-		groups = append(groups, ug.AccessGroup.Name)
+	if err := services.RegisterLoginSuccess(user.Login); err != nil {
+		log.Println("login attempts:", err)
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// LoginTwoFactorSetup godoc
+// @Summary Подключение 2FA при входе
+// @Description Для пользователей, которым 2FA обязательна, но ещё не подключена.
+// @Description Возвращает секрет и otpauth URI для приложения-аутентификатора.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   payload body models.LoginTwoFactorSetupRequest true "Challenge-токен"
+// @Success 200 {object} models.TwoFactorSetupResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/login/2fa/setup [post]
+func LoginTwoFactorSetup(c *gin.Context) {
+	var req models.LoginTwoFactorSetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Неверный формат запроса"})
+		return
 	}
 
-	token, err := services.GenerateJWT(user.ID, groups)
+	user, ok := loadChallengeUser(c, req.ChallengeToken, services.ChallengeTwoFactorSetup)
+	if !ok {
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "two_factor_enabled", "message": "Двухфакторная аутентификация уже подключена"})
+		return
+	}
+
+	setup, err := startTOTPSetup(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось начать подключение"})
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// LoginTwoFactorSetupConfirm godoc
+// @Summary Завершение подключения 2FA при входе
+// @Description Подтверждает секрет первым кодом из приложения, включает 2FA и выдаёт токены.
+// @Description Коды восстановления возвращаются один раз.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   payload body models.LoginTwoFactorRequest true "Challenge-токен и код"
+// @Success 200 {object} models.LoginTwoFactorSetupResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /auth/login/2fa/setup/confirm [post]
+func LoginTwoFactorSetupConfirm(c *gin.Context) {
+	var req models.LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Неверный формат запроса"})
+		return
+	}
+
+	user, ok := loadChallengeUser(c, req.ChallengeToken, services.ChallengeTwoFactorSetup)
+	if !ok {
+		return
+	}
+
+	var resp models.LoginTwoFactorSetupResponse
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := services.EnableTOTP(tx, user, req.Code, time.Now()); err != nil {
+			return err
+		}
+		codes, err := services.GenerateRecoveryCodes(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		tokens, err := services.StartSession(tx, user, c.Request.UserAgent(), c.ClientIP())
+		if err != nil {
+			return err
+		}
+		resp = models.LoginTwoFactorSetupResponse{TokenResponse: *tokens, RecoveryCodes: codes}
+
+		return services.Audit(tx, c, models.AuditUpdate, "user", user.ID, gin.H{"two_factor": false}, gin.H{"two_factor": true})
+	})
+	if err != nil {
+		respondSecondFactorError(c, user, err)
+		return
+	}
+
+	if err := services.RegisterLoginSuccess(user.Login); err != nil {
+		log.Println("login attempts:", err)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Refresh godoc
+// @Summary Обновление токенов
+// @Description Обменивает refresh-токен на новую пару токенов. Предъявленный refresh-токен становится недействительным.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   payload body models.RefreshRequest true "Refresh-токен"
+// @Success 200 {object} models.TokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/refresh [post]
+func Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Неверный формат запроса"})
+		return
+	}
+
+	tokens, err := services.RotateRefreshToken(db.DB, req.RefreshToken, c.Request.UserAgent(), c.ClientIP())
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrRefreshTokenInvalid), errors.Is(err, services.ErrRefreshTokenReused):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_refresh_token", "message": "Сессия истекла, войдите снова"})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось обновить токен"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout godoc
+// @Summary Выход из системы
+// @Description Отзывает refresh-токен текущей сессии
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   payload body models.RefreshRequest true "Refresh-токен"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /auth/logout [post]
+func Logout(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Неверный формат запроса"})
+		return
+	}
+
+	if err := services.RevokeRefreshToken(db.DB, req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось завершить сессию"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Сессия завершена"})
+}
+
+func respondLoginThrottled(c *gin.Context, err error, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+
+	if errors.Is(err, services.ErrLoginLocked) {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       "account_locked",
+			"message":     "Слишком много неудачных попыток. Вход временно заблокирован",
+			"retry_after": seconds,
+		})
+		return
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "too_many_attempts",
+		"message":     "Слишком частые попытки входа, повторите позже",
+		"retry_after": seconds,
+	})
+}
+
+func respondLoginChallenge(c *gin.Context, user models.User, step string) {
+	challenge, err := services.GenerateChallengeToken(user, step)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось создать токен"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LoginChallengeResponse{
+		Step:           step,
+		ChallengeToken: challenge,
+		ExpiresIn:      int(services.ChallengeTokenTTL.Seconds()),
+	})
+}
+
+// loadChallengeUser проверяет challenge-токен и ограничение частоты попыток.
+// При отказе ответ уже отправлен.
+func loadChallengeUser(c *gin.Context, challenge, step string) (models.User, bool) {
+	user, err := services.ParseChallengeToken(db.DB, challenge, step)
+	if err != nil {
+		if errors.Is(err, services.ErrChallengeInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_challenge", "message": "Время на подтверждение входа истекло, войдите снова"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось выполнить вход"})
+		}
+		return user, false
+	}
+
+	wait, err := services.CheckLoginAllowed(user.Login, c.ClientIP(), time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrLoginThrottled) || errors.Is(err, services.ErrLoginLocked) {
+			respondLoginThrottled(c, err, wait)
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось выполнить вход"})
+		}
+		return user, false
+	}
+
+	return user, true
+}
+
+func respondSecondFactorError(c *gin.Context, user models.User, err error) {
+	switch {
+	case errors.Is(err, services.ErrTwoFactorCodeInvalid):
+		registerFailedAttempt(c, user)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_code", "message": "Неверный код подтверждения"})
+	case errors.Is(err, services.ErrTwoFactorNotSetUp):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "two_factor_not_set_up", "message": "Двухфакторная аутентификация не подключена"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось выполнить вход"})
+	}
+}
+
+// finishLogin завершает вход после проверки пароля: запрашивает второй фактор,
+// если он нужен, иначе выдаёт токены. Счётчик неудачных попыток сбрасывается
+// только после прохождения второго фактора, иначе повторный ввод пароля
+// позволял бы перебирать коды.
+func finishLogin(c *gin.Context, user models.User) {
+	if step := services.SecondFactorStep(user); step != "" {
+		respondLoginChallenge(c, user, step)
+		return
+	}
+
+	if err := services.RegisterLoginSuccess(user.Login); err != nil {
+		log.Println("login attempts:", err)
+	}
+
+	var tokens *models.TokenResponse
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var err error
+		tokens, err = services.StartSession(tx, user, c.Request.UserAgent(), c.ClientIP())
+		return err
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось создать токен"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, tokens)
+}
+
+// JWKS godoc
+// @Summary Открытые ключи проверки токенов
+// @Description Набор ключей (JWKS, RFC 7517) для проверки подписи access-токенов другими сервисами.
+// @Description Тем же ключом подписываются служебные токены: сервисам нужно проверять aud = employee-dashboard-api.
+// @Description Ключ выбирается по заголовку kid токена. При ротации здесь публикуются и предыдущие ключи.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.JWKSet
+// @Router /.well-known/jwks.json [get]
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, services.PublicJWKS())
 }
@@ -5,10 +5,15 @@ import (
 	"time"
 
 	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type DashboardSummaryResponse struct {
+	// Headcount — текущая численность: работающие (не уволенные) сотрудники.
+	Headcount int64 `json:"headcount"`
+
 	AvgLoad         float64 `json:"avgLoad"`
 	AvgProductivity float64 `json:"avgProductivity"`
 	Overtime        float64 `json:"overtime"`
@@ -47,9 +52,40 @@ type TopEfficiencyItem struct {
 	Overtime     float64 `json:"overtime"`
 }
 
+type TurnoverResponse struct {
+	DateFrom string `json:"date_from"`
+	DateTo   string `json:"date_to"`
+
+	HeadcountStart   int64   `json:"headcount_start"`
+	HeadcountEnd     int64   `json:"headcount_end"`
+	AverageHeadcount float64 `json:"average_headcount"`
+
+	Hires        int64 `json:"hires"`
+	Rehires      int64 `json:"rehires"`
+	Terminations int64 `json:"terminations"`
+	// TurnoverRate — увольнения в процентах от средней численности за период.
+	TurnoverRate float64 `json:"turnover_rate"`
+
+	ByReason []TurnoverReasonItem `json:"by_reason"`
+	Monthly  []TurnoverMonth      `json:"monthly"`
+}
+
+type TurnoverReasonItem struct {
+	Reason string `json:"reason"`
+	Count  int64  `json:"count"`
+}
+
+type TurnoverMonth struct {
+	Month        string `json:"month"`
+	Headcount    int64  `json:"headcount"`
+	Hires        int64  `json:"hires"`
+	Terminations int64  `json:"terminations"`
+}
+
 // DashboardSummary godoc
 // @Summary Получение сводных показателей эффективности
-// @Description Возвращает показатели сотрудников (admin/manager — все, employee — свои)
+// @Description Возвращает показатели сотрудников: с правом dashboard.read_all — по закреплённым отделам
+// @Description (с правом scope.all_departments — по всем), иначе — только свои. Линейный руководитель всегда видит данные своих подчинённых
 // @Tags dashboard
 // @Accept json
 // @Produce json
@@ -60,6 +96,8 @@ type TopEfficiencyItem struct {
 // @Router /api/dashboard/summary [get]
 // @Security BearerAuth
 func DashboardSummary(c *gin.Context) {
+	scope := services.ScopeFor(c, services.PermDashboardReadAll)
+
 	var summary struct {
 		AvgLoad         float64
 		AvgProductivity float64
@@ -67,8 +105,10 @@ func DashboardSummary(c *gin.Context) {
 		Satisfaction    float64
 	}
 
+	headcount, _ := headcountOn(scope, time.Now())
+
 	// Считаем средние показатели через новую модель (WorkDay → WorkProcess, SatisfactionMetric)
-	db.DB.Table("work_days wd").
+	scope.Apply(db.DB.Table("work_days wd"), "wd.employee_id").
 		Select(`
 		AVG(EXTRACT(EPOCH FROM (wd.end_work_day - wd.start_work_day))/3600) AS avg_load,
 		AVG(sm.productivity) AS avg_productivity,
@@ -78,26 +118,28 @@ func DashboardSummary(c *gin.Context) {
 		Joins("LEFT JOIN satisfaction_metrics sm ON sm.work_day_id = wd.id AND sm.deleted_at IS NULL").
 		Scan(&summary)
 
-	// Эффективность по департаменту и позиции
+	// Эффективность по департаменту и позиции: рабочий день относится к отделу
+	// и должности по кадровой записи, действовавшей в этот день
 	var deptData []DeptEfficiencyItem
-	db.DB.Table("employee_hrs ehr").
+	scope.Apply(db.DB.Table("work_days wd"), "wd.employee_id").
 		Select(`
 		d.name AS department,
 		p.name AS job_level,
 		AVG(sm.productivity) AS avg_prod
 	`).
+		Joins("JOIN employee_hrs ehr ON ehr.employee_id = wd.employee_id AND ehr.deleted_at IS NULL AND " +
+			services.EffectiveHR("ehr", "wd.start_work_day")).
 		Joins("JOIN departments d ON d.id = ehr.department_id").
 		Joins("JOIN positions p ON p.id = ehr.position_id").
-		Joins("JOIN employees e ON e.id = ehr.employee_id").
-		Joins("JOIN work_days wd ON wd.employee_id = e.id AND wd.deleted_at IS NULL").
+		Joins("JOIN employees e ON e.id = wd.employee_id").
 		Joins("LEFT JOIN satisfaction_metrics sm ON sm.work_day_id = wd.id AND sm.deleted_at IS NULL").
-		Where("ehr.deleted_at IS NULL").
+		Where("wd.deleted_at IS NULL").
 		Group("d.name, p.name").
 		Scan(&deptData)
 
 	// Топ 3 по сверхурочным
 	var top []TopOvertimeItem
-	db.DB.Table("employees e").
+	scope.Apply(db.DB.Table("employees e"), "e.id").
 		Select(`
 		e.last_name || ' ' || e.first_name || ' ' || e.middle_name AS name,
 		sm.satisfaction AS job_satisfaction,
@@ -123,7 +165,7 @@ func DashboardSummary(c *gin.Context) {
 			Overtime float64
 		}
 
-		db.DB.Table("work_days wd").
+		scope.Apply(db.DB.Table("work_days wd"), "wd.employee_id").
 			Select(`
 			AVG(EXTRACT(EPOCH FROM (wd.end_work_day - wd.start_work_day))/3600) AS load,
 			AVG(GREATEST(EXTRACT(EPOCH FROM (wd.end_work_day - wd.start_work_day))/3600 - 8,0)) AS overtime
@@ -140,7 +182,7 @@ func DashboardSummary(c *gin.Context) {
 
 	// Топ 3 по продуктивности
 	var topEff []TopEfficiencyItem
-	db.DB.Table("employees e").
+	scope.Apply(db.DB.Table("employees e"), "e.id").
 		Select(`
 		e.last_name || ' ' || e.first_name || ' ' || e.middle_name AS name,
 		sm.productivity AS productivity,
@@ -155,6 +197,7 @@ func DashboardSummary(c *gin.Context) {
 		Scan(&topEff)
 
 	c.JSON(http.StatusOK, DashboardSummaryResponse{
+		Headcount:       headcount,
 		AvgLoad:         summary.AvgLoad,
 		AvgProductivity: summary.AvgProductivity,
 		Overtime:        summary.Overtime,
@@ -165,3 +208,158 @@ func DashboardSummary(c *gin.Context) {
 		TopEfficiency:   topEff,
 	})
 }
+
+// DashboardTurnover godoc
+// @Summary Текучесть кадров
+// @Description Численность на начало и конец периода, приёмы, повторные приёмы и увольнения (с разбивкой по причинам)
+// @Description и помесячная динамика. Считается по истории кадровых записей, поэтому уволенные сотрудники
+// @Description учитываются в периодах, когда работали. Период по умолчанию — последние 12 месяцев, не более 36.
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Param date_from query string false "Начало периода (YYYY-MM-DD)"
+// @Param date_to query string false "Конец периода (YYYY-MM-DD, включительно)"
+// @Success 200 {object} TurnoverResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/dashboard/turnover [get]
+func DashboardTurnover(c *gin.Context) {
+	scope := services.ScopeFor(c, services.PermDashboardReadAll)
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if v := c.Query("date_to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_to format, expected YYYY-MM-DD"})
+			return
+		}
+		to = t
+	}
+	from := time.Date(to.Year(), to.Month()-11, 1, 0, 0, 0, 0, time.UTC)
+	if v := c.Query("date_from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date_from format, expected YYYY-MM-DD"})
+			return
+		}
+		from = t
+	}
+	if from.After(to) || from.AddDate(3, 0, 0).Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_period", "message": "Период должен быть не длиннее 36 месяцев, начало — не позже конца"})
+		return
+	}
+
+	resp := TurnoverResponse{
+		DateFrom: from.Format("2006-01-02"),
+		DateTo:   to.Format("2006-01-02"),
+		ByReason: []TurnoverReasonItem{},
+		Monthly:  []TurnoverMonth{},
+	}
+
+	var err error
+	if resp.HeadcountStart, err = headcountOn(scope, from); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if resp.HeadcountEnd, err = headcountOn(scope, to); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if resp.Hires, resp.Rehires, err = countHires(scope, from, to); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	if err := terminations(scope, from, to).
+		Select("COALESCE(tr.name, '') AS reason, COUNT(*) AS count").
+		Joins("LEFT JOIN termination_reasons tr ON tr.id = ehr.termination_reason_id").
+		Group("tr.name").
+		Order("count DESC").
+		Scan(&resp.ByReason).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	for _, r := range resp.ByReason {
+		resp.Terminations += r.Count
+	}
+
+	resp.AverageHeadcount = float64(resp.HeadcountStart+resp.HeadcountEnd) / 2
+	if resp.AverageHeadcount > 0 {
+		resp.TurnoverRate = float64(resp.Terminations) / resp.AverageHeadcount * 100
+	}
+
+	for m := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(to); m = m.AddDate(0, 1, 0) {
+		start, end := m, m.AddDate(0, 1, -1)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+
+		month := TurnoverMonth{Month: m.Format("2006-01")}
+		hires, rehires, err := countHires(scope, start, end)
+		if err == nil {
+			month.Hires = hires + rehires
+			err = terminations(scope, start, end).Count(&month.Terminations).Error
+		}
+		if err == nil {
+			month.Headcount, err = headcountOn(scope, end)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		resp.Monthly = append(resp.Monthly, month)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// employmentRecords — кадровые записи не удалённых сотрудников в области видимости.
+func employmentRecords(scope services.EmployeeScope) *gorm.DB {
+	return scope.Apply(db.DB.Table("employee_hrs ehr"), "ehr.employee_id").
+		Joins("JOIN employees e ON e.id = ehr.employee_id AND e.deleted_at IS NULL").
+		Where("ehr.deleted_at IS NULL")
+}
+
+// headcountOn считает сотрудников, работавших в день day.
+func headcountOn(scope services.EmployeeScope, day time.Time) (int64, error) {
+	var n int64
+	err := employmentRecords(scope).
+		Where(services.EffectiveHR("ehr", "CAST(? AS date)"), day, day).
+		Where("(ehr.fire_date IS NULL OR ehr.fire_date > ?)", day).
+		Distinct("ehr.employee_id").
+		Count(&n).Error
+	return n, err
+}
+
+// countHires считает приёмы и повторные приёмы с from по to включительно.
+func countHires(scope services.EmployeeScope, from, to time.Time) (hires, rehires int64, err error) {
+	var rows []struct {
+		ChangeType string
+		Count      int64
+	}
+	err = employmentRecords(scope).
+		Select("ehr.change_type, COUNT(*) AS count").
+		Where("ehr.change_type IN ? AND ehr.valid_from BETWEEN CAST(? AS date) AND CAST(? AS date)",
+			[]string{services.EmploymentHire, services.EmploymentRehire}, from, to).
+		Group("ehr.change_type").
+		Scan(&rows).Error
+	for _, r := range rows {
+		if r.ChangeType == services.EmploymentRehire {
+			rehires = r.Count
+		} else {
+			hires = r.Count
+		}
+	}
+	return hires, rehires, err
+}
+
+// terminations отбирает увольнения с from по to включительно: дата увольнения
+// есть только у записи, открытой увольнением.
+func terminations(scope services.EmployeeScope, from, to time.Time) *gorm.DB {
+	return employmentRecords(scope).
+		Where("ehr.fire_date >= ? AND ehr.fire_date < ?", from, to.AddDate(0, 0, 1))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/MarBalueva/dashboard_efficiency/internal/db"
 	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -55,10 +56,29 @@ func CreateAccessGroup(c *gin.Context) {
 
 func UpdateAccessGroup(c *gin.Context) {
 	UpdateDictionary(c, &models.AccessGroup{})
+	services.InvalidatePermissions()
 }
 
 func DeleteAccessGroup(c *gin.Context) {
 	DeleteDictionary(c, &models.AccessGroup{})
+	services.InvalidatePermissions()
+}
+
+func ListTerminationReasons(c *gin.Context) {
+	var items []models.TerminationReason
+	ListDictionary(c, &items)
+}
+
+func CreateTerminationReason(c *gin.Context) {
+	CreateDictionary(c, &models.TerminationReason{})
+}
+
+func UpdateTerminationReason(c *gin.Context) {
+	UpdateDictionary(c, &models.TerminationReason{})
+}
+
+func DeleteTerminationReason(c *gin.Context) {
+	DeleteDictionary(c, &models.TerminationReason{})
 }
 
 // ListDepartments godoc
@@ -88,6 +108,15 @@ func DeleteAccessGroup(c *gin.Context) {
 // @Success 200 {array} models.AccessGroup
 // @Failure 401 {object} map[string]string
 // @Router /api/dict/access-groups [get]
+// ListTerminationReasons godoc
+// @Summary Получить список причин увольнения
+// @Description Возвращает список причин увольнения (без удалённых)
+// @Tags dictionary
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.TerminationReason
+// @Failure 401 {object} map[string]string
+// @Router /api/dict/termination-reasons [get]
 func ListDictionary(c *gin.Context, model interface{}) {
 	if err := db.DB.Find(model).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения справочника"})
@@ -119,6 +148,7 @@ func ListDictionary(c *gin.Context, model interface{}) {
 // @Success 200 {object} map[string]string{message=string}
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
+// @Failure 422 {object} controllers.ValidationErrorResponse
 // @Router /api/dict/positions [post]
 // CreateAccessGroup godoc
 // @Summary Создать группу доступа
@@ -132,12 +162,30 @@ func ListDictionary(c *gin.Context, model interface{}) {
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Router /api/dict/access-groups [post]
+// CreateTerminationReason godoc
+// @Summary Создать причину увольнения
+// @Description Добавляет причину увольнения в справочник
+// @Tags dictionary
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param data body models.DictionaryRequest true "Данные причины увольнения"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/dict/termination-reasons [post]
 func CreateDictionary(c *gin.Context, model interface{}) {
 	var input models.DictionaryRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
 		return
 	}
+	if _, ok := model.(*models.Position); ok {
+		if err := services.ValidateSalaryRange(input.MinSalary, input.MaxSalary); err != nil {
+			respondValidation(c, err)
+			return
+		}
+	}
 
 	switch m := model.(type) {
 	case *models.Department:
@@ -146,12 +194,25 @@ func CreateDictionary(c *gin.Context, model interface{}) {
 	case *models.Position:
 		m.Name = input.Name
 		m.Code = input.Code
+		m.MinSalary = input.MinSalary
+		m.MaxSalary = input.MaxSalary
 	case *models.AccessGroup:
 		m.Name = input.Name
 		m.Code = input.Code
+	case *models.TerminationReason:
+		m.Name = input.Name
+		m.Code = input.Code
 	}
 
-	if err := db.DB.Create(model).Error; err != nil {
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := tx.Create(model).Error; err != nil {
+			return err
+		}
+		after := dictionaryBase(model)
+		return services.Audit(tx, c, models.AuditCreate, dictionaryEntity(model), after.ID, nil, after)
+	})
+
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка создания записи"})
 		return
 	}
@@ -185,10 +246,11 @@ func CreateDictionary(c *gin.Context, model interface{}) {
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 422 {object} controllers.ValidationErrorResponse
 // @Router /api/dict/positions/{id} [put]
 // UpdateAccessGroup godoc
 // @Summary Обновить группу доступа
-// @Description Обновляет данные группы доступа
+// @Description Обновляет данные группы доступа. Код группы, в которой есть пользователи, изменить нельзя (409).
 // @Tags dictionary
 // @Security BearerAuth
 // @Accept json
@@ -199,7 +261,22 @@ func CreateDictionary(c *gin.Context, model interface{}) {
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /api/dict/access-groups/{id} [put]
+// UpdateTerminationReason godoc
+// @Summary Обновить причину увольнения
+// @Description Обновляет причину увольнения
+// @Tags dictionary
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID причины увольнения"
+// @Param data body models.DictionaryRequest true "Новые данные причины увольнения"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/dict/termination-reasons/{id} [put]
 func UpdateDictionary(c *gin.Context, model interface{}) {
 	id, _ := strconv.Atoi(c.Param("id"))
 
@@ -217,6 +294,30 @@ func UpdateDictionary(c *gin.Context, model interface{}) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
 		return
 	}
+	if _, ok := model.(*models.Position); ok {
+		if err := services.ValidateSalaryRange(input.MinSalary, input.MaxSalary); err != nil {
+			respondValidation(c, err)
+			return
+		}
+	}
+
+	// Код группы попадает в claim groups выданных токенов: пока в группе есть
+	// пользователи, его смена разошлась бы с их токенами.
+	if group, ok := model.(*models.AccessGroup); ok && group.Code != input.Code {
+		var members int64
+		if err := db.DB.Model(&models.UserAccessGroup{}).
+			Where("access_group_id = ?", group.ID).
+			Count(&members).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения записи"})
+			return
+		}
+		if members > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "group_in_use", "message": "Нельзя изменить код группы, в которой есть пользователи"})
+			return
+		}
+	}
+
+	before := dictionaryBase(model)
 
 	switch m := model.(type) {
 	case *models.Department:
@@ -225,12 +326,24 @@ func UpdateDictionary(c *gin.Context, model interface{}) {
 	case *models.Position:
 		m.Name = input.Name
 		m.Code = input.Code
+		m.MinSalary = input.MinSalary
+		m.MaxSalary = input.MaxSalary
 	case *models.AccessGroup:
 		m.Name = input.Name
 		m.Code = input.Code
+	case *models.TerminationReason:
+		m.Name = input.Name
+		m.Code = input.Code
 	}
 
-	if err := db.DB.Save(model).Error; err != nil {
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := tx.Save(model).Error; err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditUpdate, dictionaryEntity(model), id, before, dictionaryBase(model))
+	})
+
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления"})
 		return
 	}
@@ -271,13 +384,67 @@ func UpdateDictionary(c *gin.Context, model interface{}) {
 // @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /api/dict/access-groups/{id} [delete]
+// DeleteTerminationReason godoc
+// @Summary Удалить причину увольнения
+// @Description Помечает причину увольнения как удалённую; в истории сотрудников она сохраняется
+// @Tags dictionary
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID причины увольнения"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/dict/termination-reasons/{id} [delete]
 func DeleteDictionary(c *gin.Context, model interface{}) {
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	if err := db.DB.Delete(model, id).Error; err != nil {
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := tx.First(model, id).Error; err != nil {
+			return err
+		}
+		before := dictionaryBase(model)
+		if err := tx.Delete(model, id).Error; err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditDelete, dictionaryEntity(model), id, before, nil)
+	})
+
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Запись не найдена"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка удаления"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Запись удалена"})
 }
+
+func dictionaryEntity(model interface{}) string {
+	switch model.(type) {
+	case *models.Department:
+		return "department"
+	case *models.Position:
+		return "position"
+	case *models.AccessGroup:
+		return "access_group"
+	case *models.TerminationReason:
+		return "termination_reason"
+	}
+	return "dictionary"
+}
+
+func dictionaryBase(model interface{}) models.BaseDictionary {
+	switch m := model.(type) {
+	case *models.Department:
+		return m.BaseDictionary
+	case *models.Position:
+		return m.BaseDictionary
+	case *models.AccessGroup:
+		return m.BaseDictionary
+	case *models.TerminationReason:
+		return m.BaseDictionary
+	}
+	return models.BaseDictionary{}
+}
@@ -1,9 +1,13 @@
 package controllers
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/MarBalueva/dashboard_efficiency/internal/db"
@@ -15,78 +19,383 @@ import (
 
 // ListEmployees godoc
 // @Summary Список сотрудников
-// @Description admin и manager видят всех, employee — только себя
+// @Description Пользователи с правом employees.read_all видят сотрудников закреплённых за ними отделов
+// @Description (с правом scope.all_departments — всех), остальные — только себя. Линейный руководитель видит и своих подчинённых (прямых и косвенных).
+// @Description Сортировка: sort=поле или sort=-поле (по убыванию), несколько полей через запятую;
+// @Description поля — как в ответе (last_name, department, hire_date, ...), кроме зашифрованных salary и birth_date.
+// @Description Оклад и дата рождения возвращаются только пользователям с правом employees.salary.read, у остальных — null.
+// @Description Фильтр по дополнительным полям: attr.<код>=значение (для enum — коды через запятую),
+// @Description для числовых полей и дат — также attr.<код>.from и attr.<код>.to (включительно).
 // @Tags employees
 // @Security BearerAuth
 // @Produce json
-// @Success 200 {array} models.EmployeeFullResponse
+// @Param page query int false "Номер страницы (с 1)"
+// @Param page_size query int false "Размер страницы (по умолчанию 50, максимум 500)"
+// @Param sort query string false "Сортировка, например -hire_date,last_name"
+// @Param q query string false "Поиск по фамилии, имени и отчеству"
+// @Param department_id query string false "ID отделов через запятую"
+// @Param position_id query string false "ID должностей через запятую"
+// @Param manager_id query string false "ID руководителей через запятую — их прямые подчинённые"
+// @Param is_remote query bool false "Удалённая работа"
+// @Param hire_date_from query string false "Дата приёма с (YYYY-MM-DD)"
+// @Param hire_date_to query string false "Дата приёма по (YYYY-MM-DD, включительно)"
+// @Param status query string false "active — работающие, fired — уволенные, all — все (по умолчанию)"
+// @Success 200 {object} models.EmployeeListResponse
+// @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
 // @Router /api/employees [get]
 func ListEmployees(c *gin.Context) {
-	userID := c.GetUint("user_id")
+	query := db.DB.Table("employee_hrs").
+		Joins("JOIN employees ON employees.id = employee_hrs.employee_id AND employees.deleted_at IS NULL").
+		Joins("LEFT JOIN departments ON departments.id = employee_hrs.department_id").
+		Joins("LEFT JOIN positions ON positions.id = employee_hrs.position_id").
+		Where("employee_hrs.deleted_at IS NULL AND employee_hrs.valid_to IS NULL")
 
-	var user models.User
-	if err := db.DB.
-		Preload("AccessGroups.AccessGroup").
-		First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+	query = services.ScopeFor(c, services.PermEmployeesReadAll).Apply(query, "employee_hrs.employee_id")
+
+	query, ok := applyEmployeeFilters(c, query)
+	if !ok {
 		return
 	}
 
-	query := db.DB.
-		Model(&models.EmployeeHR{}).
-		Preload("Employee").
-		Preload("Department").
-		Preload("Position")
+	order, badField := parseEmployeeSort(c.Query("sort"))
+	if badField != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_sort", "message": "Нельзя сортировать по полю " + badField})
+		return
+	}
 
-	if !services.HasAnyGroup(user, "admin", "manager") {
-		query = query.Where("employee_id = ?", user.EmployeeID)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	if pageSize <= 0 || pageSize > 500 {
+		pageSize = 50
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	var rows []employeeRow
+	if err := query.
+		Select(`employees.id AS id, employees.last_name, employees.first_name,
+			COALESCE(employees.middle_name, '') AS middle_name,
+			COALESCE(departments.name, '') AS department, COALESCE(positions.name, '') AS position,
+			employee_hrs.manager_id, employee_hrs.is_remote, employee_hrs.birth_date, employee_hrs.hire_date, employee_hrs.fire_date,
+			employee_hrs.salary, employee_hrs.created_at, employees.version`).
+		Order(order).
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
 	}
 
-	var hrList []models.EmployeeHR
-	if err := query.Find(&hrList).Error; err != nil {
+	ids := make([]uint, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.ID)
+	}
+	attributes, err := services.EmployeeAttributes(db.DB, ids)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 		return
 	}
 
-	result := make([]models.EmployeeFullResponse, 0, len(hrList))
-	for _, hr := range hrList {
-		result = append(result, models.EmployeeFullResponse{
-			ID:         hr.Employee.ID,
-			LastName:   hr.Employee.LastName,
-			FirstName:  hr.Employee.FirstName,
-			MiddleName: hr.Employee.MiddleName,
-			Department: hr.Department.Name,
-			Position:   hr.Position.Name,
-			IsRemote:   hr.IsRemote,
-			BirthDate:  hr.BirthDate,
-			HireDate:   hr.HireDate,
-			FireDate:   hr.FireDate,
-			Salary:     hr.Salary,
-			CreatedAt:  hr.CreatedAt,
-		})
+	sensitive := services.HasPermission(c, services.PermEmployeesSalaryRead)
+	items := make([]models.EmployeeFullResponse, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, employeeResponse(r, sensitive, attributes[r.ID]))
+	}
+
+	c.JSON(http.StatusOK, models.EmployeeListResponse{
+		Items: items,
+		Meta: models.PageMeta{
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+			Pages:    int((total + int64(pageSize) - 1) / int64(pageSize)),
+		},
+	})
+}
+
+// employeeRow — строка выборки списка сотрудников; поля совпадают с EmployeeFullResponse.
+type employeeRow struct {
+	ID         uint
+	LastName   string
+	FirstName  string
+	MiddleName string
+	Department string
+	Position   string
+	ManagerID  *uint
+	IsRemote   bool
+	BirthDate  models.EncryptedDate
+	HireDate   time.Time
+	FireDate   *time.Time
+	Salary     models.EncryptedFloat
+	CreatedAt  time.Time
+	Version    uint
+}
+
+// employeeResponse формирует ответ; без sensitive оклад и дата рождения не отдаются.
+func employeeResponse(r employeeRow, sensitive bool, attributes map[string]interface{}) models.EmployeeFullResponse {
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+	resp := models.EmployeeFullResponse{
+		ID:         r.ID,
+		LastName:   r.LastName,
+		FirstName:  r.FirstName,
+		MiddleName: r.MiddleName,
+		Department: r.Department,
+		Position:   r.Position,
+		ManagerID:  r.ManagerID,
+		IsRemote:   r.IsRemote,
+		HireDate:   r.HireDate,
+		FireDate:   r.FireDate,
+		Attributes: attributes,
+		CreatedAt:  r.CreatedAt,
+		Version:    r.Version,
+	}
+	if sensitive {
+		birthDate := r.BirthDate.Time
+		salary := float64(r.Salary)
+		resp.BirthDate = &birthDate
+		resp.Salary = &salary
+	}
+	return resp
+}
+
+// employeeSortColumns сопоставляет поля EmployeeFullResponse колонкам запроса.
+var employeeSortColumns = map[string]string{
+	"id":          "employees.id",
+	"last_name":   "employees.last_name",
+	"first_name":  "employees.first_name",
+	"middle_name": "employees.middle_name",
+	"department":  "departments.name",
+	"position":    "positions.name",
+	"is_remote":   "employee_hrs.is_remote",
+	"hire_date":   "employee_hrs.hire_date",
+	"fire_date":   "employee_hrs.fire_date",
+	"created_at":  "employee_hrs.created_at",
+}
+
+// parseEmployeeSort строит ORDER BY из параметра sort. Для стабильного
+// порядка страниц в конец всегда добавляется сортировка по ID.
+// Второе значение — первое неизвестное поле, если оно есть.
+func parseEmployeeSort(raw string) (string, string) {
+	var parts []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		dir := "ASC"
+		if strings.HasPrefix(field, "-") {
+			dir, field = "DESC", field[1:]
+		}
+		column, ok := employeeSortColumns[field]
+		if !ok {
+			return "", field
+		}
+		parts = append(parts, column+" "+dir)
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "employees.last_name ASC", "employees.first_name ASC")
+	}
+	return strings.Join(append(parts, "employees.id ASC"), ", "), ""
+}
+
+// applyEmployeeFilters добавляет к запросу фильтры списка сотрудников.
+// При неверном параметре отвечает 400 и возвращает false.
+func applyEmployeeFilters(c *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
+	invalid := func(param string) (*gorm.DB, bool) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_filter", "message": "Неверное значение параметра " + param})
+		return nil, false
+	}
+
+	for param, column := range map[string]string{
+		"department_id": "employee_hrs.department_id",
+		"position_id":   "employee_hrs.position_id",
+		"manager_id":    "employee_hrs.manager_id",
+	} {
+		if v := c.Query(param); v != "" {
+			ids, err := parseIDList(v)
+			if err != nil {
+				return invalid(param)
+			}
+			query = query.Where(column+" IN ?", ids)
+		}
+	}
+
+	if v := c.Query("is_remote"); v != "" {
+		remote, err := strconv.ParseBool(v)
+		if err != nil {
+			return invalid("is_remote")
+		}
+		query = query.Where("employee_hrs.is_remote = ?", remote)
+	}
+
+	if v := c.Query("hire_date_from"); v != "" {
+		from, _, err := parseDateParam(v)
+		if err != nil {
+			return invalid("hire_date_from")
+		}
+		query = query.Where("employee_hrs.hire_date >= ?", from)
+	}
+	if v := c.Query("hire_date_to"); v != "" {
+		to, dateOnly, err := parseDateParam(v)
+		if err != nil {
+			return invalid("hire_date_to")
+		}
+		if dateOnly {
+			query = query.Where("employee_hrs.hire_date < ?", to.AddDate(0, 0, 1))
+		} else {
+			query = query.Where("employee_hrs.hire_date <= ?", to)
+		}
+	}
+
+	now := time.Now()
+	switch c.DefaultQuery("status", "all") {
+	case "all":
+	case "active":
+		query = query.Where("(employee_hrs.fire_date IS NULL OR employee_hrs.fire_date > ?)", now)
+	case "fired":
+		query = query.Where("employee_hrs.fire_date <= ?", now)
+	default:
+		return invalid("status")
+	}
+
+	// Каждое слово поиска должно встречаться в фамилии, имени или отчестве.
+	for _, word := range strings.Fields(c.Query("q")) {
+		pattern := "%" + escapeLike(word) + "%"
+		query = query.Where(
+			"(employees.last_name ILIKE ? OR employees.first_name ILIKE ? OR employees.middle_name ILIKE ?)",
+			pattern, pattern, pattern,
+		)
+	}
+
+	return applyAttributeFilters(c, query)
+}
+
+// applyAttributeFilters добавляет фильтры attr.<код>, attr.<код>.from и attr.<код>.to
+// по дополнительным полям. При неверном параметре отвечает 400 и возвращает false.
+func applyAttributeFilters(c *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
+	var params []string
+	for param := range c.Request.URL.Query() {
+		if strings.HasPrefix(param, "attr.") {
+			params = append(params, param)
+		}
+	}
+	if len(params) == 0 {
+		return query, true
+	}
+	sort.Strings(params)
+
+	attrs, err := services.LoadAttributes(db.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return nil, false
+	}
+	byCode := make(map[string]models.CustomAttribute, len(attrs))
+	for _, a := range attrs {
+		byCode[a.Code] = a
+	}
+
+	invalid := func(param string) (*gorm.DB, bool) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_filter", "message": "Неверное значение параметра " + param})
+		return nil, false
+	}
+	const exists = "EXISTS (SELECT 1 FROM employee_attribute_values v WHERE v.employee_id = employees.id AND v.attribute_id = ? AND "
+
+	for _, param := range params {
+		code, op := strings.TrimPrefix(param, "attr."), ""
+		if i := strings.IndexByte(code, '.'); i >= 0 {
+			code, op = code[:i], code[i+1:]
+		}
+		attr, ok := byCode[code]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_filter", "message": "Неизвестное дополнительное поле " + code})
+			return nil, false
+		}
+		raw := c.Query(param)
+
+		switch op {
+		case "":
+			var values []string
+			items := []string{raw}
+			if attr.Type == models.AttributeEnum {
+				items = strings.Split(raw, ",")
+			}
+			for _, item := range items {
+				value, err := services.ParseAttributeFilter(attr, item)
+				if err != nil {
+					return invalid(param)
+				}
+				values = append(values, value)
+			}
+			query = query.Where(exists+"v.value IN ?)", attr.ID, values)
+		case "from", "to":
+			if attr.Type != models.AttributeNumber && attr.Type != models.AttributeDate {
+				return invalid(param)
+			}
+			value, err := services.ParseAttributeFilter(attr, raw)
+			if err != nil {
+				return invalid(param)
+			}
+			cmp := ">="
+			if op == "to" {
+				cmp = "<="
+			}
+			// Даты хранятся как YYYY-MM-DD и сравниваются строками, числа — приводятся к numeric.
+			column := "v.value"
+			var arg interface{} = value
+			if attr.Type == models.AttributeNumber {
+				column = "v.value::numeric"
+				arg, _ = strconv.ParseFloat(value, 64)
+			}
+			query = query.Where(exists+column+" "+cmp+" ?)", attr.ID, arg)
+		default:
+			return invalid(param)
+		}
+	}
+	return query, true
+}
+
+func parseIDList(raw string) ([]uint, error) {
+	var ids []uint
+	for _, item := range strings.Split(raw, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(item), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, uint(id))
 	}
+	return ids, nil
+}
 
-	c.JSON(http.StatusOK, result)
+// escapeLike экранирует спецсимволы шаблона LIKE.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
 // GetEmployeeByID godoc
 // @Summary Получить сотрудника по ID
-// @Description
-// admin и manager могут получать любого сотрудника,
-// employee — только себя
+// @Description Пользователи с правом employees.read_all могут получать сотрудников закреплённых за ними отделов
+// @Description (с правом scope.all_departments — любого), остальные — только себя и своих подчинённых
 // @Tags employees
 // @Security BearerAuth
 // @Produce json
 // @Param id path int true "ID сотрудника"
 // @Success 200 {object} models.EmployeeFullResponse
+// @Header 200 {string} ETag "Версия карточки для If-Match"
 // @Failure 401 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /api/employees/{id} [get]
 func GetEmployeeByID(c *gin.Context) {
-	userID := c.GetUint("user_id")
-
 	employeeIDParam := c.Param("id")
 	employeeID, err := strconv.ParseUint(employeeIDParam, 10, 64)
 	if err != nil {
@@ -94,19 +403,14 @@ func GetEmployeeByID(c *gin.Context) {
 		return
 	}
 
-	var user models.User
-	if err := db.DB.
-		Preload("AccessGroups.AccessGroup").
-		First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+	allowed, err := services.ScopeFor(c, services.PermEmployeesReadAll).Allows(db.DB, uint(employeeID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 		return
 	}
-
-	if !services.HasAnyGroup(user, "admin", "manager") {
-		if user.EmployeeID != uint(employeeID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
-			return
-		}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
 	}
 
 	var hr models.EmployeeHR
@@ -114,7 +418,7 @@ func GetEmployeeByID(c *gin.Context) {
 		Preload("Employee").
 		Preload("Department").
 		Preload("Position").
-		Where("employee_id = ?", employeeID).
+		Where("employee_id = ? AND valid_to IS NULL", employeeID).
 		First(&hr).Error; err != nil {
 
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -125,22 +429,30 @@ func GetEmployeeByID(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 		return
 	}
+	attributes, err := services.EmployeeAttributes(db.DB, []uint{hr.EmployeeID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
 
-	response := models.EmployeeFullResponse{
+	response := employeeResponse(employeeRow{
 		ID:         hr.Employee.ID,
 		LastName:   hr.Employee.LastName,
 		FirstName:  hr.Employee.FirstName,
 		MiddleName: hr.Employee.MiddleName,
 		Department: hr.Department.Name,
 		Position:   hr.Position.Name,
+		ManagerID:  hr.ManagerID,
 		IsRemote:   hr.IsRemote,
 		BirthDate:  hr.BirthDate,
 		HireDate:   hr.HireDate,
 		FireDate:   hr.FireDate,
 		Salary:     hr.Salary,
 		CreatedAt:  hr.CreatedAt,
-	}
+		Version:    hr.Employee.Version,
+	}, services.HasPermission(c, services.PermEmployeesSalaryRead), attributes[hr.EmployeeID])
 
+	c.Header("ETag", services.EmployeeETag(hr.Employee.Version))
 	c.JSON(http.StatusOK, response)
 }
 
@@ -155,27 +467,31 @@ func GetEmployeeByID(c *gin.Context) {
 // @Success 200 {object} map[string]string{message=string}
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
+// @Failure 422 {object} controllers.ValidationErrorResponse
 // @Failure 500 {object} map[string]string
 // @Router /api/employees [post]
 func CreateEmployee(c *gin.Context) {
 	var input models.EmployeeCreateRequest
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid data"})
+	if err := bindJSON(c, &input); err != nil {
+		respondBindError(c, err)
 		return
 	}
 
-	birthDate, err := time.Parse("2006-01-02", input.BirthDate)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid birth_date format, expected YYYY-MM-DD"})
+	if !services.ScopeFor(c, services.PermEmployeesReadAll).AllowsDepartment(input.DepartmentID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
 		return
 	}
-	hireDate, err := time.Parse("2006-01-02", input.HireDate)
+
+	birthDate, hireDate, attributes, err := services.ValidateEmployee(db.DB, input, nil)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hire_date format, expected YYYY-MM-DD"})
+		if !respondValidation(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		}
 		return
 	}
+	trimEmployeeNames(&input)
 
-	err = db.DB.Transaction(func(tx *gorm.DB) error {
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
 		employee := models.Employee{
 			LastName:   input.LastName,
 			FirstName:  input.FirstName,
@@ -190,11 +506,24 @@ func CreateEmployee(c *gin.Context) {
 			DepartmentID: input.DepartmentID,
 			PositionID:   input.PositionID,
 			IsRemote:     input.IsRemote,
-			BirthDate:    birthDate,
+			BirthDate:    models.EncryptedDate{Time: birthDate},
 			HireDate:     hireDate,
-			Salary:       input.Salary,
+			Salary:       models.EncryptedFloat(input.Salary),
+			ValidFrom:    hireDate,
+			ChangeType:   services.EmploymentHire,
+		}
+		if err := tx.Create(&hr).Error; err != nil {
+			return err
 		}
-		return tx.Create(&hr).Error
+		if err := services.SaveEmployeeAttributes(tx, employee.ID, attributes, true); err != nil {
+			return err
+		}
+
+		after, err := loadEmployeeSnapshot(tx, employee.ID)
+		if err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditCreate, "employee", employee.ID, nil, after)
 	})
 
 	if err != nil {
@@ -207,73 +536,327 @@ func CreateEmployee(c *gin.Context) {
 
 // UpdateEmployee godoc
 // @Summary Обновить сотрудника
-// @Description Обновляет персональные и кадровые данные сотрудника
+// @Description Заменяет персональные и кадровые данные сотрудника целиком. Изменение отдела, должности,
+// @Description оклада или формата работы добавляет запись в историю с сегодняшнего дня.
+// @Description С заголовком If-Match (ETag из GET) изменение применяется, только если карточку никто не изменил.
+// @Description Без права employees.salary.read оклад и дату рождения можно не передавать: сохраняются текущие значения; их изменение — 403.
 // @Tags employees
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path int true "ID сотрудника"
+// @Param If-Match header string false "ETag карточки"
 // @Param data body models.EmployeeCreateRequest true "Новые данные сотрудника"
 // @Success 200 {object} map[string]string{message=string}
+// @Header 200 {string} ETag "Новая версия карточки"
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Failure 422 {object} controllers.ValidationErrorResponse
 // @Failure 500 {object} map[string]string
 // @Router /api/employees/{id} [put]
 func UpdateEmployee(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := employeeWriteParam(c)
+	if !ok {
+		return
+	}
+	expected, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
 
 	var input models.EmployeeCreateRequest
-	if err := c.ShouldBindJSON(&input); err != nil {
+	if err := bindJSON(c, &input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	updateEmployee(c, id, input, expected)
+}
+
+// PatchEmployee godoc
+// @Summary Частично обновить сотрудника
+// @Description Применяет JSON Merge Patch (RFC 7396) к карточке сотрудника: переданные поля заменяются,
+// @Description null очищает необязательное поле (middle_name, attributes), для остальных полей null — ошибка 422;
+// @Description отсутствующие поля не меняются; false и 0 — обычные значения.
+// @Description Без If-Match изменение сверяется с версией, прочитанной при обработке запроса.
+// @Tags employees
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID сотрудника"
+// @Param If-Match header string false "ETag карточки"
+// @Param data body models.EmployeeCreateRequest true "Изменяемые поля"
+// @Success 200 {object} map[string]string{message=string}
+// @Header 200 {string} ETag "Новая версия карточки"
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Failure 422 {object} controllers.ValidationErrorResponse
+// @Failure 500 {object} map[string]string
+// @Router /api/employees/{id} [patch]
+func PatchEmployee(c *gin.Context) {
+	id, ok := employeeWriteParam(c)
+	if !ok {
+		return
+	}
+	expected, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid data"})
 		return
 	}
 
-	birthDate, err := time.Parse("2006-01-02", input.BirthDate)
+	var employee models.Employee
+	if err := db.DB.First(&employee, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if expected != 0 && expected != employee.Version {
+		respondVersionConflict(c)
+		return
+	}
+	current, err := services.CurrentEmployment(db.DB, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid birth_date format, expected YYYY-MM-DD"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 		return
 	}
-	hireDate, err := time.Parse("2006-01-02", input.HireDate)
+	attributes, err := services.EmployeeAttributes(db.DB, []uint{id})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hire_date format, expected YYYY-MM-DD"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
 		return
 	}
+	currentAttributes := attributes[id]
+	if currentAttributes == nil {
+		currentAttributes = map[string]interface{}{}
+	}
+
+	base, err := json.Marshal(models.EmployeeCreateRequest{
+		LastName:     employee.LastName,
+		FirstName:    employee.FirstName,
+		MiddleName:   employee.MiddleName,
+		DepartmentID: current.DepartmentID,
+		PositionID:   current.PositionID,
+		IsRemote:     current.IsRemote,
+		BirthDate:    current.BirthDate.Format("2006-01-02"),
+		HireDate:     current.HireDate.Format("2006-01-02"),
+		Salary:       float64(current.Salary),
+		Attributes:   currentAttributes,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+
+	var input models.EmployeeCreateRequest
+	if err := applyMergePatch(base, patch, &input, "middle_name", "attributes"); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	// Дополнительные поля перезаписываются и проверяются, только если патч их затрагивает.
+	var changes map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &changes); err == nil {
+		if raw, ok := changes["attributes"]; !ok {
+			input.Attributes = nil
+		} else if string(raw) == "null" {
+			input.Attributes = map[string]interface{}{}
+		}
+	}
+
+	// Патч строился от прочитанной версии: её и ожидаем при записи.
+	updateEmployee(c, id, input, employee.Version)
+}
+
+// updateEmployee записывает карточку сотрудника input; expected — ожидаемая версия (0 — любая).
+func updateEmployee(c *gin.Context, id uint, input models.EmployeeCreateRequest, expected uint) {
+	if !services.ScopeFor(c, services.PermEmployeesReadAll).AllowsDepartment(input.DepartmentID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	current, err := services.CurrentEmployment(db.DB, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if !services.HasPermission(c, services.PermEmployeesSalaryRead) && !keepSensitiveFields(&input, current) {
+		respondSensitiveForbidden(c)
+		return
+	}
+	birthDate, hireDate, attributes, err := services.ValidateEmployee(db.DB, input, &current)
+	if err != nil {
+		if !respondValidation(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		}
+		return
+	}
+	trimEmployeeNames(&input)
+
+	var version uint
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var err error
+		if version, err = services.BumpEmployeeVersion(tx, id, expected); err != nil {
+			return err
+		}
+
+		before, err := loadEmployeeSnapshot(tx, id)
+		if err != nil {
+			return err
+		}
 
-	err = db.DB.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Model(&models.Employee{}).
 			Where("id = ?", id).
-			Updates(models.Employee{
-				LastName:   input.LastName,
-				FirstName:  input.FirstName,
-				MiddleName: input.MiddleName,
+			Updates(map[string]interface{}{
+				"last_name":   input.LastName,
+				"first_name":  input.FirstName,
+				"middle_name": input.MiddleName,
 			}).Error; err != nil {
 			return err
 		}
 
-		return tx.Model(&models.EmployeeHR{}).
+		current, err := services.CurrentEmployment(tx, id)
+		if err != nil {
+			return err
+		}
+
+		// Дата рождения относится к сотруднику, дата приёма — ко всем записям
+		// текущего периода работы; первый приём начинается с даты приёма.
+		if err := tx.Model(&models.EmployeeHR{}).
 			Where("employee_id = ?", id).
-			Updates(models.EmployeeHR{
-				DepartmentID: input.DepartmentID,
-				PositionID:   input.PositionID,
-				IsRemote:     input.IsRemote,
-				BirthDate:    birthDate,
-				HireDate:     hireDate,
-				Salary:       input.Salary,
-			}).Error
+			Update("birth_date", models.EncryptedDate{Time: birthDate}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.EmployeeHR{}).
+			Where("employee_id = ? AND hire_date = ?", id, current.HireDate).
+			Update("hire_date", hireDate).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.EmployeeHR{}).
+			Where("employee_id = ? AND change_type = ? AND hire_date = ? AND (valid_to IS NULL OR valid_to > ?)",
+				id, services.EmploymentHire, hireDate, hireDate).
+			Update("valid_from", hireDate).Error; err != nil {
+			return err
+		}
+
+		if current, err = services.CurrentEmployment(tx, id); err != nil {
+			return err
+		}
+		changeType := employmentChangeType(current, input)
+		if changeType != "" {
+			// Изменения из карточки вступают в силу сегодня (или с начала ещё не наступившей записи).
+			effective := time.Now()
+			if current.ValidFrom.After(effective) {
+				effective = current.ValidFrom
+			}
+			if _, _, err := services.ChangeEmployment(tx, id, changeType, effective, "", func(hr *models.EmployeeHR) {
+				hr.DepartmentID = input.DepartmentID
+				hr.PositionID = input.PositionID
+				hr.IsRemote = input.IsRemote
+				hr.Salary = models.EncryptedFloat(input.Salary)
+			}); err != nil {
+				return err
+			}
+		}
+		if attributes != nil {
+			if err := services.SaveEmployeeAttributes(tx, id, attributes, true); err != nil {
+				return err
+			}
+		}
+
+		after, err := loadEmployeeSnapshot(tx, id)
+		if err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditUpdate, "employee", id, before, after)
 	})
 
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
+	if errors.Is(err, services.ErrVersionConflict) {
+		respondVersionConflict(c)
+		return
+	}
+	if respondValidation(c, err) {
+		return
+	}
+	if errors.Is(err, services.ErrEmployeeTerminated) {
+		c.JSON(http.StatusConflict, gin.H{"error": "employee_terminated", "message": "Сотрудник уволен: кадровые данные можно изменить только при повторном приёме"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
 		return
 	}
 
+	c.Header("ETag", services.EmployeeETag(version))
 	c.JSON(http.StatusOK, gin.H{"message": "Данные обновлены"})
 }
 
+// keepSensitiveFields подставляет текущие оклад и дату рождения для того, кто их
+// не видит: он получает их скрытыми и не может передать обратно. Возвращает false,
+// если запрос всё же меняет эти поля.
+func keepSensitiveFields(input *models.EmployeeCreateRequest, current models.EmployeeHR) bool {
+	birthDate := current.BirthDate.Format("2006-01-02")
+	if (input.Salary != 0 && input.Salary != float64(current.Salary)) ||
+		(input.BirthDate != "" && input.BirthDate != birthDate) {
+		return false
+	}
+	input.Salary = float64(current.Salary)
+	input.BirthDate = birthDate
+	return true
+}
+
+func respondSensitiveForbidden(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{"error": "access denied", "message": "Нет права изменять оклад и дату рождения"})
+}
+
+func trimEmployeeNames(input *models.EmployeeCreateRequest) {
+	input.LastName = strings.TrimSpace(input.LastName)
+	input.FirstName = strings.TrimSpace(input.FirstName)
+	input.MiddleName = strings.TrimSpace(input.MiddleName)
+}
+
+// employmentChangeType определяет тип изменения кадровых данных из карточки;
+// пустая строка — кадровые данные не менялись.
+func employmentChangeType(current models.EmployeeHR, input models.EmployeeCreateRequest) string {
+	switch {
+	case current.DepartmentID != input.DepartmentID:
+		return services.EmploymentTransfer
+	case current.PositionID != input.PositionID:
+		return services.EmploymentPromotion
+	case float64(current.Salary) != input.Salary:
+		return services.EmploymentSalary
+	case current.IsRemote != input.IsRemote:
+		return services.EmploymentUpdate
+	}
+	return ""
+}
+
 // DeleteEmployee godoc
 // @Summary Удалить сотрудника
-// @Description Помечает сотрудника и его кадровые данные как удалённые (soft delete)
+// @Description Помечает сотрудника, его кадровые и рабочие данные как удалённые (soft delete); их можно восстановить
+// @Description через /api/trash. Удалённые сотрудники пропадают и из исторической аналитики, поэтому для увольнения
+// @Description используйте /api/employees/{id}/terminate.
 // @Tags employees
 // @Security BearerAuth
 // @Produce json
@@ -284,15 +867,38 @@ func UpdateEmployee(c *gin.Context) {
 // @Failure 500 {object} map[string]string
 // @Router /api/employees/{id} [delete]
 func DeleteEmployee(c *gin.Context) {
-	id := c.Param("id")
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return
+	}
 
-	err := db.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Delete(&models.EmployeeHR{}, "employee_id = ?", id).Error; err != nil {
+	allowed, err := services.ScopeFor(c, services.PermEmployeesReadAll).AllowsWrite(db.DB, uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		before, err := loadEmployeeSnapshot(tx, uint(id))
+		if err != nil {
+			return err
+		}
+
+		if err := services.DeleteEmployee(tx, uint(id)); err != nil {
 			return err
 		}
-		return tx.Delete(&models.Employee{}, id).Error
+		return services.Audit(tx, c, models.AuditDelete, "employee", id, before, nil)
 	})
 
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
 		return
@@ -300,3 +906,48 @@ func DeleteEmployee(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Сотрудник удалён"})
 }
+
+// employeeSnapshot — состояние сотрудника для журнала аудита. Оклад и дата рождения
+// в журнал не попадают: они хранятся зашифрованными и видны только с employees.salary.read,
+// а изменения оклада остаются в истории кадровых записей.
+type employeeSnapshot struct {
+	LastName     string     `json:"last_name"`
+	FirstName    string     `json:"first_name"`
+	MiddleName   string     `json:"middle_name"`
+	DepartmentID uint       `json:"department_id"`
+	PositionID   uint       `json:"position_id"`
+	ManagerID    *uint      `json:"manager_id"`
+	IsRemote     bool       `json:"is_remote"`
+	HireDate     time.Time  `json:"hire_date"`
+	FireDate     *time.Time `json:"fire_date"`
+
+	TerminationReasonID *uint `json:"termination_reason_id"`
+
+	Attributes map[string]interface{} `json:"attributes" gorm:"-"`
+}
+
+func loadEmployeeSnapshot(tx *gorm.DB, employeeID uint) (*employeeSnapshot, error) {
+	var snapshot employeeSnapshot
+	res := tx.Table("employees e").
+		Select(`
+		e.last_name, e.first_name, e.middle_name,
+		ehr.department_id, ehr.position_id, ehr.manager_id, ehr.is_remote,
+		ehr.hire_date, ehr.fire_date, ehr.termination_reason_id
+	`).
+		Joins("LEFT JOIN employee_hrs ehr ON ehr.employee_id = e.id AND ehr.deleted_at IS NULL AND ehr.valid_to IS NULL").
+		Where("e.id = ? AND e.deleted_at IS NULL", employeeID).
+		Limit(1).
+		Scan(&snapshot)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	attributes, err := services.EmployeeAttributes(tx, []uint{employeeID})
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Attributes = attributes[employeeID]
+	return &snapshot, nil
+}
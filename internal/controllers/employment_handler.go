@@ -0,0 +1,375 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetEmploymentHistory godoc
+// @Summary История работы сотрудника
+// @Description Кадровые записи сотрудника от новых к старым: приём, переводы, повышения, изменения оклада.
+// @Description Запись действует с valid_from по valid_to (не включительно); у текущей valid_to = null.
+// @Tags employees
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID сотрудника"
+// @Success 200 {array} models.EmploymentRecordResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/employees/{id}/history [get]
+func GetEmploymentHistory(c *gin.Context) {
+	employeeID, ok := employeeParam(c)
+	if !ok {
+		return
+	}
+
+	records, err := services.EmploymentHistory(db.DB, employeeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if len(records) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
+
+	sensitive := services.HasPermission(c, services.PermEmployeesSalaryRead)
+	result := make([]models.EmploymentRecordResponse, 0, len(records))
+	for _, r := range records {
+		item := models.EmploymentRecordResponse{
+			ID:           r.ID,
+			ChangeType:   r.ChangeType,
+			ValidFrom:    r.ValidFrom,
+			ValidTo:      r.ValidTo,
+			DepartmentID: r.DepartmentID,
+			Department:   r.Department.Name,
+			PositionID:   r.PositionID,
+			Position:     r.Position.Name,
+			ManagerID:    r.ManagerID,
+			IsRemote:     r.IsRemote,
+			HireDate:     r.HireDate,
+			FireDate:     r.FireDate,
+			Comment:      r.Comment,
+			CreatedAt:    r.CreatedAt,
+		}
+		if sensitive {
+			salary := float64(r.Salary)
+			item.Salary = &salary
+		}
+		if r.TerminationReason != nil {
+			item.TerminationReason = r.TerminationReason.Name
+		}
+		result = append(result, item)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// TransferEmployee godoc
+// @Summary Перевод сотрудника в другой отдел
+// @Description Закрывает текущую кадровую запись датой перевода и открывает новую. Можно одновременно
+// @Description сменить должность. Дата по умолчанию — сегодня; будущие даты не принимаются.
+// @Tags employees
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID сотрудника"
+// @Param If-Match header string false "ETag карточки сотрудника"
+// @Param data body models.EmployeeTransferRequest true "Новый отдел и дата перевода"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Router /api/employees/{id}/transfer [post]
+func TransferEmployee(c *gin.Context) {
+	var input models.EmployeeTransferRequest
+	if err := bindJSON(c, &input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if !services.ScopeFor(c, services.PermEmployeesReadAll).AllowsDepartment(input.DepartmentID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	changeEmployment(c, models.AuditUpdate, input.EffectiveDate, input.Comment, employmentChange(services.EmploymentTransfer, func(hr *models.EmployeeHR) {
+		hr.DepartmentID = input.DepartmentID
+		if input.PositionID != 0 {
+			hr.PositionID = input.PositionID
+		}
+	}))
+}
+
+// PromoteEmployee godoc
+// @Summary Назначение на другую должность
+// @Description Закрывает текущую кадровую запись и открывает новую с другой должностью
+// @Description (и окладом, если он указан). Дата по умолчанию — сегодня.
+// @Tags employees
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID сотрудника"
+// @Param If-Match header string false "ETag карточки сотрудника"
+// @Param data body models.EmployeePromotionRequest true "Новая должность и дата назначения"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Router /api/employees/{id}/promotion [post]
+func PromoteEmployee(c *gin.Context) {
+	var input models.EmployeePromotionRequest
+	if err := bindJSON(c, &input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	changeEmployment(c, models.AuditUpdate, input.EffectiveDate, input.Comment, employmentChange(services.EmploymentPromotion, func(hr *models.EmployeeHR) {
+		hr.PositionID = input.PositionID
+		if input.Salary != nil {
+			hr.Salary = models.EncryptedFloat(*input.Salary)
+		}
+	}))
+}
+
+// ChangeEmployeeSalary godoc
+// @Summary Изменение оклада
+// @Description Закрывает текущую кадровую запись и открывает новую с другим окладом. Дата по умолчанию — сегодня.
+// @Tags employees
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID сотрудника"
+// @Param If-Match header string false "ETag карточки сотрудника"
+// @Param data body models.EmployeeSalaryChangeRequest true "Новый оклад и дата изменения"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Router /api/employees/{id}/salary [post]
+func ChangeEmployeeSalary(c *gin.Context) {
+	var input models.EmployeeSalaryChangeRequest
+	if err := bindJSON(c, &input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	changeEmployment(c, models.AuditUpdate, input.EffectiveDate, input.Comment, employmentChange(services.EmploymentSalary, func(hr *models.EmployeeHR) {
+		hr.Salary = models.EncryptedFloat(*input.Salary)
+	}))
+}
+
+// TerminateEmployee godoc
+// @Summary Увольнение сотрудника
+// @Description Закрывает текущую кадровую запись датой увольнения и открывает запись с fire_date и причиной
+// @Description из справочника termination-reasons. Сотрудник перестаёт учитываться в текущей численности,
+// @Description но остаётся в исторических данных. Связанная учётная запись отключается, её сессии отзываются.
+// @Tags employees
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID сотрудника"
+// @Param If-Match header string false "ETag карточки сотрудника"
+// @Param data body models.EmployeeTerminationRequest true "Дата и причина увольнения"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Router /api/employees/{id}/terminate [post]
+func TerminateEmployee(c *gin.Context) {
+	var input models.EmployeeTerminationRequest
+	if err := bindJSON(c, &input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	var reason models.TerminationReason
+	if err := db.DB.First(&reason, input.TerminationReasonID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondValidation(c, &services.ValidationError{Fields: []services.FieldError{
+				{Field: "termination_reason_id", Code: "not_found", Message: "Причина увольнения не найдена"},
+			}})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	changeEmployment(c, models.AuditTerminate, input.FireDate, input.Comment, func(tx *gorm.DB, employeeID uint, fireDate time.Time, comment string) error {
+		userID, err := services.TerminateEmployment(tx, employeeID, fireDate, reason.ID, comment)
+		if err != nil || userID == 0 {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditUpdate, "user", userID, gin.H{"disabled": false}, gin.H{"disabled": true})
+	})
+}
+
+// RehireEmployee godoc
+// @Summary Повторный приём сотрудника
+// @Description Открывает новую кадровую запись уволенного сотрудника с даты повторного приёма (позже даты увольнения).
+// @Description Учётная запись сотрудника не включается автоматически.
+// @Description Без права employees.salary.read оклад не передаётся и остаётся прежним.
+// @Tags employees
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID сотрудника"
+// @Param If-Match header string false "ETag карточки сотрудника"
+// @Param data body models.EmployeeRehireRequest true "Условия и дата повторного приёма"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Router /api/employees/{id}/rehire [post]
+func RehireEmployee(c *gin.Context) {
+	var input models.EmployeeRehireRequest
+	if err := bindJSON(c, &input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+	if !services.ScopeFor(c, services.PermEmployeesReadAll).AllowsDepartment(input.DepartmentID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+	salaryAllowed := services.HasPermission(c, services.PermEmployeesSalaryRead)
+	if !salaryAllowed && input.Salary != 0 {
+		respondSensitiveForbidden(c)
+		return
+	}
+
+	changeEmployment(c, models.AuditRehire, input.HireDate, input.Comment, func(tx *gorm.DB, employeeID uint, hireDate time.Time, comment string) error {
+		return services.RehireEmployment(tx, employeeID, hireDate, comment, func(hr *models.EmployeeHR) {
+			hr.DepartmentID = input.DepartmentID
+			hr.PositionID = input.PositionID
+			hr.IsRemote = input.IsRemote
+			if salaryAllowed {
+				hr.Salary = models.EncryptedFloat(input.Salary)
+			}
+		})
+	})
+}
+
+// employmentChange — изменение кадровой записи типа changeType.
+func employmentChange(changeType string, apply func(*models.EmployeeHR)) func(*gorm.DB, uint, time.Time, string) error {
+	return func(tx *gorm.DB, employeeID uint, effective time.Time, comment string) error {
+		_, _, err := services.ChangeEmployment(tx, employeeID, changeType, effective, comment, apply)
+		return err
+	}
+}
+
+// changeEmployment проверяет доступ к сотруднику из :id, вносит изменение в его историю
+// с даты rawDate (по умолчанию сегодня) и записывает событие аудита action.
+func changeEmployment(c *gin.Context, action, rawDate, comment string, change func(tx *gorm.DB, employeeID uint, effective time.Time, comment string) error) {
+	employeeID, ok := employeeWriteParam(c)
+	if !ok {
+		return
+	}
+
+	effective := time.Now()
+	if rawDate != "" {
+		var err error
+		if effective, err = time.Parse("2006-01-02", rawDate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format, expected YYYY-MM-DD"})
+			return
+		}
+	}
+	comment = strings.TrimSpace(comment)
+	if r := []rune(comment); len(r) > 500 {
+		comment = string(r[:500])
+	}
+	expected, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	var version uint
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var err error
+		if version, err = services.BumpEmployeeVersion(tx, employeeID, expected); err != nil {
+			return err
+		}
+		before, err := loadEmployeeSnapshot(tx, employeeID)
+		if err != nil {
+			return err
+		}
+		if err := change(tx, employeeID, effective, comment); err != nil {
+			return err
+		}
+		after, err := loadEmployeeSnapshot(tx, employeeID)
+		if err != nil {
+			return err
+		}
+		return services.Audit(tx, c, action, "employee", employeeID, before, after)
+	})
+
+	if respondValidation(c, err) {
+		return
+	}
+	switch {
+	case err == nil:
+		c.Header("ETag", services.EmployeeETag(version))
+		c.JSON(http.StatusOK, gin.H{"message": "Изменение внесено в историю"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+	case errors.Is(err, services.ErrVersionConflict):
+		respondVersionConflict(c)
+	case errors.Is(err, services.ErrEffectiveDateTooEarly):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "effective_date_too_early", "message": "Дата раньше начала текущей кадровой записи или совпадает с датой увольнения"})
+	case errors.Is(err, services.ErrEffectiveDateInFuture):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "effective_date_in_future", "message": "Изменение нельзя внести будущей датой"})
+	case errors.Is(err, services.ErrEmployeeTerminated):
+		c.JSON(http.StatusConflict, gin.H{"error": "employee_terminated", "message": "Сотрудник уволен"})
+	case errors.Is(err, services.ErrManagerInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_manager", "message": "Руководитель не найден или уволен"})
+	case errors.Is(err, services.ErrManagerCycle):
+		c.JSON(http.StatusConflict, gin.H{"error": "manager_cycle", "message": "Руководитель не может подчиняться самому сотруднику"})
+	case errors.Is(err, services.ErrEmployeeNotTerminated):
+		c.JSON(http.StatusConflict, gin.H{"error": "employee_not_terminated", "message": "Сотрудник не уволен"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+	}
+}
+
+// employeeParam разбирает :id и проверяет, что сотрудник в области видимости; при ошибке отвечает сам.
+func employeeParam(c *gin.Context) (uint, bool) {
+	return scopedEmployeeParam(c, services.EmployeeScope.Allows)
+}
+
+// employeeWriteParam — как employeeParam, но для изменения данных сотрудника.
+func employeeWriteParam(c *gin.Context) (uint, bool) {
+	return scopedEmployeeParam(c, services.EmployeeScope.AllowsWrite)
+}
+
+func scopedEmployeeParam(c *gin.Context, check func(services.EmployeeScope, *gorm.DB, uint) (bool, error)) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee id"})
+		return 0, false
+	}
+
+	allowed, err := check(services.ScopeFor(c, services.PermEmployeesReadAll), db.DB, uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return 0, false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return 0, false
+	}
+	return uint(id), true
+}
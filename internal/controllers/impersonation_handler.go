@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxImpersonationReason — предел длины причины имперсонации (размер столбца reason).
+const maxImpersonationReason = 500
+
+// StartImpersonation godoc
+// @Summary Просмотр системы от имени пользователя
+// @Description Выдаёт временный access-токен, с которым все запросы выполняются от имени пользователя:
+// @Description с его группами, отделами и областью видимости данных. Администратор сохраняется в токене,
+// @Description события аудита записываются с actor_id. По умолчанию доступен только просмотр (allow_write = false).
+// @Description Нельзя действовать от имени пользователя, который сам может использовать имперсонацию.
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param payload body models.ImpersonationRequest true "Причина и режим"
+// @Success 200 {object} models.ImpersonationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/users/{id}/impersonate [post]
+func StartImpersonation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req models.ImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Укажите причину"})
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if utf8.RuneCountInString(req.Reason) > maxImpersonationReason {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": fmt.Sprintf("Причина — не больше %d символов", maxImpersonationReason)})
+		return
+	}
+
+	var target models.User
+	if err := db.DB.Preload("AccessGroups.AccessGroup").First(&target, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondUserError(c, errUserNotFound)
+			return
+		}
+		respondUserError(c, err)
+		return
+	}
+
+	var resp *models.ImpersonationResponse
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var err error
+		resp, err = services.StartImpersonation(tx, c.GetUint("user_id"), target, req.Reason, req.AllowWrite)
+		if err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditImpersonationStart, "user", target.ID, nil, gin.H{
+			"session_id":  resp.SessionID,
+			"reason":      req.Reason,
+			"allow_write": req.AllowWrite,
+		})
+	})
+	if errors.Is(err, services.ErrImpersonationForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "impersonation_forbidden", "message": "Нельзя действовать от имени этого пользователя"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось начать сессию"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// StopImpersonation godoc
+// @Summary Завершить просмотр от имени пользователя
+// @Description Закрывает сессию имперсонации; её токен сразу перестаёт действовать.
+// @Description Вызывается с токеном имперсонации.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/impersonation/stop [post]
+func StopImpersonation(c *gin.Context) {
+	sessionID := c.GetUint("impersonation_id")
+	if sessionID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "not_impersonating", "message": "Сессия просмотра от имени пользователя не открыта"})
+		return
+	}
+
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := services.StopImpersonation(tx, sessionID); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditImpersonationStop, "user", c.GetUint("user_id"), nil, gin.H{"session_id": sessionID})
+	})
+	if errors.Is(err, services.ErrImpersonationInvalid) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "not_impersonating", "message": "Сессия уже завершена"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось завершить сессию"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Просмотр от имени пользователя завершён"})
+}
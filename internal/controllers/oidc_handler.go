@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	oidcStateCookie = "oidc_state"
+	oidcStateTTL    = 10 * time.Minute
+)
+
+// OIDCLogin godoc
+// @Summary Вход через внешний OIDC-провайдер
+// @Description Перенаправляет на страницу входа провайдера (authorization code flow с PKCE).
+// @Description После входа провайдер возвращает пользователя на /auth/oidc/callback.
+// @Tags auth
+// @Success 302
+// @Failure 404 {object} map[string]string
+// @Router /auth/oidc/login [get]
+func OIDCLogin(c *gin.Context) {
+	provider, err := services.OIDC()
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "oidc_disabled", "message": "Вход через внешний провайдер не настроен"})
+		return
+	}
+
+	state, err1 := services.RandomToken(24)
+	nonce, err2 := services.RandomToken(24)
+	verifier, err3 := services.RandomToken(32)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось начать вход"})
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Println("oidc:", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "oidc_unavailable", "message": "Провайдер входа недоступен"})
+		return
+	}
+
+	// state, nonce и code_verifier хранятся в подписанной HttpOnly cookie,
+	// а не в URL, чтобы перехват redirect не давал всех данных для обмена кода.
+	cookie, err := services.SignToken(jwt.MapClaims{
+		"typ":      services.TokenTypeOIDCState,
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      time.Now().Add(oidcStateTTL).Unix(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось начать вход"})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, cookie, int(oidcStateTTL.Seconds()), "/auth/oidc", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback godoc
+// @Summary Возврат от OIDC-провайдера
+// @Description Обменивает код авторизации на ID-токен, находит (или создаёт) пользователя
+// @Description и перенаправляет на страницу фронтенда, передавая токены во фрагменте URL
+// @Description (#token=...&refresh_token=...&expires_in=...) или код ошибки (#error=...).
+// @Description Если пользователю нужна 2FA и провайдер не подтвердил многофакторный вход
+// @Description (OIDC_TRUSTED_MFA), вместо токенов передаётся шаг входа
+// @Description (#step=...&challenge_token=...&expires_in=...), как в ответе /auth/login.
+// @Tags auth
+// @Param code query string false "Код авторизации"
+// @Param state query string true "Значение state"
+// @Success 302
+// @Router /auth/oidc/callback [get]
+func OIDCCallback(c *gin.Context) {
+	provider, err := services.OIDC()
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "oidc_disabled", "message": "Вход через внешний провайдер не настроен"})
+		return
+	}
+	redirect := provider.Config().FrontendRedirectURL
+
+	raw, _ := c.Cookie(oidcStateCookie)
+	c.SetCookie(oidcStateCookie, "", -1, "/auth/oidc", "", c.Request.TLS != nil, true)
+
+	claims, err := services.ParseToken(raw, services.TokenTypeOIDCState)
+	if err != nil || claims["state"] != c.Query("state") || c.Query("state") == "" {
+		redirectWithFragment(c, redirect, url.Values{"error": {"oidc_state_invalid"}})
+		return
+	}
+	if idpErr := c.Query("error"); idpErr != "" {
+		log.Println("oidc: provider returned error:", idpErr, c.Query("error_description"))
+		redirectWithFragment(c, redirect, url.Values{"error": {"oidc_failed"}})
+		return
+	}
+
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+	identity, err := provider.Exchange(c.Request.Context(), c.Query("code"), verifier, nonce)
+	if err != nil {
+		log.Println("oidc:", err)
+		redirectWithFragment(c, redirect, url.Values{"error": {"oidc_failed"}})
+		return
+	}
+
+	var (
+		tokens *models.TokenResponse
+		step   string
+		user   models.User
+	)
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var err error
+		if user, err = services.ResolveOIDCUser(tx, provider, identity); err != nil {
+			return err
+		}
+		// Второй фактор запрашивается так же, как при входе по паролю,
+		// если провайдер сам не подтвердил многофакторный вход.
+		if step = services.SecondFactorStep(user); step != "" && !provider.MFAVerified(identity) {
+			return nil
+		}
+		step = ""
+		tokens, err = services.StartSession(tx, user, c.Request.UserAgent(), c.ClientIP())
+		return err
+	})
+
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrOIDCUserNotFound):
+		redirectWithFragment(c, redirect, url.Values{"error": {"oidc_user_not_found"}})
+		return
+	case errors.Is(err, services.ErrOIDCUserDisabled):
+		redirectWithFragment(c, redirect, url.Values{"error": {"oidc_user_disabled"}})
+		return
+	case errors.Is(err, services.ErrLoginTaken):
+		redirectWithFragment(c, redirect, url.Values{"error": {"oidc_login_taken"}})
+		return
+	default:
+		log.Println("oidc:", err)
+		redirectWithFragment(c, redirect, url.Values{"error": {"oidc_failed"}})
+		return
+	}
+
+	if step != "" {
+		challenge, err := services.GenerateChallengeToken(user, step)
+		if err != nil {
+			log.Println("oidc:", err)
+			redirectWithFragment(c, redirect, url.Values{"error": {"oidc_failed"}})
+			return
+		}
+		redirectWithFragment(c, redirect, url.Values{
+			"step":            {step},
+			"challenge_token": {challenge},
+			"expires_in":      {strconv.Itoa(int(services.ChallengeTokenTTL.Seconds()))},
+		})
+		return
+	}
+
+	redirectWithFragment(c, redirect, url.Values{
+		"token":         {tokens.Token},
+		"refresh_token": {tokens.RefreshToken},
+		"expires_in":    {strconv.Itoa(tokens.ExpiresIn)},
+	})
+}
+
+// redirectWithFragment передаёт данные во фрагменте URL: он не отправляется
+// на сервер и не попадает в логи и заголовок Referer.
+func redirectWithFragment(c *gin.Context, target string, values url.Values) {
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, target+"#"+values.Encode())
+}
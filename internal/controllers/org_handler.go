@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SetEmployeeManager godoc
+// @Summary Назначить линейного руководителя
+// @Description Записывает руководителя в историю сотрудника с указанной даты (по умолчанию сегодня).
+// @Description Руководитель должен работать и не может подчиняться самому сотруднику; manager_id = null снимает руководителя.
+// @Tags employees
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID сотрудника"
+// @Param If-Match header string false "ETag карточки сотрудника"
+// @Param data body models.EmployeeManagerRequest true "Руководитель и дата назначения"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Router /api/employees/{id}/manager [post]
+func SetEmployeeManager(c *gin.Context) {
+	var input models.EmployeeManagerRequest
+	if err := bindJSON(c, &input); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if input.ManagerID != nil {
+		allowed, err := services.ScopeFor(c, services.PermEmployeesReadAll).Allows(db.DB, *input.ManagerID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+	}
+
+	changeEmployment(c, models.AuditUpdate, input.EffectiveDate, input.Comment, func(tx *gorm.DB, employeeID uint, effective time.Time, comment string) error {
+		if input.ManagerID != nil {
+			if err := services.ValidateManager(tx, employeeID, *input.ManagerID); err != nil {
+				return err
+			}
+		}
+		_, _, err := services.ChangeEmployment(tx, employeeID, services.EmploymentManager, effective, comment, func(hr *models.EmployeeHR) {
+			hr.ManagerID = input.ManagerID
+		})
+		return err
+	})
+}
+
+// GetDirectReports godoc
+// @Summary Прямые подчинённые
+// @Description Работающие сотрудники, у которых указанный сотрудник — линейный руководитель.
+// @Tags employees
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID руководителя"
+// @Success 200 {array} models.OrgEmployee
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/employees/{id}/reports [get]
+func GetDirectReports(c *gin.Context) {
+	managerID, ok := employeeParam(c)
+	if !ok {
+		return
+	}
+
+	items, err := orgEmployees(c, func(q *gorm.DB) *gorm.DB {
+		return q.Where("employee_hrs.manager_id = ?", managerID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetEmployeeSubtree godoc
+// @Summary Все подчинённые
+// @Description Прямые и косвенные подчинённые сотрудника; depth — уровень подчинения (1 — прямой подчинённый).
+// @Tags employees
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID руководителя"
+// @Success 200 {array} models.OrgEmployee
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/employees/{id}/subtree [get]
+func GetEmployeeSubtree(c *gin.Context) {
+	managerID, ok := employeeParam(c)
+	if !ok {
+		return
+	}
+
+	depths, err := services.Subtree(db.DB, managerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if len(depths) == 0 {
+		c.JSON(http.StatusOK, []models.OrgEmployee{})
+		return
+	}
+
+	ids := make([]uint, 0, len(depths))
+	for id := range depths {
+		ids = append(ids, id)
+	}
+	items, err := orgEmployees(c, func(q *gorm.DB) *gorm.DB {
+		return q.Where("employee_hrs.employee_id IN ?", ids)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	for i := range items {
+		items[i].Depth = depths[items[i].ID]
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Depth < items[j].Depth })
+	c.JSON(http.StatusOK, items)
+}
+
+// GetOrgChart godoc
+// @Summary Оргструктура
+// @Description Дерево подчинения работающих сотрудников в области видимости пользователя. Корни — сотрудники
+// @Description без руководителя или с руководителем вне области видимости. С root_id возвращается поддерево сотрудника.
+// @Tags employees
+// @Security BearerAuth
+// @Produce json
+// @Param root_id query int false "ID сотрудника — корня поддерева"
+// @Success 200 {array} models.OrgChartNode
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/employees/org-chart [get]
+func GetOrgChart(c *gin.Context) {
+	var rootID uint
+	if v := c.Query("root_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid root_id"})
+			return
+		}
+		rootID = uint(id)
+	}
+
+	items, err := orgEmployees(c, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	byID := make(map[uint]bool, len(items))
+	children := make(map[uint][]models.OrgEmployee)
+	for _, e := range items {
+		byID[e.ID] = true
+	}
+	var roots []models.OrgEmployee
+	for _, e := range items {
+		switch {
+		case rootID != 0:
+			if e.ID == rootID {
+				roots = append(roots, e)
+			}
+		case e.ManagerID == nil || !byID[*e.ManagerID]:
+			roots = append(roots, e)
+		}
+		if e.ManagerID != nil {
+			children[*e.ManagerID] = append(children[*e.ManagerID], e)
+		}
+	}
+	if rootID != 0 && len(roots) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
+
+	visited := make(map[uint]bool, len(items))
+	var build func(e models.OrgEmployee) *models.OrgChartNode
+	build = func(e models.OrgEmployee) *models.OrgChartNode {
+		visited[e.ID] = true
+		node := &models.OrgChartNode{OrgEmployee: e, Reports: []*models.OrgChartNode{}}
+		for _, child := range children[e.ID] {
+			if !visited[child.ID] {
+				node.Reports = append(node.Reports, build(child))
+			}
+		}
+		return node
+	}
+
+	chart := make([]*models.OrgChartNode, 0, len(roots))
+	for _, e := range roots {
+		chart = append(chart, build(e))
+	}
+	c.JSON(http.StatusOK, chart)
+}
+
+// orgEmployees выбирает работающих сотрудников в области видимости пользователя.
+func orgEmployees(c *gin.Context, filter func(*gorm.DB) *gorm.DB) ([]models.OrgEmployee, error) {
+	query := db.DB.Table("employee_hrs").
+		Select(`employees.id, employees.last_name, employees.first_name,
+			COALESCE(employees.middle_name, '') AS middle_name,
+			COALESCE(departments.name, '') AS department, COALESCE(positions.name, '') AS position,
+			employee_hrs.manager_id`).
+		Joins("JOIN employees ON employees.id = employee_hrs.employee_id AND employees.deleted_at IS NULL").
+		Joins("LEFT JOIN departments ON departments.id = employee_hrs.department_id").
+		Joins("LEFT JOIN positions ON positions.id = employee_hrs.position_id").
+		Where("employee_hrs.deleted_at IS NULL AND employee_hrs.valid_to IS NULL AND employee_hrs.fire_date IS NULL")
+	query = services.ScopeFor(c, services.PermEmployeesReadAll).Apply(query, "employee_hrs.employee_id")
+	if filter != nil {
+		query = filter(query)
+	}
+
+	items := []models.OrgEmployee{}
+	err := query.Order("employees.last_name, employees.first_name, employees.id").Scan(&items).Error
+	return items, err
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// StartUserPasswordReset godoc
+// @Summary Сбросить пароль пользователя по email
+// @Description Отправляет пользователю письмо с одноразовой ссылкой для установки нового пароля.
+// @Description Текущий пароль перестаёт действовать и сессии пользователя завершаются: войти можно
+// @Description только после установки нового пароля по ссылке.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} models.PasswordResetResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /api/users/{id}/password-reset [post]
+func StartUserPasswordReset(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var resp models.PasswordResetResponse
+	var msg services.MailMessage
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Where("deleted_at IS NULL").First(&user, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errUserNotFound
+			}
+			return err
+		}
+
+		var expiresAt time.Time
+		var err error
+		if msg, expiresAt, err = services.StartPasswordReset(tx, user, c.GetUint("user_id")); err != nil {
+			return err
+		}
+		resp = models.PasswordResetResponse{Message: "Письмо для сброса пароля отправлено", ExpiresAt: expiresAt}
+
+		return services.Audit(tx, c, models.AuditUpdate, "user", user.ID, nil, gin.H{"password_reset": true})
+	})
+
+	// Письмо отправляется после фиксации: сброс уже действует, при ошибке
+	// отправки администратор повторяет сброс, и прежняя ссылка аннулируется.
+	if err == nil {
+		if err := services.SendMail(msg); err != nil {
+			log.Println("password reset mail:", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "mail_failed", "message": "Пароль сброшен, но письмо не отправлено, повторите сброс"})
+			return
+		}
+	}
+
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, resp)
+	case errors.Is(err, services.ErrNoEmail):
+		c.JSON(http.StatusConflict, gin.H{"error": "no_email", "message": "Логин пользователя не является email, задайте временный пароль вручную"})
+	default:
+		respondUserError(c, err)
+	}
+}
+
+// ResetPassword godoc
+// @Summary Установить пароль по ссылке из письма
+// @Description Принимает одноразовый токен сброса и новый пароль, который должен соответствовать политике паролей.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body models.PasswordResetRequest true "Токен и новый пароль"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /auth/password-reset [post]
+func ResetPassword(c *gin.Context) {
+	var req models.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Неверный формат запроса"})
+		return
+	}
+
+	var user models.User
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var err error
+		if user, err = services.ConsumePasswordResetToken(tx, req.Token); err != nil {
+			return err
+		}
+		if err := services.SetPassword(tx, user, req.Password); err != nil {
+			return err
+		}
+		if err := services.RevokeUserTokens(tx, user.ID); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditUpdate, "user", user.ID, nil, gin.H{"password_changed": true})
+	})
+
+	if err != nil {
+		if errors.Is(err, services.ErrResetTokenInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_reset_token", "message": "Ссылка для сброса пароля недействительна или устарела"})
+			return
+		}
+		if !respondPasswordError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось сменить пароль"})
+		}
+		return
+	}
+
+	// Блокировка входа после неудачных попыток больше не актуальна.
+	_ = services.UnlockLogin(user.Login)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Пароль изменён, войдите с новым паролем"})
+}
+
+// respondPasswordError отвечает на нарушение политики паролей.
+// Возвращает false, если err к политике не относится.
+func respondPasswordError(c *gin.Context, err error) bool {
+	var policyErr *services.PasswordPolicyError
+	switch {
+	case errors.As(err, &policyErr):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "password_policy",
+			"message":    policyErr.Violations[0].Message,
+			"violations": policyErr.Violations,
+		})
+	case errors.Is(err, services.ErrPasswordReused):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "password_reused", "message": "Пароль совпадает с одним из последних использованных"})
+	default:
+		return false
+	}
+	return true
+}
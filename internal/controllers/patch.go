@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+var errPatchNotObject = errors.New("merge patch must be a JSON object")
+
+// applyMergePatch применяет JSON Merge Patch (RFC 7396) patch к документу base и
+// разбирает результат в obj с проверкой тегов binding. Поля патча, которых нет в base,
+// возвращаются как ошибки полей unknown; null допускается только для полей nullable,
+// иначе удалённое поле молча стало бы нулевым значением (ошибка not_nullable).
+func applyMergePatch(base, patch []byte, obj interface{}, nullable ...string) error {
+	var doc, changes map[string]interface{}
+	if err := json.Unmarshal(base, &doc); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return err
+	}
+	if changes == nil {
+		return errPatchNotObject
+	}
+
+	v := &services.ValidationError{}
+	for field, value := range changes {
+		if _, ok := doc[field]; !ok {
+			v.Add(field, "unknown", "Неизвестное поле")
+		} else if value == nil && !slices.Contains(nullable, field) {
+			v.Add(field, "not_nullable", "Поле нельзя очистить: передайте значение")
+		}
+	}
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(mergePatch(doc, changes))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(merged, obj); err != nil {
+		return bindError(obj, err)
+	}
+	return bindError(obj, binding.Validator.ValidateStruct(obj))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	doc, ok := target.(map[string]interface{})
+	if !ok {
+		doc = map[string]interface{}{}
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(doc, key)
+		} else {
+			doc[key] = mergePatch(doc[key], value)
+		}
+	}
+	return doc
+}
+
+// ifMatchVersion разбирает If-Match; 0 — заголовка нет. При ошибке отвечает сам.
+func ifMatchVersion(c *gin.Context) (uint, bool) {
+	version, err := services.ParseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_if_match", "message": "Неверный заголовок If-Match"})
+		return 0, false
+	}
+	return version, true
+}
+
+func respondVersionConflict(c *gin.Context) {
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error":   "version_conflict",
+		"message": "Карточку сотрудника уже изменили: обновите данные и повторите изменение",
+	})
+}
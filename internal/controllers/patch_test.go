@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+)
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		name   string
+		target string
+		patch  string
+		want   string
+	}{
+		{"replace", `{"a":1,"b":2}`, `{"a":3}`, `{"a":3,"b":2}`},
+		{"add", `{"a":1}`, `{"b":2}`, `{"a":1,"b":2}`},
+		{"null deletes", `{"a":1,"b":2}`, `{"a":null}`, `{"b":2}`},
+		{"false kept", `{"a":true}`, `{"a":false}`, `{"a":false}`},
+		{"zero kept", `{"a":5}`, `{"a":0}`, `{"a":0}`},
+		{"empty string kept", `{"a":"x"}`, `{"a":""}`, `{"a":""}`},
+		{"nested merge", `{"a":{"x":1,"y":2}}`, `{"a":{"y":null,"z":3}}`, `{"a":{"x":1,"z":3}}`},
+		{"object over scalar", `{"a":1}`, `{"a":{"x":1}}`, `{"a":{"x":1}}`},
+		{"array replaced", `{"a":[1,2]}`, `{"a":[3]}`, `{"a":[3]}`},
+		{"non-object patch", `{"a":1}`, `[1]`, `[1]`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var target, patch, want interface{}
+			mustUnmarshal(t, tc.target, &target)
+			mustUnmarshal(t, tc.patch, &patch)
+			mustUnmarshal(t, tc.want, &want)
+			if got := mergePatch(target, patch); !reflect.DeepEqual(got, want) {
+				t.Errorf("mergePatch(%s, %s) = %v, want %v", tc.target, tc.patch, got, want)
+			}
+		})
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	base, err := json.Marshal(models.EmployeeCreateRequest{
+		LastName:     "Иванов",
+		FirstName:    "Иван",
+		MiddleName:   "Иванович",
+		DepartmentID: 1,
+		PositionID:   2,
+		IsRemote:     true,
+		HireDate:     "2020-01-15",
+		BirthDate:    "1990-05-01",
+		Salary:       100000,
+		Attributes:   map[string]interface{}{"grade": "senior"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nullable := []string{"middle_name", "attributes"}
+
+	cases := []struct {
+		name  string
+		patch string
+		check func(models.EmployeeCreateRequest) bool
+		field string // ожидаемая ошибка поля
+		code  string
+	}{
+		{name: "empty patch", patch: `{}`, check: func(r models.EmployeeCreateRequest) bool {
+			return r.LastName == "Иванов" && r.IsRemote && r.Salary == 100000 && r.DepartmentID == 1
+		}},
+		{name: "false", patch: `{"is_remote":false}`, check: func(r models.EmployeeCreateRequest) bool {
+			return !r.IsRemote && r.Salary == 100000
+		}},
+		{name: "zero salary", patch: `{"salary":0}`, check: func(r models.EmployeeCreateRequest) bool {
+			return r.Salary == 0 && r.IsRemote
+		}},
+		{name: "null middle name", patch: `{"middle_name":null}`, check: func(r models.EmployeeCreateRequest) bool {
+			return r.MiddleName == "" && r.FirstName == "Иван"
+		}},
+		{name: "null attributes", patch: `{"attributes":null}`, check: func(r models.EmployeeCreateRequest) bool {
+			return len(r.Attributes) == 0
+		}},
+		{name: "null attribute value", patch: `{"attributes":{"grade":null,"level":3}}`, check: func(r models.EmployeeCreateRequest) bool {
+			_, ok := r.Attributes["grade"]
+			return !ok && r.Attributes["level"] == float64(3)
+		}},
+		{name: "null salary", patch: `{"salary":null}`, field: "salary", code: "not_nullable"},
+		{name: "null is_remote", patch: `{"is_remote":null}`, field: "is_remote", code: "not_nullable"},
+		{name: "null required field", patch: `{"last_name":null}`, field: "last_name", code: "not_nullable"},
+		{name: "empty required field", patch: `{"first_name":""}`, field: "first_name", code: "required"},
+		{name: "unknown field", patch: `{"version":3}`, field: "version", code: "unknown"},
+		{name: "wrong type", patch: `{"department_id":"sales"}`, field: "department_id", code: "invalid_type"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var input models.EmployeeCreateRequest
+			err := applyMergePatch(base, []byte(tc.patch), &input, nullable...)
+			if tc.code == "" {
+				if err != nil {
+					t.Fatalf("applyMergePatch(%s): %v", tc.patch, err)
+				}
+				if !tc.check(input) {
+					t.Errorf("applyMergePatch(%s) = %+v", tc.patch, input)
+				}
+				return
+			}
+			var verr *services.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("applyMergePatch(%s) error = %v, want validation error", tc.patch, err)
+			}
+			for _, fe := range verr.Fields {
+				if fe.Field == tc.field && fe.Code == tc.code {
+					return
+				}
+			}
+			t.Errorf("applyMergePatch(%s) fields = %+v, want %s/%s", tc.patch, verr.Fields, tc.field, tc.code)
+		})
+	}
+
+	var input models.EmployeeCreateRequest
+	if err := applyMergePatch(base, []byte(`null`), &input, nullable...); !errors.Is(err, errPatchNotObject) {
+		t.Errorf("null patch: err = %v, want errPatchNotObject", err)
+	}
+}
+
+func mustUnmarshal(t *testing.T, s string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(s), v); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errUnknownPermission = errors.New("unknown permission")
+
+// ListPermissions godoc
+// @Summary Список прав доступа
+// @Description Возвращает все права, которые можно назначить группам доступа
+// @Tags permissions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.PermissionResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/permissions [get]
+func ListPermissions(c *gin.Context) {
+	var items []models.Permission
+	if err := db.DB.Order("code").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	result := make([]models.PermissionResponse, 0, len(items))
+	for _, p := range items {
+		result = append(result, models.PermissionResponse{Code: p.Code, Name: p.Name})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetAccessGroupPermissions godoc
+// @Summary Права группы доступа
+// @Tags permissions
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID группы доступа"
+// @Success 200 {array} models.PermissionResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/dict/access-groups/{id}/permissions [get]
+func GetAccessGroupPermissions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid access group id"})
+		return
+	}
+
+	if err := db.DB.First(&models.AccessGroup{}, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Запись не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	var items []models.Permission
+	if err := db.DB.
+		Joins("JOIN access_group_permissions agp ON agp.permission_id = permissions.id").
+		Where("agp.access_group_id = ?", id).
+		Order("permissions.code").
+		Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	result := make([]models.PermissionResponse, 0, len(items))
+	for _, p := range items {
+		result = append(result, models.PermissionResponse{Code: p.Code, Name: p.Name})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SetAccessGroupPermissions godoc
+// @Summary Задать права группы доступа
+// @Description Полностью заменяет набор прав группы. Изменения применяются без перевыпуска токенов.
+// @Tags permissions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID группы доступа"
+// @Param data body models.AccessGroupPermissionsRequest true "Коды прав"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/dict/access-groups/{id}/permissions [put]
+func SetAccessGroupPermissions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid access group id"})
+		return
+	}
+
+	var input models.AccessGroupPermissionsRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
+		return
+	}
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var group models.AccessGroup
+		if err := tx.First(&group, id).Error; err != nil {
+			return err
+		}
+
+		var perms []models.Permission
+		if len(input.Permissions) > 0 {
+			if err := tx.Where("code IN ?", input.Permissions).Find(&perms).Error; err != nil {
+				return err
+			}
+		}
+		if len(perms) != len(uniqueStrings(input.Permissions)) {
+			return errUnknownPermission
+		}
+
+		var previous []string
+		if err := tx.Table("permissions p").
+			Joins("JOIN access_group_permissions agp ON agp.permission_id = p.id").
+			Where("agp.access_group_id = ?", group.ID).
+			Order("p.code").
+			Pluck("p.code", &previous).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("access_group_id = ?", group.ID).
+			Delete(&models.AccessGroupPermission{}).Error; err != nil {
+			return err
+		}
+		current := make([]string, 0, len(perms))
+		for _, p := range perms {
+			if err := tx.Create(&models.AccessGroupPermission{
+				AccessGroupID: group.ID,
+				PermissionID:  p.ID,
+			}).Error; err != nil {
+				return err
+			}
+			current = append(current, p.Code)
+		}
+		sort.Strings(current)
+
+		return services.Audit(tx, c, models.AuditUpdate, "access_group_permissions", group.ID,
+			gin.H{"permissions": previous}, gin.H{"permissions": current})
+	})
+
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Запись не найдена"})
+		return
+	case errors.Is(err, errUnknownPermission):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown_permission", "message": "Указаны неизвестные права"})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления"})
+		return
+	}
+
+	services.InvalidatePermissions()
+	c.JSON(http.StatusOK, gin.H{"message": "Права группы обновлены"})
+}
+
+func uniqueStrings(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
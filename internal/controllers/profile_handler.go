@@ -2,11 +2,12 @@ package controllers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/MarBalueva/dashboard_efficiency/internal/db"
 	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -62,6 +63,7 @@ func GetProfile(c *gin.Context) {
 // UpdateProfile godoc
 // @Summary Обновить профиль текущего пользователя
 // @Description Позволяет изменить пароль пользователя и/или ФИО сотрудника.
+// @Description Для смены пароля нужно указать текущий пароль (current_password); новый пароль проверяется по политике паролей.
 // @Description
 // @Description Можно передавать только те поля, которые требуется изменить.
 // @Tags profile
@@ -73,6 +75,7 @@ func GetProfile(c *gin.Context) {
 // @Failure 400 {object} map[string]string "Неверные данные"
 // @Failure 401 {object} map[string]string "Неавторизован"
 // @Failure 404 {object} map[string]string "Пользователь или сотрудник не найден"
+// @Failure 429 {object} map[string]string "Слишком много неверных попыток ввода пароля"
 // @Failure 500 {object} map[string]string "Ошибка при обновлении профиля"
 // @Router /api/profile [put]
 func UpdateProfile(c *gin.Context) {
@@ -102,15 +105,24 @@ func UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	if input.Password != "" {
-		hashed, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "password hash error"})
+	passwordChanged := input.Password != ""
+	if passwordChanged {
+		if input.CurrentPassword == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "current_password_required", "message": "Введите текущий пароль"})
+			return
+		}
+		if !checkAttemptsAllowed(c, user) {
+			return
+		}
+		if !services.CheckPassword(input.CurrentPassword, user.Password) {
+			registerFailedAttempt(c, user)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "wrong_password", "message": "Неверный текущий пароль"})
 			return
 		}
-		user.Password = string(hashed)
 	}
 
+	before := profileSnapshot(employee)
+
 	if input.LastName != "" {
 		employee.LastName = input.LastName
 	}
@@ -121,20 +133,89 @@ func UpdateProfile(c *gin.Context) {
 		employee.MiddleName = input.MiddleName
 	}
 
-	err := db.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Save(&user).Error; err != nil {
+	var tokens *models.TokenResponse
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := tx.Model(&employee).Updates(map[string]interface{}{
+			"last_name":   employee.LastName,
+			"first_name":  employee.FirstName,
+			"middle_name": employee.MiddleName,
+			"version":     gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		if err := services.Audit(tx, c, models.AuditUpdate, "employee", employee.ID, before, profileSnapshot(employee)); err != nil {
+			return err
+		}
+		if !passwordChanged {
+			return nil
+		}
+
+		if err := services.SetPassword(tx, user, input.Password); err != nil {
+			return err
+		}
+
+		if err := services.Audit(tx, c, models.AuditUpdate, "user", user.ID, nil, gin.H{"password_changed": true}); err != nil {
+			return err
+		}
+
+		// Смена пароля завершает все сессии, текущей выдаются новые токены.
+		if err := services.RevokeUserTokens(tx, user.ID); err != nil {
 			return err
 		}
-		if err := tx.Save(&employee).Error; err != nil {
+		if err := tx.Preload("AccessGroups.AccessGroup").First(&user, user.ID).Error; err != nil {
 			return err
 		}
-		return nil
+		var err error
+		tokens, err = services.StartSession(tx, user, c.Request.UserAgent(), c.ClientIP())
+		return err
 	})
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления профиля"})
+		if !respondPasswordError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления профиля"})
+		}
+		return
+	}
+
+	if tokens != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Профиль успешно обновлён", "tokens": tokens})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Профиль успешно обновлён"})
 }
+
+// LogoutEverywhere godoc
+// @Summary Выйти на всех устройствах
+// @Description Отзывает все токены текущего пользователя, включая токен этого запроса
+// @Tags profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/profile/logout-all [post]
+func LogoutEverywhere(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := services.RevokeUserTokens(tx, userID); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditDelete, "session", "user:"+strconv.FormatUint(uint64(userID), 10), nil, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось завершить сессии"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Все сессии завершены"})
+}
+
+func profileSnapshot(e models.Employee) gin.H {
+	return gin.H{
+		"last_name":   e.LastName,
+		"first_name":  e.FirstName,
+		"middle_name": e.MiddleName,
+	}
+}
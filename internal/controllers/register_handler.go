@@ -0,0 +1,445 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errRegistrationNotFound   = errors.New("registration not found")
+	errRegistrationProcessed  = errors.New("registration already processed")
+	errRegistrationNoEmployee = errors.New("registration has no employee")
+	errEmployeeNotFound       = errors.New("employee not found")
+	errRegistrationPending    = errors.New("registration pending")
+	errInvalidInvite          = errors.New("invalid invite")
+)
+
+// Register godoc
+// @Summary Регистрация пользователя
+// @Description Создаёт заявку на регистрацию. Учётная запись становится активной после подтверждения администратором.
+// @Description Если передан код приглашения, заявка сразу привязывается к сотруднику из приглашения.
+// @Description Приглашение занимается заявкой; если заявку отклонят, его можно использовать снова.
+// @Description
+// @Description Коды ошибок: invalid_input, email_exists, invalid_invite_code, employee_already_linked
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body models.RegisterRequest true "Данные регистрации"
+// @Success 201 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/register [post]
+func Register(c *gin.Context) {
+	var req models.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Неверный формат запроса"})
+		return
+	}
+
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.Name = strings.TrimSpace(req.Name)
+	req.InviteCode = strings.ToUpper(strings.TrimSpace(req.InviteCode))
+
+	if req.Email == "" || req.Name == "" || req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Заполните все поля"})
+		return
+	}
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Некорректный email"})
+		return
+	}
+	if err := services.ValidatePassword(req.Password, req.Email); err != nil {
+		respondPasswordError(c, err)
+		return
+	}
+
+	hash, err := services.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось обработать пароль"})
+		return
+	}
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		taken, err := services.LoginTaken(tx, req.Email)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return services.ErrLoginTaken
+		}
+
+		var pending int64
+		if err := tx.Model(&models.Registration{}).
+			Where("login = ? AND status = ?", req.Email, models.RegistrationPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return errRegistrationPending
+		}
+
+		registration := models.Registration{
+			Login:    req.Email,
+			Password: hash,
+			FullName: req.Name,
+			Status:   models.RegistrationPending,
+		}
+
+		if req.InviteCode != "" {
+			var invite models.RegistrationInvite
+			if err := tx.Where("code = ? AND used_at IS NULL AND expires_at > ?", req.InviteCode, time.Now()).
+				First(&invite).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errInvalidInvite
+				}
+				return err
+			}
+
+			linked, err := services.EmployeeHasUser(tx, invite.EmployeeID)
+			if err != nil {
+				return err
+			}
+			if linked {
+				return services.ErrEmployeeLinked
+			}
+
+			now := time.Now()
+			invite.UsedAt = &now
+			if err := tx.Save(&invite).Error; err != nil {
+				return err
+			}
+
+			registration.EmployeeID = &invite.EmployeeID
+			registration.InviteID = &invite.ID
+		}
+
+		if err := tx.Create(&registration).Error; err != nil {
+			return err
+		}
+
+		after := gin.H{"login": registration.Login, "full_name": registration.FullName, "employee_id": registration.EmployeeID}
+		return services.Audit(tx, c, models.AuditCreate, "registration", registration.ID, nil, after)
+	})
+
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrLoginTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "email_exists", "message": "Пользователь с таким email уже существует"})
+		return
+	case errors.Is(err, errRegistrationPending):
+		c.JSON(http.StatusConflict, gin.H{"error": "email_exists", "message": "Заявка с таким email уже ожидает подтверждения"})
+		return
+	case errors.Is(err, errInvalidInvite):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_invite_code", "message": "Код приглашения недействителен или истёк"})
+		return
+	case errors.Is(err, services.ErrEmployeeLinked):
+		c.JSON(http.StatusConflict, gin.H{"error": "employee_already_linked", "message": "У сотрудника уже есть учётная запись"})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось создать заявку"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status":  models.RegistrationPending,
+		"message": "Заявка на регистрацию отправлена и ожидает подтверждения администратора",
+	})
+}
+
+// ListRegistrations godoc
+// @Summary Список заявок на регистрацию
+// @Description По умолчанию возвращает заявки в статусе pending. Параметр status=all возвращает все заявки.
+// @Tags registrations
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "pending | approved | rejected | all"
+// @Success 200 {array} models.RegistrationResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/registrations [get]
+func ListRegistrations(c *gin.Context) {
+	status := c.DefaultQuery("status", models.RegistrationPending)
+
+	query := db.DB.Order("created_at")
+	if status != "all" {
+		query = query.Where("status = ?", status)
+	}
+
+	var items []models.Registration
+	if err := query.Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	result := make([]models.RegistrationResponse, 0, len(items))
+	for _, r := range items {
+		result = append(result, models.RegistrationResponse{
+			ID:         r.ID,
+			Login:      r.Login,
+			FullName:   r.FullName,
+			EmployeeID: r.EmployeeID,
+			Status:     r.Status,
+			ReviewedAt: r.ReviewedAt,
+			CreatedAt:  r.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ApproveRegistration godoc
+// @Summary Подтвердить заявку на регистрацию
+// @Description Создаёт пользователя, привязанного к сотруднику, и назначает ему группу доступа employee.
+// @Description Если заявка создана без кода приглашения, employee_id обязателен.
+// @Tags registrations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заявки"
+// @Param data body models.RegistrationApproveRequest false "Сотрудник для привязки"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/registrations/{id}/approve [post]
+func ApproveRegistration(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid registration id"})
+		return
+	}
+
+	var input models.RegistrationApproveRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid data"})
+			return
+		}
+	}
+
+	reviewerID := c.GetUint("user_id")
+
+	var userID uint
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var registration models.Registration
+		if err := tx.First(&registration, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errRegistrationNotFound
+			}
+			return err
+		}
+		if registration.Status != models.RegistrationPending {
+			return errRegistrationProcessed
+		}
+
+		employeeID := input.EmployeeID
+		if employeeID == 0 && registration.EmployeeID != nil {
+			employeeID = *registration.EmployeeID
+		}
+		if employeeID == 0 {
+			return errRegistrationNoEmployee
+		}
+
+		if err := tx.First(&models.Employee{}, employeeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errEmployeeNotFound
+			}
+			return err
+		}
+
+		user, err := services.CreateUser(tx, registration.Login, registration.Password, employeeID, services.DefaultUserGroup)
+		if err != nil {
+			return err
+		}
+		userID = user.ID
+
+		now := time.Now()
+		registration.Status = models.RegistrationApproved
+		registration.EmployeeID = &employeeID
+		registration.ReviewedBy = &reviewerID
+		registration.ReviewedAt = &now
+		if err := tx.Save(&registration).Error; err != nil {
+			return err
+		}
+
+		after := gin.H{"status": registration.Status, "user_id": user.ID, "employee_id": employeeID}
+		return services.Audit(tx, c, models.AuditApprove, "registration", registration.ID,
+			gin.H{"status": models.RegistrationPending}, after)
+	})
+
+	if err != nil {
+		respondRegistrationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Заявка подтверждена", "user_id": userID})
+}
+
+// RejectRegistration godoc
+// @Summary Отклонить заявку на регистрацию
+// @Description Приглашение, по которому подана заявка, снова становится действительным до истечения срока.
+// @Tags registrations
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID заявки"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/registrations/{id}/reject [post]
+func RejectRegistration(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid registration id"})
+		return
+	}
+
+	reviewerID := c.GetUint("user_id")
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var registration models.Registration
+		if err := tx.First(&registration, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errRegistrationNotFound
+			}
+			return err
+		}
+		if registration.Status != models.RegistrationPending {
+			return errRegistrationProcessed
+		}
+
+		now := time.Now()
+		registration.Status = models.RegistrationRejected
+		registration.ReviewedBy = &reviewerID
+		registration.ReviewedAt = &now
+		if err := tx.Save(&registration).Error; err != nil {
+			return err
+		}
+		// Приглашение было занято заявкой: после отказа им можно воспользоваться снова, пока оно не истекло.
+		if registration.InviteID != nil {
+			if err := tx.Model(&models.RegistrationInvite{}).
+				Where("id = ?", *registration.InviteID).
+				Update("used_at", nil).Error; err != nil {
+				return err
+			}
+		}
+
+		return services.Audit(tx, c, models.AuditReject, "registration", registration.ID,
+			gin.H{"status": models.RegistrationPending}, gin.H{"status": registration.Status})
+	})
+
+	if err != nil {
+		respondRegistrationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Заявка отклонена"})
+}
+
+// CreateRegistrationInvite godoc
+// @Summary Создать приглашение на регистрацию
+// @Description Выдаёт код, по которому сотрудник может зарегистрироваться. Срок действия по умолчанию — 7 дней.
+// @Tags registrations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param data body models.RegistrationInviteRequest true "Сотрудник и срок действия"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/registrations/invites [post]
+func CreateRegistrationInvite(c *gin.Context) {
+	var input models.RegistrationInviteRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid data"})
+		return
+	}
+	if input.TTLDays <= 0 {
+		input.TTLDays = 7
+	}
+
+	if err := db.DB.First(&models.Employee{}, input.EmployeeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	linked, err := services.EmployeeHasUser(db.DB, input.EmployeeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	if linked {
+		c.JSON(http.StatusConflict, gin.H{"error": "employee_already_linked", "message": "У сотрудника уже есть учётная запись"})
+		return
+	}
+
+	code, err := services.GenerateInviteCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error"})
+		return
+	}
+
+	invite := models.RegistrationInvite{
+		Code:       code,
+		EmployeeID: input.EmployeeID,
+		CreatedBy:  c.GetUint("user_id"),
+		ExpiresAt:  time.Now().AddDate(0, 0, input.TTLDays),
+	}
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := tx.Create(&invite).Error; err != nil {
+			return err
+		}
+		after := gin.H{"employee_id": invite.EmployeeID, "expires_at": invite.ExpiresAt}
+		return services.Audit(tx, c, models.AuditCreate, "registration_invite", invite.ID, nil, after)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "create failed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":        invite.Code,
+		"employee_id": invite.EmployeeID,
+		"expires_at":  invite.ExpiresAt,
+	})
+}
+
+func respondRegistrationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errRegistrationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "registration_not_found", "message": "Заявка не найдена"})
+	case errors.Is(err, errRegistrationProcessed):
+		c.JSON(http.StatusConflict, gin.H{"error": "registration_processed", "message": "Заявка уже обработана"})
+	case errors.Is(err, errRegistrationNoEmployee):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "employee_required", "message": "Укажите сотрудника для привязки"})
+	case errors.Is(err, errEmployeeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee_not_found", "message": "Сотрудник не найден"})
+	case errors.Is(err, services.ErrLoginTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "email_exists", "message": "Пользователь с таким логином уже существует"})
+	case errors.Is(err, services.ErrEmployeeLinked):
+		c.JSON(http.StatusConflict, gin.H{"error": "employee_already_linked", "message": "У сотрудника уже есть учётная запись"})
+	case errors.Is(err, services.ErrAccessGroupNotFound):
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "access_group_not_found", "message": "Группа доступа по умолчанию не настроена"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Ошибка обработки заявки"})
+	}
+}
@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListSessions godoc
+// @Summary Активные сессии
+// @Description Устройства, на которых выполнен вход: браузер и ОС, IP, время входа и последнего обращения.
+// @Description Текущая сессия отмечена current = true.
+// @Tags profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.SessionResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/profile/sessions [get]
+func ListSessions(c *gin.Context) {
+	respondSessions(c, c.GetUint("user_id"))
+}
+
+// RevokeSession godoc
+// @Summary Завершить сессию
+// @Description Отзывает сессию на другом устройстве (или текущую — это равносильно выходу).
+// @Tags profile
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID сессии"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/profile/sessions/{id} [delete]
+func RevokeSession(c *gin.Context) {
+	revokeSession(c, c.GetUint("user_id"), c.Param("id"))
+}
+
+// RevokeOtherSessions godoc
+// @Summary Завершить все сессии, кроме текущей
+// @Tags profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /api/profile/sessions/revoke-others [post]
+func RevokeOtherSessions(c *gin.Context) {
+	userID, sessionID := c.GetUint("user_id"), c.GetUint("session_id")
+
+	var revoked int64
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var err error
+		if revoked, err = services.RevokeOtherSessions(tx, userID, sessionID); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditDelete, "session", "user:"+strconv.FormatUint(uint64(userID), 10),
+			nil, gin.H{"kept_session_id": sessionID, "revoked": revoked})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось завершить сессии"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Остальные сессии завершены", "revoked": revoked})
+}
+
+// ListUserSessions godoc
+// @Summary Активные сессии пользователя
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {array} models.SessionResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/users/{id}/sessions [get]
+func ListUserSessions(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+	respondSessions(c, user.ID)
+}
+
+// RevokeUserSession godoc
+// @Summary Завершить сессию пользователя
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param sid path int true "ID сессии"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/users/{id}/sessions/{sid} [delete]
+func RevokeUserSession(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+	revokeSession(c, user.ID, c.Param("sid"))
+}
+
+// RevokeUserSessions godoc
+// @Summary Завершить все сессии пользователя
+// @Description Отзывает все сессии и токены пользователя; API-токены не затрагиваются.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/users/{id}/sessions [delete]
+func RevokeUserSessions(c *gin.Context) {
+	user, ok := loadUserParam(c)
+	if !ok {
+		return
+	}
+
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := services.RevokeUserTokens(tx, user.ID); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditDelete, "session", "user:"+strconv.FormatUint(uint64(user.ID), 10), nil, nil)
+	})
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Все сессии пользователя завершены"})
+}
+
+func respondSessions(c *gin.Context, userID uint) {
+	items, err := services.ActiveSessions(db.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	current := c.GetUint("session_id")
+	result := make([]models.SessionResponse, 0, len(items))
+	for _, s := range items {
+		result = append(result, models.SessionResponse{
+			ID:         s.ID,
+			Device:     services.DescribeUserAgent(s.UserAgent),
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.UserID == c.GetUint("user_id") && s.ID == current,
+		})
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func revokeSession(c *gin.Context, userID uint, rawID string) {
+	id, err := strconv.ParseUint(rawID, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := services.RevokeSession(tx, userID, uint(id)); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditDelete, "session", uint(id), nil, nil)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Сессия не найдена"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось завершить сессию"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Сессия завершена"})
+}
+
+// loadUserParam загружает пользователя по параметру :id; при ошибке отвечает сам.
+func loadUserParam(c *gin.Context) (models.User, bool) {
+	var user models.User
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return user, false
+	}
+
+	if err := db.DB.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondUserError(c, errUserNotFound)
+		} else {
+			respondUserError(c, err)
+		}
+		return user, false
+	}
+	return user, true
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListTrash godoc
+// @Summary Корзина
+// @Description Удалённые записи: сотрудники (вместе с кадровыми и рабочими данными), рабочие данные сотрудников
+// @Description и записи справочников. deleted_by — кто удалил запись по журналу аудита, purge_at — когда запись
+// @Description будет удалена окончательно.
+// @Tags trash
+// @Security BearerAuth
+// @Produce json
+// @Param type query string false "Тип записей: employees, work_days, departments, positions, access_groups, termination_reasons, custom_attributes"
+// @Success 200 {object} models.TrashListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/trash [get]
+func ListTrash(c *gin.Context) {
+	items, err := services.ListTrash(db.DB, c.Query("type"))
+	if errors.Is(err, services.ErrTrashUnknownType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TrashListResponse{
+		Items:         items,
+		RetentionDays: int(services.TrashRetention().Hours() / 24),
+	})
+}
+
+// RestoreTrashItem godoc
+// @Summary Восстановить удалённую запись
+// @Description Восстанавливает запись вместе с зависимыми: сотрудника — с кадровыми записями и рабочими данными,
+// @Description удалёнными вместе с ним; рабочие данные — с процессами и метриками. Дополнительное поле
+// @Description не восстанавливается, если его код занят новым полем (409).
+// @Tags trash
+// @Security BearerAuth
+// @Produce json
+// @Param type path string true "Тип записи"
+// @Param id path int true "ID записи (для work_days — ID сотрудника)"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/trash/{type}/{id}/restore [post]
+func RestoreTrashItem(c *gin.Context) {
+	id, ok := trashItemID(c)
+	if !ok {
+		return
+	}
+
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		return services.RestoreTrashItem(tx, c, c.Param("type"), id)
+	})
+	if respondTrashError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Запись восстановлена"})
+}
+
+// PurgeTrashItem godoc
+// @Summary Удалить запись окончательно
+// @Description Безвозвратно удаляет запись из корзины вместе с зависимыми данными. Справочник, на который
+// @Description ссылаются кадровые записи, и сотрудника с активной учётной записью удалить нельзя (409).
+// @Tags trash
+// @Security BearerAuth
+// @Produce json
+// @Param type path string true "Тип записи"
+// @Param id path int true "ID записи (для work_days — ID сотрудника)"
+// @Success 200 {object} map[string]string{message=string}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/trash/{type}/{id} [delete]
+func PurgeTrashItem(c *gin.Context) {
+	id, ok := trashItemID(c)
+	if !ok {
+		return
+	}
+
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		return services.PurgeTrashItem(tx, c, c.Param("type"), id)
+	})
+	if respondTrashError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Запись удалена окончательно"})
+}
+
+func trashItemID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondTrashError отвечает на ошибку операции с корзиной; false — ошибки нет.
+func respondTrashError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, services.ErrTrashUnknownType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "Удалённая запись не найдена"})
+	case errors.Is(err, services.ErrTrashInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": "in_use", "message": "На запись ссылаются другие данные"})
+	case errors.Is(err, services.ErrTrashCodeTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "code_taken", "message": "Код записи уже занят другой записью"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+	}
+	return true
+}
@@ -0,0 +1,331 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetTwoFactorStatus godoc
+// @Summary Состояние двухфакторной аутентификации
+// @Tags profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.TwoFactorStatusResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/profile/2fa [get]
+func GetTwoFactorStatus(c *gin.Context) {
+	var user models.User
+	if err := db.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	left, err := services.RecoveryCodesLeft(db.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TwoFactorStatusResponse{
+		Enabled:           user.TOTPEnabled,
+		Required:          services.TwoFactorRequired(c.GetStringSlice("groups")),
+		RecoveryCodesLeft: int(left),
+	})
+}
+
+// SetupTwoFactor godoc
+// @Summary Начать подключение 2FA
+// @Description Создаёт новый секрет TOTP. В приложение-аутентификатор его можно добавить
+// @Description вручную или отсканировав QR-код, построенный из otpauth_uri.
+// @Description 2FA включается только после подтверждения кодом (/api/profile/2fa/confirm).
+// @Tags profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.TwoFactorSetupResponse
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/profile/2fa/setup [post]
+func SetupTwoFactor(c *gin.Context) {
+	var user models.User
+	if err := db.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "two_factor_enabled", "message": "Двухфакторная аутентификация уже подключена"})
+		return
+	}
+
+	setup, err := startTOTPSetup(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось начать подключение"})
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// ConfirmTwoFactor godoc
+// @Summary Подтвердить подключение 2FA
+// @Description Включает 2FA после проверки кода из приложения и возвращает коды восстановления.
+// @Description Коды показываются один раз.
+// @Tags profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param payload body models.TwoFactorCodeRequest true "Код из приложения"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /api/profile/2fa/confirm [post]
+func ConfirmTwoFactor(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Введите код"})
+		return
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "two_factor_enabled", "message": "Двухфакторная аутентификация уже подключена"})
+		return
+	}
+	if !checkAttemptsAllowed(c, user) {
+		return
+	}
+
+	var codes []string
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := services.EnableTOTP(tx, user, req.Code, time.Now()); err != nil {
+			return err
+		}
+		var err error
+		if codes, err = services.GenerateRecoveryCodes(tx, user.ID); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditUpdate, "user", user.ID, gin.H{"two_factor": false}, gin.H{"two_factor": true})
+	})
+	if err != nil {
+		respondTwoFactorError(c, user, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Новые коды восстановления
+// @Description Заменяет коды восстановления новыми; прежние перестают действовать.
+// @Tags profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param payload body models.TwoFactorCodeRequest true "Код из приложения"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /api/profile/2fa/recovery-codes [post]
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Введите код"})
+		return
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if !checkAttemptsAllowed(c, user) {
+		return
+	}
+
+	var codes []string
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := services.VerifySecondFactor(tx, user, req.Code, time.Now()); err != nil {
+			return err
+		}
+		var err error
+		codes, err = services.GenerateRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		respondTwoFactorError(c, user, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor godoc
+// @Summary Отключить 2FA
+// @Description Требует текущий пароль и код из приложения (или код восстановления).
+// @Description Недоступно, если 2FA обязательна для группы пользователя.
+// @Tags profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param payload body models.TwoFactorDisableRequest true "Пароль и код"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /api/profile/2fa/disable [post]
+func DisableTwoFactor(c *gin.Context) {
+	var req models.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Введите пароль и код"})
+		return
+	}
+
+	if services.TwoFactorRequired(c.GetStringSlice("groups")) {
+		c.JSON(http.StatusConflict, gin.H{"error": "two_factor_required", "message": "Для вашей группы двухфакторная аутентификация обязательна"})
+		return
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, c.GetUint("user_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if !checkAttemptsAllowed(c, user) {
+		return
+	}
+	if !services.CheckPassword(req.Password, user.Password) {
+		registerFailedAttempt(c, user)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "wrong_password", "message": "Неверный пароль"})
+		return
+	}
+
+	err := services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := services.VerifySecondFactor(tx, user, req.Code, time.Now()); err != nil {
+			return err
+		}
+		if err := services.DisableTOTP(tx, user.ID); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditUpdate, "user", user.ID, gin.H{"two_factor": true}, gin.H{"two_factor": false})
+	})
+	if err != nil {
+		respondTwoFactorError(c, user, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Двухфакторная аутентификация отключена"})
+}
+
+// ResetUserTwoFactor godoc
+// @Summary Сбросить 2FA пользователя
+// @Description Отключает 2FA (например, при утере устройства и кодов восстановления) и завершает все сессии.
+// @Description Если 2FA обязательна, пользователь подключит её заново при следующем входе.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/users/{id}/2fa [delete]
+func ResetUserTwoFactor(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errUserNotFound
+			}
+			return err
+		}
+
+		if err := services.DisableTOTP(tx, user.ID); err != nil {
+			return err
+		}
+		if err := services.RevokeUserTokens(tx, user.ID); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditUpdate, "user", user.ID, gin.H{"two_factor": user.TOTPEnabled}, gin.H{"two_factor": false})
+	})
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Двухфакторная аутентификация сброшена"})
+}
+
+// startTOTPSetup сохраняет новый неподтверждённый секрет пользователя.
+func startTOTPSetup(user models.User) (*models.TwoFactorSetupResponse, error) {
+	secret, err := services.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := db.DB.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_last_step": 0,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: services.TwoFactorURI(user.Login, secret),
+	}, nil
+}
+
+// checkAttemptsAllowed применяет к проверке кода или пароля в профиле те же
+// ограничения, что и ко входу: перебор здесь не должен обходить блокировку.
+// При отказе отвечает сам.
+func checkAttemptsAllowed(c *gin.Context, user models.User) bool {
+	wait, err := services.CheckLoginAllowed(user.Login, c.ClientIP(), time.Now())
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, services.ErrLoginThrottled) || errors.Is(err, services.ErrLoginLocked) {
+		respondLoginThrottled(c, err, wait)
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Ошибка обработки запроса"})
+	}
+	return false
+}
+
+// registerFailedAttempt учитывает неверный код или пароль в счётчике попыток входа.
+func registerFailedAttempt(c *gin.Context, user models.User) {
+	if err := services.RegisterLoginFailure(user.Login, c.ClientIP(), time.Now()); err != nil {
+		log.Println("login attempts:", err)
+	}
+}
+
+func respondTwoFactorError(c *gin.Context, user models.User, err error) {
+	switch {
+	case errors.Is(err, services.ErrTwoFactorCodeInvalid):
+		registerFailedAttempt(c, user)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_code", "message": "Неверный код подтверждения"})
+	case errors.Is(err, services.ErrTwoFactorNotSetUp):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "two_factor_not_set_up", "message": "Двухфакторная аутентификация не подключена"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Ошибка обработки запроса"})
+	}
+}
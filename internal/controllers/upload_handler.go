@@ -12,14 +12,18 @@ import (
 
 	"github.com/MarBalueva/dashboard_efficiency/internal/db"
 	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/tealeg/xlsx"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 // UploadEmployees обрабатывает загрузку CSV/Excel для предпросмотра
 // @Summary Предпросмотр загруженных данных
-// @Description Загружает CSV или Excel файл и возвращает данные для предпросмотра, без записи в БД
+// @Description Загружает CSV или Excel файл и возвращает данные для предпросмотра, без записи в БД.
+// @Description Столбцы после восьмого, заголовок которых совпадает с кодом дополнительного поля,
+// @Description попадают в attributes строки.
 // @Tags upload
 // @Accept multipart/form-data
 // @Produce json
@@ -41,13 +45,23 @@ func UploadEmployees(c *gin.Context) {
 	c.SaveUploadedFile(file, tempPath)
 	defer os.Remove(tempPath)
 
+	attrs, err := services.LoadAttributes(db.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	codes := make(map[string]bool, len(attrs))
+	for _, a := range attrs {
+		codes[a.Code] = true
+	}
+
 	var preview []map[string]interface{}
 	var parseErr error
 
 	if strings.HasSuffix(file.Filename, ".csv") {
-		preview, parseErr = processCSV(tempPath)
+		preview, parseErr = processCSV(tempPath, codes)
 	} else if strings.HasSuffix(file.Filename, ".xlsx") || strings.HasSuffix(file.Filename, ".xls") {
-		preview, parseErr = processExcel(tempPath)
+		preview, parseErr = processExcel(tempPath, codes)
 	} else {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "только CSV или Excel"})
 		return
@@ -63,7 +77,8 @@ func UploadEmployees(c *gin.Context) {
 
 // ConfirmUpload сохраняет данные сотрудников и кадровых метрик
 // @Summary Подтверждение загрузки данных сотрудников
-// @Description Получает массив объектов с данными сотрудников и сохраняет их в БД
+// @Description Получает массив объектов с данными сотрудников и сохраняет их в БД. Значения attributes
+// @Description записываются в дополнительные поля сотрудника; пустые значения пропускаются.
 // @Tags upload
 // @Accept json
 // @Produce json
@@ -71,6 +86,7 @@ func UploadEmployees(c *gin.Context) {
 // @Success 200 {object} map[string]interface{} "Сообщение о добавленных/обновленных записях"
 // @Failure 400 {object} map[string]string "Ошибка при обработке данных"
 // @Failure 401 {object} map[string]string "Пользователь не авторизован"
+// @Failure 403 {object} map[string]interface{} "Есть сотрудники вне доступных отделов"
 // @Router /api/upload/confirm [post]
 // @Security BearerAuth
 func ConfirmUpload(c *gin.Context) {
@@ -88,6 +104,8 @@ func ConfirmUpload(c *gin.Context) {
 		WorkLifeBalance int       `json:"work_life_balance"`
 		Satisfaction    int       `json:"satisfaction"`
 		Productivity    int       `json:"productivity"`
+
+		Attributes map[string]interface{} `json:"attributes"`
 	}
 
 	var rows []UploadRow
@@ -96,76 +114,120 @@ func ConfirmUpload(c *gin.Context) {
 		return
 	}
 
-	added := 0
-	errors := []string{}
-	now := time.Now()
-
+	scope := services.ScopeFor(c, services.PermEmployeesReadAll)
+	forbidden := []uint{}
+	checked := map[uint]bool{}
 	for _, r := range rows {
-
-		workDay := models.WorkDay{
-			EmployeeID:   r.EmployeeID,
-			StartWorkDay: r.StartWorkDay,
-			EndWorkDay:   r.EndWorkDay,
-			CreatedAt:    now,
-		}
-
-		if err := db.DB.
-			Clauses(clause.OnConflict{
-				Columns: []clause.Column{
-					{Name: "employee_id"},
-					{Name: "start_work_day"},
-					{Name: "end_work_day"},
-				},
-				UpdateAll: true,
-			}).
-			Create(&workDay).Error; err != nil {
-
-			errors = append(errors, fmt.Sprintf(
-				"WorkDay EmployeeID=%d: %v", r.EmployeeID, err,
-			))
+		if checked[r.EmployeeID] {
 			continue
 		}
+		checked[r.EmployeeID] = true
 
-		workDayID := workDay.ID
-
-		workProcess := models.WorkProcess{
-			WorkDayID:      workDayID,
-			CallsCount:     r.CallsCount,
-			CompletedTasks: r.CompletedTasks,
-			CreatedAt:      now,
+		allowed, err := scope.AllowsWrite(db.DB, r.EmployeeID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		if !allowed {
+			forbidden = append(forbidden, r.EmployeeID)
 		}
+	}
+	if len(forbidden) > 0 {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message":      "нет доступа к сотрудникам других отделов",
+			"employee_ids": forbidden,
+		})
+		return
+	}
+
+	attrs, err := services.LoadAttributes(db.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
 
-		if err := db.DB.
-			Clauses(clause.OnConflict{
-				Columns:   []clause.Column{{Name: "work_day_id"}},
-				UpdateAll: true,
-			}).
-			Create(&workProcess).Error; err != nil {
+	added := 0
+	errors := []string{}
+	now := time.Now()
 
-			errors = append(errors, fmt.Sprintf(
-				"WorkProcess WorkDayID=%d: %v", workDayID, err,
-			))
+	for _, r := range rows {
+		attributes, err := services.CheckAttributes(attrs, r.Attributes, false)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Attributes EmployeeID=%d: %v", r.EmployeeID, err))
 			continue
 		}
 
-		satMetric := models.SatisfactionMetric{
-			WorkDayID:       workDayID,
-			WorkLifeBalance: r.WorkLifeBalance,
-			Satisfaction:    r.Satisfaction,
-			Productivity:    r.Productivity,
-			CreatedAt:       now,
-		}
-
-		if err := db.DB.
-			Clauses(clause.OnConflict{
-				Columns:   []clause.Column{{Name: "work_day_id"}},
-				UpdateAll: true,
-			}).
-			Create(&satMetric).Error; err != nil {
+		// Каждая строка сохраняется в отдельной транзакции вместе с записью аудита
+		err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+			workDay := models.WorkDay{
+				EmployeeID:   r.EmployeeID,
+				StartWorkDay: r.StartWorkDay,
+				EndWorkDay:   r.EndWorkDay,
+				CreatedAt:    now,
+			}
+
+			if err := tx.
+				Clauses(clause.OnConflict{
+					Columns: []clause.Column{
+						{Name: "employee_id"},
+						{Name: "start_work_day"},
+						{Name: "end_work_day"},
+					},
+					UpdateAll: true,
+				}).
+				Create(&workDay).Error; err != nil {
+				return fmt.Errorf("WorkDay EmployeeID=%d: %v", r.EmployeeID, err)
+			}
+
+			workDayID := workDay.ID
+
+			workProcess := models.WorkProcess{
+				WorkDayID:      workDayID,
+				CallsCount:     r.CallsCount,
+				CompletedTasks: r.CompletedTasks,
+				CreatedAt:      now,
+			}
+
+			if err := tx.
+				Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "work_day_id"}},
+					UpdateAll: true,
+				}).
+				Create(&workProcess).Error; err != nil {
+				return fmt.Errorf("WorkProcess WorkDayID=%d: %v", workDayID, err)
+			}
+
+			satMetric := models.SatisfactionMetric{
+				WorkDayID:       workDayID,
+				WorkLifeBalance: r.WorkLifeBalance,
+				Satisfaction:    r.Satisfaction,
+				Productivity:    r.Productivity,
+				CreatedAt:       now,
+			}
+
+			if err := tx.
+				Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "work_day_id"}},
+					UpdateAll: true,
+				}).
+				Create(&satMetric).Error; err != nil {
+				return fmt.Errorf("SatisfactionMetric WorkDayID=%d: %v", workDayID, err)
+			}
+
+			if len(attributes) > 0 {
+				if _, err := services.BumpEmployeeVersion(tx, r.EmployeeID, 0); err != nil {
+					return fmt.Errorf("Attributes EmployeeID=%d: %v", r.EmployeeID, err)
+				}
+				if err := services.SaveEmployeeAttributes(tx, r.EmployeeID, attributes, false); err != nil {
+					return fmt.Errorf("Attributes EmployeeID=%d: %v", r.EmployeeID, err)
+				}
+			}
+
+			return services.Audit(tx, c, models.AuditImport, "work_day", workDayID, nil, r)
+		})
 
-			errors = append(errors, fmt.Sprintf(
-				"SatisfactionMetric WorkDayID=%d: %v", workDayID, err,
-			))
+		if err != nil {
+			errors = append(errors, err.Error())
 			continue
 		}
 
@@ -178,7 +240,8 @@ func ConfirmUpload(c *gin.Context) {
 	})
 }
 
-func processCSV(path string) ([]map[string]interface{}, error) {
+// processCSV и processExcel разбирают файл загрузки; codes — коды дополнительных полей.
+func processCSV(path string, codes map[string]bool) ([]map[string]interface{}, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("не удалось открыть CSV")
@@ -189,7 +252,7 @@ func processCSV(path string) ([]map[string]interface{}, error) {
 	reader.TrimLeadingSpace = true
 
 	// Заголовок
-	_, err = reader.Read()
+	header, err := reader.Read()
 	if err != nil {
 		return nil, fmt.Errorf("не удалось прочитать заголовок CSV")
 	}
@@ -203,7 +266,7 @@ func processCSV(path string) ([]map[string]interface{}, error) {
 		if err != nil {
 			continue
 		}
-		data, err := parseRow(row)
+		data, err := parseRow(header, row, codes)
 		if err != nil {
 			continue
 		}
@@ -213,7 +276,7 @@ func processCSV(path string) ([]map[string]interface{}, error) {
 	return preview, nil
 }
 
-func processExcel(path string) ([]map[string]interface{}, error) {
+func processExcel(path string, codes map[string]bool) ([]map[string]interface{}, error) {
 	xlFile, err := xlsx.OpenFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("не удалось открыть Excel")
@@ -221,18 +284,19 @@ func processExcel(path string) ([]map[string]interface{}, error) {
 
 	sheet := xlFile.Sheets[0]
 	var preview []map[string]interface{}
+	var header []string
 
 	for i, row := range sheet.Rows {
-		if i == 0 {
-			continue
-		}
-
 		cells := make([]string, len(row.Cells))
 		for j, cell := range row.Cells {
 			cells[j] = cell.String()
 		}
+		if i == 0 {
+			header = cells
+			continue
+		}
 
-		data, err := parseRow(cells)
+		data, err := parseRow(header, cells, codes)
 		if err != nil {
 			continue
 		}
@@ -242,7 +306,9 @@ func processExcel(path string) ([]map[string]interface{}, error) {
 	return preview, nil
 }
 
-func parseRow(row []string) (map[string]interface{}, error) {
+// parseRow разбирает строку загрузки. Значения столбцов после восьмого, заголовок
+// которых — код дополнительного поля из codes, возвращаются в attributes.
+func parseRow(header, row []string, codes map[string]bool) (map[string]interface{}, error) {
 	if len(row) < 8 {
 		return nil, fmt.Errorf("не хватает столбцов")
 	}
@@ -256,7 +322,7 @@ func parseRow(row []string) (map[string]interface{}, error) {
 	satisfaction, _ := strconv.Atoi(row[6])
 	productivity, _ := strconv.Atoi(row[7])
 
-	return map[string]interface{}{
+	data := map[string]interface{}{
 		"employee_id":       employeeID,
 		"start_work_day":    startWork,
 		"end_work_day":      endWork,
@@ -265,5 +331,18 @@ func parseRow(row []string) (map[string]interface{}, error) {
 		"work_life_balance": wlb,
 		"satisfaction":      satisfaction,
 		"productivity":      productivity,
-	}, nil
+	}
+
+	attributes := map[string]interface{}{}
+	for j := 8; j < len(row) && j < len(header); j++ {
+		code := strings.TrimSpace(header[j])
+		if value := strings.TrimSpace(row[j]); codes[code] && value != "" {
+			attributes[code] = value
+		}
+	}
+	if len(attributes) > 0 {
+		data["attributes"] = attributes
+	}
+
+	return data, nil
 }
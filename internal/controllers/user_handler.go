@@ -0,0 +1,652 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errUserNotFound      = errors.New("user not found")
+	errUnknownDepartment = errors.New("unknown department")
+)
+
+// ListUsers godoc
+// @Summary Список пользователей
+// @Description Возвращает все учётные записи, включая отключённые, с группами доступа
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.UserResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/users [get]
+func ListUsers(c *gin.Context) {
+	var users []models.User
+	if err := db.DB.
+		Preload("AccessGroups.AccessGroup").
+		Order("id").
+		Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	employeeIDs := make([]uint, 0, len(users))
+	for _, u := range users {
+		employeeIDs = append(employeeIDs, u.EmployeeID)
+	}
+
+	var employees []models.Employee
+	if err := db.DB.Unscoped().Where("id IN ?", employeeIDs).Find(&employees).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+	names := make(map[uint]string, len(employees))
+	for _, e := range employees {
+		names[e.ID] = strings.TrimSpace(e.LastName + " " + e.FirstName + " " + e.MiddleName)
+	}
+
+	locked, err := services.LockedLogins(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	result := make([]models.UserResponse, 0, len(users))
+	for _, u := range users {
+		resp := userResponse(u, names[u.EmployeeID])
+		if st, ok := locked[strings.ToLower(u.Login)]; ok {
+			resp.Locked = true
+			resp.LockedUntil = st.LockedUntil
+		}
+		result = append(result, resp)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateUser godoc
+// @Summary Создать учётную запись для сотрудника
+// @Description Создаёт пользователя, привязанного к сотруднику. Если группы не переданы, назначается группа employee.
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param data body models.UserCreateRequest true "Данные пользователя"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/users [post]
+func CreateUser(c *gin.Context) {
+	var input models.UserCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Неверные данные"})
+		return
+	}
+
+	input.Login = strings.TrimSpace(input.Login)
+	if input.Login == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Логин не может быть пустым"})
+		return
+	}
+	if len(input.Groups) == 0 {
+		input.Groups = []string{services.DefaultUserGroup}
+	}
+	if err := services.ValidatePassword(input.Password, input.Login); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	hash, err := services.HashPassword(input.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Не удалось обработать пароль"})
+		return
+	}
+
+	var user *models.User
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := tx.First(&models.Employee{}, input.EmployeeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errEmployeeNotFound
+			}
+			return err
+		}
+
+		var err error
+		user, err = services.CreateUser(tx, input.Login, hash, input.EmployeeID, input.Groups...)
+		if err != nil {
+			return err
+		}
+
+		after := gin.H{"login": user.Login, "employee_id": user.EmployeeID, "groups": input.Groups}
+		return services.Audit(tx, c, models.AuditCreate, "user", user.ID, nil, after)
+	})
+
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Пользователь создан", "id": user.ID})
+}
+
+// ResetUserPassword godoc
+// @Summary Задать временный пароль пользователя
+// @Description Устанавливает пользователю новый пароль; при следующем входе пользователь должен будет его сменить
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param data body models.UserPasswordRequest true "Новый пароль"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/users/{id}/password [put]
+func ResetUserPassword(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var input models.UserPasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Неверные данные"})
+		return
+	}
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errUserNotFound
+			}
+			return err
+		}
+
+		if err := services.SetPassword(tx, user, input.Password); err != nil {
+			return err
+		}
+		// Пароль, заданный администратором, временный.
+		if err := tx.Model(&user).Update("must_change_password", true).Error; err != nil {
+			return err
+		}
+		if err := services.RevokeUserTokens(tx, user.ID); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditUpdate, "user", id, nil, gin.H{"password_reset": true})
+	})
+
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Пароль изменён"})
+}
+
+// DisableUser godoc
+// @Summary Отключить пользователя
+// @Description Помечает учётную запись как отключённую (заполняет deleted_at). Вход для неё становится невозможен.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/users/{id} [delete]
+func DisableUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	if uint(id) == c.GetUint("user_id") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot_disable_self", "message": "Нельзя отключить собственную учётную запись"})
+		return
+	}
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		res := tx.Model(&models.User{}).
+			Where("id = ? AND deleted_at IS NULL", id).
+			Update("deleted_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errUserNotFound
+		}
+		if err := services.RevokeUserTokens(tx, uint(id)); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditUpdate, "user", id, gin.H{"disabled": false}, gin.H{"disabled": true})
+	})
+
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Пользователь отключён"})
+}
+
+// EnableUser godoc
+// @Summary Включить пользователя
+// @Description Снимает отметку об отключении учётной записи
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/users/{id}/enable [post]
+func EnableUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errUserNotFound
+			}
+			return err
+		}
+
+		linked, err := services.EmployeeHasUser(tx, user.EmployeeID)
+		if err != nil {
+			return err
+		}
+		if linked {
+			return services.ErrEmployeeLinked
+		}
+
+		if err := tx.Model(&user).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditUpdate, "user", id, gin.H{"disabled": true}, gin.H{"disabled": false})
+	})
+
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Пользователь включён"})
+}
+
+// ListLockedAccounts godoc
+// @Summary Заблокированные учётные записи
+// @Description Логины, временно заблокированные после серии неудачных попыток входа.
+// @Description В список попадают и логины, для которых нет учётной записи (user_id = null).
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.LockedAccountResponse
+// @Failure 403 {object} map[string]string
+// @Router /api/users/locked [get]
+func ListLockedAccounts(c *gin.Context) {
+	locked, err := services.LockedLogins(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	logins := make([]string, 0, len(locked))
+	for login := range locked {
+		logins = append(logins, login)
+	}
+
+	var users []models.User
+	if len(logins) > 0 {
+		if err := db.DB.Where("LOWER(login) IN ?", logins).Find(&users).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+			return
+		}
+	}
+	userIDs := make(map[string]uint, len(users))
+	for _, u := range users {
+		userIDs[strings.ToLower(u.Login)] = u.ID
+	}
+
+	result := make([]models.LockedAccountResponse, 0, len(locked))
+	for login, st := range locked {
+		item := models.LockedAccountResponse{
+			Login:       login,
+			Failures:    st.Failures,
+			LockedUntil: *st.LockedUntil,
+		}
+		if id, ok := userIDs[login]; ok {
+			item.UserID = &id
+		}
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].LockedUntil.After(result[j].LockedUntil) })
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UnlockUser godoc
+// @Summary Разблокировать вход пользователя
+// @Description Сбрасывает счётчик неудачных попыток входа и снимает временную блокировку
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/users/{id}/unlock [post]
+func UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var user models.User
+	if err := db.DB.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondUserError(c, errUserNotFound)
+			return
+		}
+		respondUserError(c, err)
+		return
+	}
+
+	if err := services.UnlockLogin(user.Login); err != nil {
+		respondUserError(c, err)
+		return
+	}
+	if err := services.Audit(db.DB, c, models.AuditUpdate, "user", user.ID, gin.H{"locked": true}, gin.H{"locked": false}); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Блокировка входа снята"})
+}
+
+// AddUserGroup godoc
+// @Summary Добавить пользователя в группу доступа
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param data body models.UserGroupRequest true "Код группы доступа"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/users/{id}/groups [post]
+func AddUserGroup(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var input models.UserGroupRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Неверные данные"})
+		return
+	}
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := tx.First(&models.User{}, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errUserNotFound
+			}
+			return err
+		}
+		if err := services.AddUserToGroup(tx, uint(id), input.Code); err != nil {
+			return err
+		}
+		if err := services.RevokeUserTokens(tx, uint(id)); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditCreate, "user_access_group", id, nil, gin.H{"user_id": id, "group": input.Code})
+	})
+
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Группа доступа добавлена"})
+}
+
+// RemoveUserGroup godoc
+// @Summary Исключить пользователя из группы доступа
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param code path string true "Код группы доступа"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/users/{id}/groups/{code} [delete]
+func RemoveUserGroup(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := services.RemoveUserFromGroup(tx, uint(id), c.Param("code")); err != nil {
+			return err
+		}
+		if err := services.RevokeUserTokens(tx, uint(id)); err != nil {
+			return err
+		}
+		return services.Audit(tx, c, models.AuditDelete, "user_access_group", id, gin.H{"user_id": id, "group": c.Param("code")}, nil)
+	})
+
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Группа доступа удалена"})
+}
+
+// GetUserDepartments godoc
+// @Summary Отделы, закреплённые за пользователем
+// @Description Менеджер видит и изменяет данные только сотрудников этих отделов
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {array} models.Department
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/users/{id}/departments [get]
+func GetUserDepartments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	if err := db.DB.First(&models.User{}, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondUserError(c, errUserNotFound)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	var items []models.Department
+	if err := db.DB.
+		Joins("JOIN user_departments ud ON ud.department_id = departments.id").
+		Where("ud.user_id = ?", id).
+		Order("departments.name").
+		Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "db error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// SetUserDepartments godoc
+// @Summary Закрепить отделы за пользователем
+// @Description Полностью заменяет список отделов пользователя
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param data body models.UserDepartmentsRequest true "ID отделов"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/users/{id}/departments [put]
+func SetUserDepartments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var input models.UserDepartmentsRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_input", "message": "Неверные данные"})
+		return
+	}
+
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		if err := tx.First(&models.User{}, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errUserNotFound
+			}
+			return err
+		}
+
+		var departments []models.Department
+		if len(input.DepartmentIDs) > 0 {
+			if err := tx.Where("id IN ?", input.DepartmentIDs).Find(&departments).Error; err != nil {
+				return err
+			}
+		}
+		if len(departments) != len(uniqueUints(input.DepartmentIDs)) {
+			return errUnknownDepartment
+		}
+
+		var previous []uint
+		if err := tx.Model(&models.UserDepartment{}).Where("user_id = ?", id).Pluck("department_id", &previous).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&models.UserDepartment{}).Error; err != nil {
+			return err
+		}
+		current := make([]uint, 0, len(departments))
+		for _, d := range departments {
+			if err := tx.Create(&models.UserDepartment{UserID: uint(id), DepartmentID: d.ID}).Error; err != nil {
+				return err
+			}
+			current = append(current, d.ID)
+		}
+
+		return services.Audit(tx, c, models.AuditUpdate, "user_departments", id,
+			gin.H{"department_ids": previous}, gin.H{"department_ids": current})
+	})
+
+	if errors.Is(err, errUnknownDepartment) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown_department", "message": "Указаны несуществующие отделы"})
+		return
+	}
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	services.InvalidatePrincipal(uint(id))
+	c.JSON(http.StatusOK, gin.H{"message": "Отделы пользователя обновлены"})
+}
+
+func userResponse(u models.User, employeeName string) models.UserResponse {
+	groups := make([]models.UserGroupResponse, 0, len(u.AccessGroups))
+	for _, ug := range u.AccessGroups {
+		groups = append(groups, models.UserGroupResponse{
+			ID:   ug.AccessGroup.ID,
+			Code: ug.AccessGroup.Code,
+			Name: ug.AccessGroup.Name,
+		})
+	}
+
+	return models.UserResponse{
+		ID:           u.ID,
+		Login:        u.Login,
+		EmployeeID:   u.EmployeeID,
+		EmployeeName: employeeName,
+		AccessGroups: groups,
+		Disabled:     u.DeletedAt != nil,
+		DisabledAt:   u.DeletedAt,
+		CreatedAt:    u.CreatedAt,
+	}
+}
+
+func respondUserError(c *gin.Context, err error) {
+	if respondPasswordError(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, errUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user_not_found", "message": "Пользователь не найден"})
+	case errors.Is(err, errEmployeeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "employee_not_found", "message": "Сотрудник не найден"})
+	case errors.Is(err, services.ErrLoginTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "login_exists", "message": "Пользователь с таким логином уже существует"})
+	case errors.Is(err, services.ErrEmployeeLinked):
+		c.JSON(http.StatusConflict, gin.H{"error": "employee_already_linked", "message": "У сотрудника уже есть активная учётная запись"})
+	case errors.Is(err, services.ErrAccessGroupNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "access_group_not_found", "message": "Группа доступа не найдена"})
+	case errors.Is(err, services.ErrAlreadyInGroup):
+		c.JSON(http.StatusConflict, gin.H{"error": "already_in_group", "message": "Пользователь уже состоит в группе"})
+	case errors.Is(err, services.ErrNotInGroup):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_in_group", "message": "Пользователь не состоит в группе"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "Ошибка обработки запроса"})
+	}
+}
+
+func uniqueUints(items []uint) []uint {
+	seen := make(map[uint]bool, len(items))
+	result := make([]uint, 0, len(items))
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
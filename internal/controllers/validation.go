@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationErrorResponse — ответ 422: message дублирует первую ошибку, fields содержит все.
+type ValidationErrorResponse struct {
+	Error   string                `json:"error" example:"validation_failed"`
+	Message string                `json:"message"`
+	Fields  []services.FieldError `json:"fields"`
+}
+
+// bindJSON разбирает тело запроса в obj. Пропущенные обязательные поля и значения
+// неверного типа возвращаются как *services.ValidationError, прочие ошибки — как есть.
+func bindJSON(c *gin.Context, obj interface{}) error {
+	return bindError(obj, c.ShouldBindJSON(obj))
+}
+
+// bindError приводит ошибку разбора или проверки obj к виду, описанному в bindJSON.
+func bindError(obj interface{}, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	v := &services.ValidationError{}
+	var fieldErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &fieldErrs):
+		t := reflect.TypeOf(obj).Elem()
+		for _, fe := range fieldErrs {
+			field := jsonFieldName(t, fe.StructField())
+			if fe.Tag() == "required" {
+				v.Add(field, "required", "Обязательное поле")
+			} else {
+				v.Add(field, "invalid", "Неверное значение")
+			}
+		}
+	case errors.As(err, &typeErr):
+		v.Add(typeErr.Field, "invalid_type", "Неверный тип значения")
+	default:
+		return err
+	}
+	return v
+}
+
+// respondValidation отвечает 422 со списком ошибок полей.
+// Возвращает false, если err к проверке данных не относится.
+func respondValidation(c *gin.Context, err error) bool {
+	var verr *services.ValidationError
+	if !errors.As(err, &verr) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, ValidationErrorResponse{
+		Error:   "validation_failed",
+		Message: verr.Fields[0].Message,
+		Fields:  verr.Fields,
+	})
+	return true
+}
+
+// respondBindError отвечает на ошибку bindJSON.
+func respondBindError(c *gin.Context, err error) {
+	if !respondValidation(c, err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid data"})
+	}
+}
+
+func jsonFieldName(t reflect.Type, name string) string {
+	if f, ok := t.FieldByName(name); ok {
+		if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+			return tag
+		}
+	}
+	return name
+}
@@ -8,12 +8,13 @@ import (
 	"github.com/MarBalueva/dashboard_efficiency/internal/models"
 	"github.com/MarBalueva/dashboard_efficiency/internal/services"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // GetEmployeesTable godoc
 //
 //	@Summary		Получить таблицу сотрудников
-//	@Description	employee видит только свои данные, admin и manager — все
+//	@Description	Пользователи с правом work.read_all видят данные сотрудников закреплённых за ними отделов, остальные — только свои; линейный руководитель видит и данные подчинённых
 //	@Tags			employees
 //	@Accept			json
 //	@Produce		json
@@ -24,18 +25,10 @@ import (
 //
 // @Security BearerAuth
 func GetWork(c *gin.Context) {
-	userIDAny, exists := c.Get("user_id")
-	if !exists {
+	if _, exists := c.Get("user_id"); !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	userID := userIDAny.(uint)
-
-	var user models.User
-	if err := db.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
-		return
-	}
 
 	query := db.DB.
 		Table("work_days wd").
@@ -63,9 +56,7 @@ func GetWork(c *gin.Context) {
 		Joins("JOIN employees e ON e.id = wd.employee_id").
 		Where("wd.deleted_at IS NULL")
 
-	if services.HasAnyGroup(user, "employee") && !services.HasAnyGroup(user, "admin") && !services.HasAnyGroup(user, "manager") {
-		query = query.Where("wd.employee_id = ?", user.EmployeeID)
-	}
+	query = services.ScopeFor(c, services.PermWorkReadAll).Apply(query, "wd.employee_id")
 
 	var result []models.EmployeeWorkSummary
 	if err := query.Scan(&result).Error; err != nil {
@@ -79,50 +70,51 @@ func GetWork(c *gin.Context) {
 // DeleteEmployee godoc
 //
 //	@Summary		Удалить сотрудника
-//	@Description	Удаляет рабочий день, процессы и метрики сотрудника по employee_id (soft delete)
+//	@Description	Удаляет рабочие дни, процессы и метрики сотрудника по employee_id (soft delete); восстановить их можно через /api/trash
 //	@Tags			employees
 //	@Accept			json
 //	@Produce		json
 //	@Param			employee_id	path	int	true	"ID сотрудника"
 //	@Success		204	"No Content"
 //	@Failure		400	{object}	map[string]string
+//	@Failure		403	{object}	map[string]string
 //	@Failure		500	{object}	map[string]string
 //	@Router			/api/employees/work/{employee_id} [delete]
 //
 // @Security BearerAuth
 func DeleteWork(c *gin.Context) {
-	userIDAny, exists := c.Get("user_id")
-	if !exists {
+	if _, exists := c.Get("user_id"); !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	userID := userIDAny.(uint)
-
-	var user models.User
-	if err := db.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
-		return
-	}
-
 	paramID, err := strconv.ParseUint(c.Param("employee_id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid employee_id"})
 		return
 	}
 
-	if services.HasAnyGroup(user, "employee") && user.EmployeeID != uint(paramID) {
+	allowed, err := services.ScopeFor(c, services.PermWorkReadAll).AllowsWrite(db.DB, uint(paramID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !allowed {
 		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 		return
 	}
 
-	tx := db.DB.Begin()
+	err = services.Transaction(db.DB, func(tx *gorm.DB) error {
+		deletedIDs, err := services.DeleteWorkData(tx, uint(paramID))
+		if err != nil {
+			return err
+		}
 
-	tx.Where("employee_id = ?", paramID).Delete(&models.WorkDay{})
-	tx.Where("employee_id = ?", paramID).Delete(&models.WorkProcess{})
-	tx.Where("employee_id = ?", paramID).Delete(&models.SatisfactionMetric{})
+		before := gin.H{"employee_id": paramID, "work_day_ids": deletedIDs}
+		return services.Audit(tx, c, models.AuditDelete, "work_days", paramID, before, nil)
+	})
 
-	if err := tx.Commit().Error; err != nil {
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -26,15 +26,39 @@ func Migrate() error {
 		return nil
 	}
 
+	if err := migrateEncryptedColumns(); err != nil {
+		log.Println("migration error:", err)
+		return err
+	}
+
 	err := DB.AutoMigrate(
 		&models.AccessGroup{},
+		&models.AccessGroupPermission{},
+		&models.AuditEvent{},
+		&models.LoginAttempt{},
+		&models.RecoveryCode{},
+		&models.PasswordHistory{},
+		&models.PasswordResetToken{},
+		&models.UserIdentity{},
+		&models.APIToken{},
+		&models.UserSession{},
+		&models.ImpersonationSession{},
+		&models.CustomAttribute{},
+		&models.CustomAttributeOption{},
 		&models.Department{},
 		&models.Employee{},
+		&models.EmployeeAttributeValue{},
 		&models.EmployeeHR{},
+		&models.TerminationReason{},
+		&models.Permission{},
 		&models.Position{},
+		&models.RefreshToken{},
+		&models.Registration{},
+		&models.RegistrationInvite{},
 		&models.SatisfactionMetric{},
 		&models.User{},
 		&models.UserAccessGroup{},
+		&models.UserDepartment{},
 		&models.WorkDay{},
 		&models.WorkProcess{},
 	)
@@ -44,5 +68,54 @@ func Migrate() error {
 		return err
 	}
 
+	// Оклад и дата рождения попадали в журнал аудита открытым текстом до шифрования полей.
+	if err := DB.Exec(`UPDATE audit_events SET
+			before = before - 'salary' - 'birth_date',
+			after = after - 'salary' - 'birth_date',
+			diff = diff - 'salary' - 'birth_date'
+		WHERE entity_type = 'employee' AND (
+			jsonb_exists_any(before, array['salary', 'birth_date']) OR
+			jsonb_exists_any(after, array['salary', 'birth_date']) OR
+			jsonb_exists_any(diff, array['salary', 'birth_date']))`).Error; err != nil {
+		log.Println("migration error:", err)
+		return err
+	}
+
+	// Кадровые записи, созданные до появления истории, действуют с даты приёма.
+	if err := DB.Exec(`UPDATE employee_hrs SET valid_from = hire_date::date, change_type = 'hire'
+		WHERE valid_from IS NULL`).Error; err != nil {
+		log.Println("migration error:", err)
+		return err
+	}
+
+	return nil
+}
+
+// migrateEncryptedColumns переводит оклад и дату рождения в текстовые колонки
+// под зашифрованные значения. Старые данные остаются открытыми до
+// services.RotateFieldEncryption.
+func migrateEncryptedColumns() error {
+	if !DB.Migrator().HasTable("employee_hrs") {
+		return nil
+	}
+
+	conversions := map[string]string{
+		"salary":     "salary::text",
+		"birth_date": `to_char(birth_date AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')`,
+	}
+	for column, using := range conversions {
+		var dataType string
+		if err := DB.Raw(`SELECT data_type FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'employee_hrs' AND column_name = ?`, column).
+			Scan(&dataType).Error; err != nil {
+			return err
+		}
+		if dataType == "" || dataType == "text" {
+			continue
+		}
+		if err := DB.Exec("ALTER TABLE employee_hrs ALTER COLUMN " + column + " TYPE text USING " + using).Error; err != nil {
+			return err
+		}
+	}
 	return nil
 }
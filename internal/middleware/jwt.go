@@ -2,11 +2,11 @@ package middleware
 
 import (
 	"net/http"
-	"os"
 	"strings"
 
+	"github.com/MarBalueva/dashboard_efficiency/internal/db"
+	"github.com/MarBalueva/dashboard_efficiency/internal/services"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 func AuthMiddleware() gin.HandlerFunc {
@@ -25,30 +25,76 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		tokenStr := parts[1]
-		secret := os.Getenv("JWT_SECRET")
-
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrTokenMalformed
-			}
-			return []byte(secret), nil
-		})
+		if strings.HasPrefix(parts[1], services.APITokenPrefix) {
+			authenticateAPIToken(c, parts[1])
+			return
+		}
 
-		if err != nil || !token.Valid {
+		// Challenge-токены промежуточных шагов входа и cookie OIDC не дают доступа к API.
+		claims, err := services.ParseToken(parts[1], services.TokenTypeAccess)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
+		rawID, ok := claims["user_id"].(float64)
 		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
 			c.Abort()
 			return
 		}
+		userID := uint(rawID)
+		version, _ := claims["ver"].(float64)
 
-		c.Set("user_id", uint(claims["user_id"].(float64)))
+		principal, err := services.LoadPrincipal(db.DB, userID)
+		if err != nil || int(version) != principal.TokenVersion {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token_revoked", "message": "Сессия завершена, войдите снова"})
+			c.Abort()
+			return
+		}
+
+		// Отозванная сессия закрывает и уже выданные access-токены.
+		if rawSession, ok := claims["sid"].(float64); ok && rawSession > 0 {
+			if err := services.CheckSession(db.DB, userID, uint(rawSession), c.ClientIP()); err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "token_revoked", "message": "Сессия завершена, войдите снова"})
+				c.Abort()
+				return
+			}
+			c.Set("session_id", uint(rawSession))
+		}
+
+		// Токен имперсонации действует, пока открыта его сессия
+		// и у администратора сохраняется право на имперсонацию.
+		if rawSession, ok := claims["imp"].(float64); ok {
+			rawActor, _ := claims["act"].(float64)
+			session, err := services.LoadImpersonation(db.DB, uint(rawSession), uint(rawActor))
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "impersonation_ended", "message": "Сессия просмотра от имени пользователя завершена"})
+				c.Abort()
+				return
+			}
+			actor, err := services.LoadPrincipal(db.DB, session.ActorID)
+			if err == nil {
+				var allowed bool
+				allowed, err = services.GroupsHavePermission(actor.Groups, services.PermUsersImpersonate)
+				if err == nil && !allowed {
+					err = services.ErrImpersonationInvalid
+				}
+			}
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "impersonation_ended", "message": "Сессия просмотра от имени пользователя завершена"})
+				c.Abort()
+				return
+			}
+			c.Set("actor_id", session.ActorID)
+			c.Set("impersonation_id", session.ID)
+			c.Set("impersonation_write", session.AllowWrite)
+		}
+
+		c.Set("user_id", userID)
+		c.Set("employee_id", principal.EmployeeID)
+		c.Set("department_ids", principal.DepartmentIDs)
 
 		if g, ok := claims["groups"].([]interface{}); ok {
 			strGroups := make([]string, 0, len(g))
@@ -63,3 +109,66 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// authenticateAPIToken пропускает запрос с API-токеном. Группы берутся
+// текущие, а права дополнительно ограничиваются scopes токена (см. services.HasPermission).
+func authenticateAPIToken(c *gin.Context, raw string) {
+	token, err := services.AuthenticateAPIToken(db.DB, raw, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+		c.Abort()
+		return
+	}
+
+	principal, err := services.LoadPrincipal(db.DB, token.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token_revoked", "message": "Учётная запись отключена"})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", principal.UserID)
+	c.Set("employee_id", principal.EmployeeID)
+	c.Set("department_ids", principal.DepartmentIDs)
+	c.Set("groups", principal.Groups)
+	c.Set("api_token_id", token.ID)
+	c.Set("token_scopes", services.APITokenScopes(*token))
+
+	c.Next()
+}
+
+// RequireSession запрещает доступ по API-токену и в режиме имперсонации:
+// управление учётной записью (пароль, 2FA, сами токены) доступно только
+// её владельцу после интерактивного входа.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("api_token_id"); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "session_required", "message": "Действие недоступно по API-токену"})
+			c.Abort()
+			return
+		}
+		if _, ok := c.Get("impersonation_id"); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "session_required", "message": "Действие недоступно при просмотре от имени пользователя"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// BlockImpersonatedWrites оставляет в режиме имперсонации только чтение,
+// если при начале сессии не разрешены изменения.
+func BlockImpersonatedWrites() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("impersonation_id"); ok && !c.GetBool("impersonation_write") {
+			switch c.Request.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				c.JSON(http.StatusForbidden, gin.H{"error": "impersonation_read_only", "message": "В режиме просмотра от имени пользователя изменения запрещены"})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
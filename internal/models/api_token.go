@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// APIToken — долгоживущий токен для скриптов и интеграций.
+// Хранится только SHA-256 хеш; Prefix нужен, чтобы пользователь мог узнать токен в списке.
+type APIToken struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint `gorm:"not null;index"`
+	User   User `gorm:"foreignKey:UserID"`
+
+	Name      string `gorm:"size:100;not null"`
+	Prefix    string `gorm:"size:16;not null"`
+	TokenHash string `gorm:"size:64;unique;not null"`
+	// Scopes — коды прав через запятую; токен не даёт прав сверх них.
+	Scopes string `gorm:"type:text;not null"`
+
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	LastUsedIP string `gorm:"size:64"`
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+type APITokenCreateRequest struct {
+	Name   string   `json:"name" binding:"required" example:"nightly export"`
+	Scopes []string `json:"scopes" example:"work.read_all,dashboard.read_all"`
+	// ExpiresInDays — срок действия; 0 — максимальный допустимый срок.
+	ExpiresInDays int `json:"expires_in_days" example:"90"`
+}
+
+type APITokenResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APITokenCreatedResponse — созданный токен; значение token показывается один раз.
+type APITokenCreatedResponse struct {
+	APITokenResponse
+	Token string `json:"token"`
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Типы дополнительных полей сотрудника.
+const (
+	AttributeString = "string"
+	AttributeNumber = "number"
+	AttributeDate   = "date"
+	AttributeEnum   = "enum"
+)
+
+// CustomAttribute — дополнительное поле карточки сотрудника, заданное администратором
+// (табельный номер, офис, грейд и т.п.). Значения хранятся в EmployeeAttributeValue.
+type CustomAttribute struct {
+	ID uint `gorm:"primaryKey"`
+	// Code — ключ поля в attributes карточки, в фильтрах и в заголовке столбца загрузки.
+	Code     string `gorm:"size:64;not null;uniqueIndex:idx_custom_attributes_code,where:deleted_at IS NULL"`
+	Name     string `gorm:"size:255;not null"`
+	Type     string `gorm:"size:16;not null"`
+	Required bool   `gorm:"not null;default:false"`
+
+	// Pattern — регулярное выражение для строк. Min и Max — границы числа
+	// или длины строки; nil — без ограничения.
+	Pattern string `gorm:"size:255"`
+	Min     *float64
+	Max     *float64
+
+	Options []CustomAttributeOption `gorm:"foreignKey:AttributeID"`
+
+	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index" swaggerignore:"true"`
+}
+
+// CustomAttributeOption — значение справочника поля типа enum. У сотрудника хранится Code;
+// удалённое значение остаётся у тех, кому оно уже назначено, но новым не назначается.
+type CustomAttributeOption struct {
+	ID          uint   `gorm:"primaryKey"`
+	AttributeID uint   `gorm:"not null;uniqueIndex:idx_custom_attribute_options_code,where:deleted_at IS NULL"`
+	Code        string `gorm:"size:64;not null;uniqueIndex:idx_custom_attribute_options_code,where:deleted_at IS NULL"`
+	Name        string `gorm:"size:255;not null"`
+
+	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index" swaggerignore:"true"`
+}
+
+// EmployeeAttributeValue — значение дополнительного поля сотрудника. Value хранится
+// в нормализованном виде: число — десятичной записью, дата — YYYY-MM-DD, enum — кодом значения.
+type EmployeeAttributeValue struct {
+	EmployeeID  uint   `gorm:"primaryKey"`
+	AttributeID uint   `gorm:"primaryKey;index"`
+	Value       string `gorm:"type:text;not null"`
+	UpdatedAt   time.Time
+}
+
+type CustomAttributeRequest struct {
+	Code     string `json:"code" binding:"required" example:"grade"`
+	Name     string `json:"name" binding:"required" example:"Грейд"`
+	Type     string `json:"type" binding:"required" example:"enum" enums:"string,number,date,enum"`
+	Required bool   `json:"required"`
+
+	// Pattern — регулярное выражение для строк.
+	Pattern string `json:"pattern" example:"^[A-Z]{2}-\\d{4}$"`
+	// Min и Max — границы числа или длины строки.
+	Min *float64 `json:"min"`
+	Max *float64 `json:"max"`
+}
+
+type CustomAttributeOptionRequest struct {
+	Code string `json:"code" binding:"required" example:"senior"`
+	Name string `json:"name" binding:"required" example:"Senior"`
+}
+
+type CustomAttributeOptionResponse struct {
+	ID   uint   `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type CustomAttributeResponse struct {
+	ID       uint     `json:"id"`
+	Code     string   `json:"code"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Pattern  string   `json:"pattern"`
+	Min      *float64 `json:"min"`
+	Max      *float64 `json:"max"`
+	// Options — значения справочника для типа enum.
+	Options   []CustomAttributeOptionResponse `json:"options"`
+	CreatedAt time.Time                       `json:"created_at"`
+}
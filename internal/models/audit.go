@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditImport  = "import"
+	AuditApprove = "approve"
+	AuditReject  = "reject"
+
+	AuditTerminate = "terminate"
+	AuditRehire    = "rehire"
+
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+
+	AuditImpersonationStart = "impersonation_start"
+	AuditImpersonationStop  = "impersonation_stop"
+)
+
+type AuditEvent struct {
+	ID uint `gorm:"primaryKey"`
+
+	UserID *uint `gorm:"index"`
+	// ActorID — администратор, действовавший от имени UserID (режим имперсонации).
+	ActorID *uint `gorm:"index"`
+
+	Action     string `gorm:"size:50;not null;index"`
+	EntityType string `gorm:"size:50;not null;index:idx_audit_entity"`
+	EntityID   string `gorm:"size:64;index:idx_audit_entity"`
+
+	Before *string `gorm:"type:jsonb"`
+	After  *string `gorm:"type:jsonb"`
+	Diff   *string `gorm:"type:jsonb"`
+
+	IP        string `gorm:"size:64"`
+	UserAgent string `gorm:"size:512"`
+	Method    string `gorm:"size:10"`
+	Path      string `gorm:"size:512"`
+
+	CreatedAt time.Time `gorm:"index"`
+}
+
+type AuditEventResponse struct {
+	ID         uint            `json:"id"`
+	UserID     *uint           `json:"user_id"`
+	ActorID    *uint           `json:"actor_id"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before" swaggertype:"object"`
+	After      json.RawMessage `json:"after" swaggertype:"object"`
+	Diff       json.RawMessage `json:"diff" swaggertype:"object"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type AuditListResponse struct {
+	Items []AuditEventResponse `json:"items"`
+	Total int64                `json:"total"`
+}
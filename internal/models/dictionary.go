@@ -20,15 +20,28 @@ type Department struct {
 
 type Position struct {
 	BaseDictionary
+
+	// MinSalary и MaxSalary — вилка оклада; nil — без ограничения.
+	MinSalary *float64
+	MaxSalary *float64
 }
 
 type AccessGroup struct {
 	BaseDictionary
 }
 
+// TerminationReason — причина увольнения.
+type TerminationReason struct {
+	BaseDictionary
+}
+
 type DictionaryRequest struct {
 	Name string `json:"name" binding:"required"`
 	Code string `json:"code"`
+
+	// MinSalary и MaxSalary задают вилку оклада; используются только для должностей.
+	MinSalary *float64 `json:"min_salary"`
+	MaxSalary *float64 `json:"max_salary"`
 }
 
 type DictionaryModel interface {
@@ -15,8 +15,15 @@ type Employee struct {
 	LastName   string `gorm:"size:255;not null"`
 	FirstName  string `gorm:"size:255;not null"`
 	MiddleName string `gorm:"size:255"`
+
+	// Version увеличивается при каждом изменении карточки сотрудника, включая
+	// кадровые данные; отдаётся клиенту как ETag для оптимистичной блокировки.
+	Version uint `gorm:"not null;default:1"`
 }
 
+// EmployeeHR — кадровая запись сотрудника, действующая с ValidFrom по ValidTo
+// (не включительно). Перевод, повышение или изменение оклада закрывают текущую
+// запись и открывают новую, поэтому история не теряется. У текущей записи ValidTo = NULL.
 type EmployeeHR struct {
 	ID uint `gorm:"primaryKey"`
 
@@ -26,21 +33,38 @@ type EmployeeHR struct {
 	PositionID uint
 	Position   Position `gorm:"foreignKey:PositionID"`
 
-	EmployeeID uint
+	EmployeeID uint     `gorm:"index;uniqueIndex:idx_employee_hrs_current,where:valid_to IS NULL AND deleted_at IS NULL"`
 	Employee   Employee `gorm:"foreignKey:EmployeeID"`
 
-	IsRemote  bool
-	BirthDate time.Time
+	// ManagerID — линейный руководитель (сотрудник).
+	ManagerID *uint     `gorm:"index"`
+	Manager   *Employee `gorm:"foreignKey:ManagerID"`
+
+	IsRemote bool
+	// BirthDate и Salary хранятся зашифрованными (см. EncryptedDate, EncryptedFloat),
+	// поэтому по ним нельзя фильтровать и сортировать в SQL.
+	BirthDate EncryptedDate
 	HireDate  time.Time
 	FireDate  *time.Time
-	Salary    float64
+	Salary    EncryptedFloat
+
+	// TerminationReasonID заполняется вместе с FireDate при увольнении.
+	TerminationReasonID *uint
+	TerminationReason   *TerminationReason `gorm:"foreignKey:TerminationReasonID"`
+
+	ValidFrom  time.Time  `gorm:"type:date;index"`
+	ValidTo    *time.Time `gorm:"type:date"`
+	ChangeType string     `gorm:"size:32"`
+	Comment    string     `gorm:"size:500"`
 
 	CreatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index" swaggerignore:"true"`
 }
 
 type ProfileUpdateRequest struct {
-	Password string `json:"password" example:"secret123"`
+	Password string `json:"password" example:"N3w-Secret!"`
+	// CurrentPassword обязателен при смене пароля.
+	CurrentPassword string `json:"current_password" example:"0ld-Secret!"`
 
 	LastName   string `json:"last_name" example:"Иванов"`
 	FirstName  string `json:"first_name" example:"Иван"`
@@ -56,25 +80,111 @@ type EmployeeFullResponse struct {
 
 	Department string `json:"department"`
 	Position   string `json:"position"`
+	ManagerID  *uint  `json:"manager_id"`
 
-	IsRemote  bool       `json:"is_remote"`
-	BirthDate time.Time  `json:"birth_date"`
+	IsRemote bool `json:"is_remote"`
+	// BirthDate и Salary равны null, если у пользователя нет права employees.salary.read.
+	BirthDate *time.Time `json:"birth_date"`
 	HireDate  time.Time  `json:"hire_date"`
 	FireDate  *time.Time `json:"fire_date"`
-	Salary    float64    `json:"salary"`
+	Salary    *float64   `json:"salary"`
+
+	// Attributes — дополнительные поля по коду: number — числом, остальные типы — строкой.
+	Attributes map[string]interface{} `json:"attributes"`
 
 	CreatedAt time.Time `json:"created_at"`
+	// Version — версия карточки; передаётся в If-Match при изменении.
+	Version uint `json:"version" example:"3"`
 }
 
-type EmployeeCreateRequest struct {
-	LastName   string `json:"last_name" binding:"required"`
-	FirstName  string `json:"first_name" binding:"required"`
-	MiddleName string `json:"middle_name"`
+type EmployeeListResponse struct {
+	Items []EmployeeFullResponse `json:"items"`
+	Meta  PageMeta               `json:"meta"`
+}
+
+// EmploymentRecordResponse — запись истории работы сотрудника.
+type EmploymentRecordResponse struct {
+	ID           uint       `json:"id"`
+	ChangeType   string     `json:"change_type" example:"transfer"`
+	ValidFrom    time.Time  `json:"valid_from"`
+	ValidTo      *time.Time `json:"valid_to"`
+	DepartmentID uint       `json:"department_id"`
+	Department   string     `json:"department"`
+	PositionID   uint       `json:"position_id"`
+	Position     string     `json:"position"`
+	ManagerID    *uint      `json:"manager_id"`
+	IsRemote     bool       `json:"is_remote"`
+	// Salary равен null без права employees.salary.read.
+	Salary   *float64   `json:"salary"`
+	HireDate time.Time  `json:"hire_date"`
+	FireDate *time.Time `json:"fire_date"`
+	// TerminationReason — название причины увольнения для записи об увольнении.
+	TerminationReason string    `json:"termination_reason,omitempty"`
+	Comment           string    `json:"comment"`
+	CreatedAt         time.Time `json:"created_at"`
+}
 
+// EmployeeTransferRequest — перевод в другой отдел (при необходимости — на другую должность).
+type EmployeeTransferRequest struct {
+	DepartmentID uint `json:"department_id" binding:"required"`
+	PositionID   uint `json:"position_id"`
+	// EffectiveDate — дата вступления в силу (YYYY-MM-DD), по умолчанию сегодня.
+	EffectiveDate string `json:"effective_date" example:"2024-03-01"`
+	Comment       string `json:"comment"`
+}
+
+// EmployeePromotionRequest — назначение на другую должность, при необходимости с новым окладом.
+type EmployeePromotionRequest struct {
+	PositionID    uint     `json:"position_id" binding:"required"`
+	Salary        *float64 `json:"salary"`
+	EffectiveDate string   `json:"effective_date" example:"2024-03-01"`
+	Comment       string   `json:"comment"`
+}
+
+// EmployeeSalaryChangeRequest — изменение оклада.
+type EmployeeSalaryChangeRequest struct {
+	Salary        *float64 `json:"salary" binding:"required"`
+	EffectiveDate string   `json:"effective_date" example:"2024-03-01"`
+	Comment       string   `json:"comment"`
+}
+
+// EmployeeTerminationRequest — увольнение сотрудника.
+type EmployeeTerminationRequest struct {
+	TerminationReasonID uint `json:"termination_reason_id" binding:"required"`
+	// FireDate — дата увольнения (YYYY-MM-DD), по умолчанию сегодня.
+	FireDate string `json:"fire_date" example:"2024-03-01"`
+	Comment  string `json:"comment"`
+}
+
+// EmployeeRehireRequest — повторный приём уволенного сотрудника. Без права
+// employees.salary.read Salary не передаётся: сохраняется оклад до увольнения.
+type EmployeeRehireRequest struct {
 	DepartmentID uint    `json:"department_id" binding:"required"`
 	PositionID   uint    `json:"position_id" binding:"required"`
 	IsRemote     bool    `json:"is_remote"`
-	BirthDate    string  `json:"birth_date" binding:"required"`
-	HireDate     string  `json:"hire_date" binding:"required"`
 	Salary       float64 `json:"salary"`
+	// HireDate — дата повторного приёма (YYYY-MM-DD), по умолчанию сегодня.
+	HireDate string `json:"hire_date" example:"2024-09-01"`
+	Comment  string `json:"comment"`
+}
+
+type EmployeeCreateRequest struct {
+	LastName   string `json:"last_name" binding:"required"`
+	FirstName  string `json:"first_name" binding:"required"`
+	MiddleName string `json:"middle_name"`
+
+	DepartmentID uint   `json:"department_id" binding:"required"`
+	PositionID   uint   `json:"position_id" binding:"required"`
+	IsRemote     bool   `json:"is_remote"`
+	HireDate     string `json:"hire_date" binding:"required"`
+
+	// BirthDate обязательна при создании. Без права employees.salary.read при изменении
+	// BirthDate и Salary можно не передавать: сохраняются текущие значения.
+	BirthDate string  `json:"birth_date"`
+	Salary    float64 `json:"salary"`
+
+	// Attributes — значения дополнительных полей по коду: число, строка или дата YYYY-MM-DD,
+	// для enum — код значения. Заменяют все текущие значения; если поле не передано,
+	// значения не меняются. null или пустая строка очищает значение.
+	Attributes map[string]interface{} `json:"attributes"`
 }
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EncryptedPrefix — признак зашифрованного значения в колонке. Значения без него
+// записаны до включения шифрования и читаются как есть.
+const EncryptedPrefix = "enc:"
+
+// FieldCipher шифрует значения чувствительных полей. Encrypt возвращает строку
+// с префиксом EncryptedPrefix, Decrypt принимает её же.
+type FieldCipher interface {
+	Encrypt(plaintext []byte) (string, error)
+	Decrypt(value string) ([]byte, error)
+}
+
+var fieldCipher FieldCipher
+
+// ErrNoFieldCipher возвращается при записи или чтении зашифрованного поля до SetFieldCipher.
+var ErrNoFieldCipher = errors.New("field cipher is not configured")
+
+// SetFieldCipher задаёт шифр для EncryptedFloat и EncryptedDate.
+func SetFieldCipher(c FieldCipher) {
+	fieldCipher = c
+}
+
+// EncryptedFloat — число, которое хранится в БД зашифрованным.
+type EncryptedFloat float64
+
+func (EncryptedFloat) GormDataType() string { return "text" }
+
+func (f EncryptedFloat) Value() (driver.Value, error) {
+	return encryptField(strconv.FormatFloat(float64(f), 'f', -1, 64))
+}
+
+func (f *EncryptedFloat) Scan(src interface{}) error {
+	s, err := decryptField(src)
+	if err != nil || s == "" {
+		*f = 0
+		return err
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("encrypted float: %w", err)
+	}
+	*f = EncryptedFloat(v)
+	return nil
+}
+
+// EncryptedDate — дата, которая хранится в БД зашифрованной.
+type EncryptedDate struct {
+	time.Time
+}
+
+func (EncryptedDate) GormDataType() string { return "text" }
+
+func (d EncryptedDate) Value() (driver.Value, error) {
+	return encryptField(d.UTC().Format(time.RFC3339))
+}
+
+func (d *EncryptedDate) Scan(src interface{}) error {
+	s, err := decryptField(src)
+	if err != nil || s == "" {
+		d.Time = time.Time{}
+		return err
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return fmt.Errorf("encrypted date: %w", err)
+	}
+	d.Time = t
+	return nil
+}
+
+func encryptField(plaintext string) (driver.Value, error) {
+	if fieldCipher == nil {
+		return nil, ErrNoFieldCipher
+	}
+	return fieldCipher.Encrypt([]byte(plaintext))
+}
+
+func decryptField(src interface{}) (string, error) {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		return "", nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return "", fmt.Errorf("encrypted field: unsupported type %T", src)
+	}
+	if !strings.HasPrefix(s, EncryptedPrefix) {
+		return s, nil
+	}
+	if fieldCipher == nil {
+		return "", ErrNoFieldCipher
+	}
+	plaintext, err := fieldCipher.Decrypt(s)
+	return string(plaintext), err
+}
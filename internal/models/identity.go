@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// UserIdentity связывает пользователя с учётной записью во внешнем
+// провайдере (OIDC): пара issuer + subject однозначно определяет человека у провайдера.
+type UserIdentity struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"not null;index"`
+	User        User   `gorm:"foreignKey:UserID"`
+	Issuer      string `gorm:"size:512;not null;uniqueIndex:idx_identity_subject"`
+	Subject     string `gorm:"size:255;not null;uniqueIndex:idx_identity_subject"`
+	Email       string `gorm:"size:320"`
+	CreatedAt   time.Time
+	LastLoginAt *time.Time
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// ImpersonationSession — просмотр системы администратором от имени пользователя.
+// Токен имперсонации действует, пока сессия не завершена и не истекла.
+type ImpersonationSession struct {
+	ID uint `gorm:"primaryKey"`
+
+	ActorID uint `gorm:"not null;index"`
+	Actor   User `gorm:"foreignKey:ActorID"`
+	UserID  uint `gorm:"not null;index"`
+	User    User `gorm:"foreignKey:UserID"`
+
+	Reason     string `gorm:"size:500;not null"`
+	AllowWrite bool   `gorm:"not null;default:false"`
+
+	ExpiresAt time.Time
+	EndedAt   *time.Time
+	CreatedAt time.Time
+}
+
+type ImpersonationRequest struct {
+	Reason string `json:"reason" binding:"required" example:"Проверка обращения: не отображаются данные за март"`
+	// AllowWrite разрешает изменяющие запросы; по умолчанию доступен только просмотр.
+	AllowWrite bool `json:"allow_write"`
+}
+
+// ImpersonationResponse — access-токен для работы от имени пользователя.
+// Refresh-токен не выдаётся: по истечении срока нужно начать сессию заново.
+type ImpersonationResponse struct {
+	Token      string `json:"token"`
+	ExpiresIn  int    `json:"expires_in"`
+	SessionID  uint   `json:"session_id"`
+	UserID     uint   `json:"user_id"`
+	Login      string `json:"login"`
+	AllowWrite bool   `json:"allow_write"`
+}
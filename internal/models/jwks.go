@@ -0,0 +1,23 @@
+package models
+
+// JWK — открытый ключ проверки токенов в формате JSON Web Key (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty" example:"RSA"`
+	Kid string `json:"kid"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"RS256"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519 (kty = OKP) и EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet — набор открытых ключей, которыми могут быть подписаны токены.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// LoginAttempt — счётчик неудачных попыток входа по ключу
+// ("login:<логин>" или "ip:<адрес>"). Используется, если счётчики
+// должны переживать перезапуск приложения.
+type LoginAttempt struct {
+	Key           string `gorm:"primaryKey;size:320"`
+	Failures      int    `gorm:"not null;default:0"`
+	LastFailureAt time.Time
+	LockedUntil   *time.Time `gorm:"index"`
+	UpdatedAt     time.Time
+}
+
+// LockedAccountResponse — заблокированная после неудачных попыток учётная запись.
+type LockedAccountResponse struct {
+	UserID      *uint     `json:"user_id"`
+	Login       string    `json:"login"`
+	Failures    int       `json:"failures"`
+	LockedUntil time.Time `json:"locked_until"`
+}
@@ -0,0 +1,27 @@
+package models
+
+// OrgEmployee — сотрудник в оргструктуре.
+type OrgEmployee struct {
+	ID         uint   `json:"id"`
+	LastName   string `json:"last_name"`
+	FirstName  string `json:"first_name"`
+	MiddleName string `json:"middle_name"`
+	Department string `json:"department"`
+	Position   string `json:"position"`
+	ManagerID  *uint  `json:"manager_id"`
+	// Depth — уровень подчинения относительно запрошенного руководителя (1 — прямой подчинённый).
+	Depth int `json:"depth,omitempty"`
+}
+
+// OrgChartNode — узел оргструктуры с подчинёнными.
+type OrgChartNode struct {
+	OrgEmployee
+	Reports []*OrgChartNode `json:"reports"`
+}
+
+// EmployeeManagerRequest — назначение линейного руководителя; manager_id = null снимает руководителя.
+type EmployeeManagerRequest struct {
+	ManagerID     *uint  `json:"manager_id"`
+	EffectiveDate string `json:"effective_date" example:"2024-03-01"`
+	Comment       string `json:"comment"`
+}
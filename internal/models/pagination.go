@@ -0,0 +1,9 @@
+package models
+
+// PageMeta — метаданные постраничной выдачи.
+type PageMeta struct {
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+	Pages    int   `json:"pages"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// PasswordHistory — хеши паролей, которые пользователь задавал ранее.
+// Используется, чтобы запретить повторное использование последних паролей.
+type PasswordHistory struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	User      User   `gorm:"foreignKey:UserID"`
+	Hash      string `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// PasswordResetToken — одноразовый токен сброса пароля, выданный администратором.
+// Хранится только SHA-256 хеш.
+type PasswordResetToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	User      User      `gorm:"foreignKey:UserID"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedBy *uint
+	CreatedAt time.Time
+}
+
+// PasswordResetRequest — установка нового пароля по токену из письма.
+type PasswordResetRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required" example:"N3w-Secret!"`
+}
+
+// PasswordResetResponse — результат выдачи токена сброса.
+type PasswordResetResponse struct {
+	Message   string    `json:"message"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LoginPasswordChangeRequest — обязательная смена пароля при входе.
+type LoginPasswordChangeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Password       string `json:"password" binding:"required"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type Permission struct {
+	ID        uint   `gorm:"primaryKey"`
+	Code      string `gorm:"size:100;unique;not null"`
+	Name      string `gorm:"size:255;not null"`
+	CreatedAt time.Time
+}
+
+type AccessGroupPermission struct {
+	AccessGroupID uint        `gorm:"primaryKey"`
+	AccessGroup   AccessGroup `gorm:"foreignKey:AccessGroupID"`
+
+	PermissionID uint       `gorm:"primaryKey"`
+	Permission   Permission `gorm:"foreignKey:PermissionID"`
+
+	CreatedAt time.Time
+}
+
+type PermissionResponse struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type AccessGroupPermissionsRequest struct {
+	Permissions []string `json:"permissions" example:"employees.read_all,work.read_all"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+const (
+	RegistrationPending  = "pending"
+	RegistrationApproved = "approved"
+	RegistrationRejected = "rejected"
+)
+
+type Registration struct {
+	ID       uint   `gorm:"primaryKey"`
+	Login    string `gorm:"size:255;not null;index"`
+	Password string `gorm:"not null"`
+	FullName string `gorm:"size:255"`
+
+	EmployeeID *uint
+	InviteID   *uint
+
+	Status     string `gorm:"size:20;not null;default:pending;index"`
+	ReviewedBy *uint
+	ReviewedAt *time.Time
+
+	CreatedAt time.Time
+}
+
+type RegistrationInvite struct {
+	ID   uint   `gorm:"primaryKey"`
+	Code string `gorm:"size:32;unique;not null"`
+
+	EmployeeID uint     `gorm:"not null"`
+	Employee   Employee `gorm:"foreignKey:EmployeeID"`
+
+	CreatedBy uint
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type RegisterRequest struct {
+	Email      string `json:"email" example:"ivanov@example.com"`
+	Password   string `json:"password" example:"secret123"`
+	Name       string `json:"name" example:"Иван Иванов"`
+	InviteCode string `json:"invite_code" example:"7F3A9C21BD"`
+}
+
+type RegistrationApproveRequest struct {
+	EmployeeID uint `json:"employee_id"`
+}
+
+type RegistrationInviteRequest struct {
+	EmployeeID uint `json:"employee_id" binding:"required"`
+	TTLDays    int  `json:"ttl_days" example:"7"`
+}
+
+type RegistrationResponse struct {
+	ID         uint       `json:"id"`
+	Login      string     `json:"login"`
+	FullName   string     `json:"full_name"`
+	EmployeeID *uint      `json:"employee_id"`
+	Status     string     `json:"status"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
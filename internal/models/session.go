@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// UserSession — сессия входа на одном устройстве. Создаётся при входе,
+// продлевается ротацией refresh-токена; отзыв сессии отзывает её токены.
+type UserSession struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint `gorm:"not null;index"`
+	User   User `gorm:"foreignKey:UserID"`
+
+	UserAgent string `gorm:"size:512"`
+	IP        string `gorm:"size:64"`
+
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	Device     string    `json:"device" example:"Chrome, Windows"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Current — сессия, с которой выполнен запрос.
+	Current bool `json:"current"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// TrashItem — удалённая (soft delete) запись, которую можно восстановить или удалить окончательно.
+type TrashItem struct {
+	Type string `json:"type" example:"employees"`
+	// ID — ID записи; для рабочих данных — ID сотрудника.
+	ID   uint   `json:"id"`
+	Name string `json:"name" example:"Иванов Иван Иванович"`
+	// Count — число удалённых рабочих дней (только для work_days).
+	Count int `json:"count,omitempty"`
+
+	DeletedAt time.Time `json:"deleted_at"`
+	// DeletedBy — пользователь, удаливший запись, по журналу аудита.
+	DeletedBy      *uint  `json:"deleted_by"`
+	DeletedByLogin string `json:"deleted_by_login,omitempty"`
+	// PurgeAt — когда запись будет удалена окончательно; null — автоочистка отключена.
+	PurgeAt *time.Time `json:"purge_at"`
+}
+
+type TrashListResponse struct {
+	Items []TrashItem `json:"items"`
+	// RetentionDays — срок хранения удалённых записей; 0 — автоочистка отключена.
+	RetentionDays int `json:"retention_days"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// RecoveryCode — одноразовый код восстановления для входа без устройства с TOTP.
+// Хранится только SHA-256 хеш.
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	User      User   `gorm:"foreignKey:UserID"`
+	CodeHash  string `gorm:"size:64;not null;uniqueIndex"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// TwoFactorStatusResponse — состояние 2FA текущего пользователя.
+type TwoFactorStatusResponse struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// TwoFactorSetupResponse — секрет для добавления в приложение-аутентификатор.
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorCodeRequest — подтверждение действия кодом из приложения.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableRequest — отключение 2FA требует пароль и действующий код.
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse — новые коды восстановления; показываются один раз.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// LoginChallengeResponse возвращается вместо токенов, если для входа нужен
+// дополнительный шаг. Step: "2fa" — ввести код, "2fa_setup" — подключить 2FA,
+// "password_change" — сменить пароль после сброса.
+type LoginChallengeResponse struct {
+	Step           string `json:"step"`
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int    `json:"expires_in"`
+}
+
+// LoginTwoFactorRequest — второй шаг входа: код TOTP или код восстановления.
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// LoginTwoFactorSetupRequest — начало обязательного подключения 2FA при входе.
+type LoginTwoFactorSetupRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// LoginTwoFactorSetupResponse — токены и коды восстановления после
+// подключения 2FA при входе.
+type LoginTwoFactorSetupResponse struct {
+	TokenResponse
+	RecoveryCodes []string `json:"recovery_codes"`
+}
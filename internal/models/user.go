@@ -14,6 +14,21 @@ type User struct {
 	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime"`
 	DeletedAt  *time.Time `gorm:"column:deleted_at"`
 
+	// TokenVersion увеличивается при смене пароля, отключении или смене групп,
+	// после чего ранее выданные токены перестают приниматься.
+	TokenVersion int `gorm:"column:token_version;not null;default:0"`
+
+	// Двухфакторная аутентификация (TOTP). Секрет сохраняется при начале
+	// подключения, а TOTPEnabled выставляется после подтверждения кодом.
+	TOTPSecret   string `gorm:"column:totp_secret;size:64"`
+	TOTPEnabled  bool   `gorm:"column:totp_enabled;not null;default:false"`
+	TOTPLastStep int64  `gorm:"column:totp_last_step;not null;default:0"`
+
+	// MustChangePassword выставляется при сбросе пароля администратором:
+	// при следующем входе пользователь должен задать новый пароль.
+	MustChangePassword bool       `gorm:"column:must_change_password;not null;default:false"`
+	PasswordChangedAt  *time.Time `gorm:"column:password_changed_at"`
+
 	AccessGroups []UserAccessGroup `gorm:"foreignKey:UserID"`
 }
 
@@ -27,3 +42,79 @@ type UserAccessGroup struct {
 	CreatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
+
+type RefreshToken struct {
+	ID uint `gorm:"primaryKey"`
+
+	UserID uint `gorm:"not null;index"`
+	User   User `gorm:"foreignKey:UserID"`
+
+	// SessionID связывает цепочку ротаций refresh-токена с сессией входа.
+	SessionID *uint `gorm:"index"`
+
+	TokenHash  string `gorm:"size:64;unique;not null"`
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+	ReplacedBy *uint
+
+	CreatedAt time.Time
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+type UserCreateRequest struct {
+	Login      string   `json:"login" binding:"required" example:"ivanov"`
+	Password   string   `json:"password" binding:"required" example:"secret123"`
+	EmployeeID uint     `json:"employee_id" binding:"required"`
+	Groups     []string `json:"groups" example:"employee"`
+}
+
+type UserPasswordRequest struct {
+	Password string `json:"password" binding:"required" example:"secret123"`
+}
+
+type UserGroupRequest struct {
+	Code string `json:"code" binding:"required" example:"manager"`
+}
+
+type UserGroupResponse struct {
+	ID   uint   `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type UserResponse struct {
+	ID           uint                `json:"id"`
+	Login        string              `json:"login"`
+	EmployeeID   uint                `json:"employee_id"`
+	EmployeeName string              `json:"employee_name"`
+	AccessGroups []UserGroupResponse `json:"access_groups"`
+	Disabled     bool                `json:"disabled"`
+	DisabledAt   *time.Time          `json:"disabled_at"`
+	Locked       bool                `json:"locked"`
+	LockedUntil  *time.Time          `json:"locked_until"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
+
+// UserDepartment — отдел, в пределах которого менеджер видит и изменяет данные сотрудников.
+type UserDepartment struct {
+	UserID uint `gorm:"primaryKey"`
+	User   User `gorm:"foreignKey:UserID"`
+
+	DepartmentID uint       `gorm:"primaryKey"`
+	Department   Department `gorm:"foreignKey:DepartmentID"`
+
+	CreatedAt time.Time
+}
+
+type UserDepartmentsRequest struct {
+	DepartmentIDs []uint `json:"department_ids"`
+}
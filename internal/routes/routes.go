@@ -8,35 +8,113 @@ import (
 )
 
 func Register(r *gin.Engine) {
+	r.GET("/.well-known/jwks.json", controllers.JWKS)
+
 	auth := r.Group("/auth")
 	{
 		auth.POST("/login", controllers.Login)
+		auth.POST("/login/password", controllers.LoginPasswordChange)
+		auth.POST("/login/2fa", controllers.LoginTwoFactor)
+		auth.POST("/login/2fa/setup", controllers.LoginTwoFactorSetup)
+		auth.POST("/login/2fa/setup/confirm", controllers.LoginTwoFactorSetupConfirm)
+		auth.POST("/register", controllers.Register)
+		auth.POST("/refresh", controllers.Refresh)
+		auth.POST("/logout", controllers.Logout)
+		auth.POST("/password-reset", controllers.ResetPassword)
+		auth.GET("/oidc/login", controllers.OIDCLogin)
+		auth.GET("/oidc/callback", controllers.OIDCCallback)
 	}
 
 	apiGroup := r.Group("/api")
 	apiGroup.Use(middleware.AuthMiddleware())
+
+	// Завершение имперсонации регистрируется до BlockImpersonatedWrites:
+	// сессию только для чтения тоже нужно уметь закрыть.
+	apiGroup.POST("/impersonation/stop", controllers.StopImpersonation)
+	apiGroup.Use(middleware.BlockImpersonatedWrites())
 	{
 		// Профиль
-		apiGroup.GET("/profile", controllers.GetProfile)
-		apiGroup.PUT("/profile", controllers.UpdateProfile)
+		profile := apiGroup.Group("/profile")
+		profile.Use(middleware.RequireSession())
+		{
+			profile.GET("", controllers.GetProfile)
+			profile.PUT("", controllers.UpdateProfile)
+			profile.POST("/logout-all", controllers.LogoutEverywhere)
+			profile.GET("/2fa", controllers.GetTwoFactorStatus)
+			profile.POST("/2fa/setup", controllers.SetupTwoFactor)
+			profile.POST("/2fa/confirm", controllers.ConfirmTwoFactor)
+			profile.POST("/2fa/recovery-codes", controllers.RegenerateRecoveryCodes)
+			profile.POST("/2fa/disable", controllers.DisableTwoFactor)
+			profile.GET("/tokens", controllers.ListAPITokens)
+			profile.GET("/tokens/scopes", controllers.ListAPITokenScopes)
+			profile.POST("/tokens", controllers.CreateAPIToken)
+			profile.DELETE("/tokens/:id", controllers.RevokeAPIToken)
+			profile.GET("/sessions", controllers.ListSessions)
+			profile.POST("/sessions/revoke-others", controllers.RevokeOtherSessions)
+			profile.DELETE("/sessions/:id", controllers.RevokeSession)
+		}
+
+		// Заявки на регистрацию
+		registrations := apiGroup.Group("/registrations")
+		registrations.Use(services.RequirePermission(services.PermUsersManage))
+		{
+			registrations.GET("", controllers.ListRegistrations)
+			registrations.POST("/invites", controllers.CreateRegistrationInvite)
+			registrations.POST("/:id/approve", controllers.ApproveRegistration)
+			registrations.POST("/:id/reject", controllers.RejectRegistration)
+		}
+
+		// Пользователи
+		users := apiGroup.Group("/users")
+		users.Use(services.RequirePermission(services.PermUsersManage))
+		{
+			users.GET("", controllers.ListUsers)
+			users.POST("", controllers.CreateUser)
+			users.GET("/locked", controllers.ListLockedAccounts)
+			users.DELETE("/:id", controllers.DisableUser)
+			users.POST("/:id/enable", controllers.EnableUser)
+			users.POST("/:id/unlock", controllers.UnlockUser)
+			users.GET("/:id/sessions", controllers.ListUserSessions)
+			users.DELETE("/:id/sessions", controllers.RevokeUserSessions)
+			users.DELETE("/:id/sessions/:sid", controllers.RevokeUserSession)
+			users.POST("/:id/impersonate", middleware.RequireSession(), services.RequirePermission(services.PermUsersImpersonate), controllers.StartImpersonation)
+			users.DELETE("/:id/2fa", controllers.ResetUserTwoFactor)
+			users.PUT("/:id/password", controllers.ResetUserPassword)
+			users.POST("/:id/password-reset", controllers.StartUserPasswordReset)
+			users.POST("/:id/groups", controllers.AddUserGroup)
+			users.DELETE("/:id/groups/:code", controllers.RemoveUserGroup)
+			users.GET("/:id/departments", controllers.GetUserDepartments)
+			users.PUT("/:id/departments", controllers.SetUserDepartments)
+		}
 
 		// Сотрудники
 		employees := apiGroup.Group("/employees")
 		{
-			employees.GET("", services.RequireGroup("admin", "manager", "employee"), controllers.ListEmployees)
-			employees.POST("", services.RequireGroup("admin", "manager"), controllers.CreateEmployee)
-			employees.GET("/:id", services.RequireGroup("admin", "manager", "employee"), controllers.GetEmployeeByID)
-			employees.PUT("/:id", services.RequireGroup("admin", "manager"), controllers.UpdateEmployee)
-			employees.DELETE("/:id", services.RequireGroup("admin", "manager"), controllers.DeleteEmployee)
-			employees.GET("/work", services.RequireGroup("admin", "manager", "employee"), controllers.GetWork)
-			employees.DELETE("/work/:id", services.RequireGroup("admin", "manager"), controllers.DeleteWork)
+			employees.GET("", controllers.ListEmployees)
+			employees.POST("", services.RequirePermission(services.PermEmployeesWrite), controllers.CreateEmployee)
+			employees.GET("/org-chart", controllers.GetOrgChart)
+			employees.GET("/:id", controllers.GetEmployeeByID)
+			employees.PUT("/:id", services.RequirePermission(services.PermEmployeesWrite), controllers.UpdateEmployee)
+			employees.PATCH("/:id", services.RequirePermission(services.PermEmployeesWrite), controllers.PatchEmployee)
+			employees.DELETE("/:id", services.RequirePermission(services.PermEmployeesWrite), controllers.DeleteEmployee)
+			employees.GET("/:id/history", controllers.GetEmploymentHistory)
+			employees.POST("/:id/transfer", services.RequirePermission(services.PermEmployeesWrite), controllers.TransferEmployee)
+			employees.POST("/:id/promotion", services.RequirePermission(services.PermEmployeesWrite), controllers.PromoteEmployee)
+			employees.POST("/:id/salary", services.RequirePermission(services.PermEmployeesWrite), controllers.ChangeEmployeeSalary)
+			employees.POST("/:id/terminate", services.RequirePermission(services.PermEmployeesWrite), controllers.TerminateEmployee)
+			employees.POST("/:id/rehire", services.RequirePermission(services.PermEmployeesWrite), controllers.RehireEmployee)
+			employees.POST("/:id/manager", services.RequirePermission(services.PermEmployeesWrite), controllers.SetEmployeeManager)
+			employees.GET("/:id/reports", controllers.GetDirectReports)
+			employees.GET("/:id/subtree", controllers.GetEmployeeSubtree)
+			employees.GET("/work", controllers.GetWork)
+			employees.DELETE("/work/:employee_id", services.RequirePermission(services.PermWorkDelete), controllers.DeleteWork)
 		}
 
 		// Загрузка данных
 		upload := apiGroup.Group("/upload")
 		{
-			upload.POST("", services.RequireGroup("admin", "manager"), controllers.UploadEmployees)
-			upload.POST("/confirm", services.RequireGroup("admin", "manager"), controllers.ConfirmUpload)
+			upload.POST("", services.RequirePermission(services.PermUploadPreview), controllers.UploadEmployees)
+			upload.POST("/confirm", services.RequirePermission(services.PermUploadConfirm), controllers.ConfirmUpload)
 		}
 
 		// Справочники
@@ -46,34 +124,73 @@ func Register(r *gin.Engine) {
 			departments := dict.Group("/departments")
 			{
 				departments.GET("", controllers.ListDepartments)
-				departments.POST("", services.RequireGroup("admin", "manager"), controllers.CreateDepartment)
-				departments.PUT("/:id", services.RequireGroup("admin", "manager"), controllers.UpdateDepartment)
-				departments.DELETE("/:id", services.RequireGroup("admin", "manager"), controllers.DeleteDepartment)
+				departments.POST("", services.RequirePermission(services.PermDictWrite), controllers.CreateDepartment)
+				departments.PUT("/:id", services.RequirePermission(services.PermDictWrite), controllers.UpdateDepartment)
+				departments.DELETE("/:id", services.RequirePermission(services.PermDictWrite), controllers.DeleteDepartment)
 			}
 
 			// Positions
 			positions := dict.Group("/positions")
 			{
 				positions.GET("", controllers.ListPositions)
-				positions.POST("", services.RequireGroup("admin", "manager"), controllers.CreatePosition)
-				positions.PUT("/:id", services.RequireGroup("admin", "manager"), controllers.UpdatePosition)
-				positions.DELETE("/:id", services.RequireGroup("admin", "manager"), controllers.DeletePosition)
+				positions.POST("", services.RequirePermission(services.PermDictWrite), controllers.CreatePosition)
+				positions.PUT("/:id", services.RequirePermission(services.PermDictWrite), controllers.UpdatePosition)
+				positions.DELETE("/:id", services.RequirePermission(services.PermDictWrite), controllers.DeletePosition)
 			}
 
 			// AccessGroups
 			accessGroups := dict.Group("/access-groups")
 			{
 				accessGroups.GET("", controllers.ListAccessGroups)
-				accessGroups.POST("", services.RequireGroup("admin"), controllers.CreateAccessGroup)
-				accessGroups.PUT("/:id", services.RequireGroup("admin"), controllers.UpdateAccessGroup)
-				accessGroups.DELETE("/:id", services.RequireGroup("admin"), controllers.DeleteAccessGroup)
+				accessGroups.POST("", services.RequirePermission(services.PermDictAccessGroupsWrite), controllers.CreateAccessGroup)
+				accessGroups.PUT("/:id", services.RequirePermission(services.PermDictAccessGroupsWrite), controllers.UpdateAccessGroup)
+				accessGroups.DELETE("/:id", services.RequirePermission(services.PermDictAccessGroupsWrite), controllers.DeleteAccessGroup)
+				accessGroups.GET("/:id/permissions", services.RequirePermission(services.PermDictAccessGroupsWrite), controllers.GetAccessGroupPermissions)
+				accessGroups.PUT("/:id/permissions", services.RequirePermission(services.PermDictAccessGroupsWrite), controllers.SetAccessGroupPermissions)
+			}
+
+			// TerminationReasons
+			terminationReasons := dict.Group("/termination-reasons")
+			{
+				terminationReasons.GET("", controllers.ListTerminationReasons)
+				terminationReasons.POST("", services.RequirePermission(services.PermDictWrite), controllers.CreateTerminationReason)
+				terminationReasons.PUT("/:id", services.RequirePermission(services.PermDictWrite), controllers.UpdateTerminationReason)
+				terminationReasons.DELETE("/:id", services.RequirePermission(services.PermDictWrite), controllers.DeleteTerminationReason)
+			}
+
+			// CustomAttributes
+			attributes := dict.Group("/attributes")
+			{
+				attributes.GET("", controllers.ListCustomAttributes)
+				attributes.POST("", services.RequirePermission(services.PermDictWrite), controllers.CreateCustomAttribute)
+				attributes.PUT("/:id", services.RequirePermission(services.PermDictWrite), controllers.UpdateCustomAttribute)
+				attributes.DELETE("/:id", services.RequirePermission(services.PermDictWrite), controllers.DeleteCustomAttribute)
+				attributes.POST("/:id/options", services.RequirePermission(services.PermDictWrite), controllers.CreateCustomAttributeOption)
+				attributes.PUT("/:id/options/:option_id", services.RequirePermission(services.PermDictWrite), controllers.UpdateCustomAttributeOption)
+				attributes.DELETE("/:id/options/:option_id", services.RequirePermission(services.PermDictWrite), controllers.DeleteCustomAttributeOption)
 			}
 		}
 
+		// Журнал аудита
+		apiGroup.GET("/audit", services.RequirePermission(services.PermAuditRead), controllers.ListAuditEvents)
+
+		// Корзина
+		trash := apiGroup.Group("/trash")
+		trash.Use(services.RequirePermission(services.PermTrashManage))
+		{
+			trash.GET("", controllers.ListTrash)
+			trash.POST("/:type/:id/restore", controllers.RestoreTrashItem)
+			trash.DELETE("/:type/:id", controllers.PurgeTrashItem)
+		}
+
+		// Права доступа
+		apiGroup.GET("/permissions", services.RequirePermission(services.PermDictAccessGroupsWrite), controllers.ListPermissions)
+
 		// Dashboard
 		dashboard := apiGroup.Group("/dashboard")
 		{
 			dashboard.GET("/summary", controllers.DashboardSummary)
+			dashboard.GET("/turnover", controllers.DashboardTurnover)
 		}
 	}
 }
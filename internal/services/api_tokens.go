@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+// APITokenPrefix отличает API-токены от JWT в заголовке Authorization
+// и упрощает поиск утёкших токенов сканерами секретов.
+const APITokenPrefix = "dft_"
+
+// apiTokenTouchInterval ограничивает частоту записи last_used_at,
+// чтобы скрипт с частыми запросами не обновлял строку на каждый запрос.
+const apiTokenTouchInterval = time.Minute
+
+var (
+	ErrAPITokenInvalid = errors.New("api token invalid, expired or revoked")
+	ErrAPITokenScope   = errors.New("api token scope not allowed")
+)
+
+var apiTokenMaxTTL = 365 * 24 * time.Hour
+
+// InitAPITokens задаёт максимальный срок действия API-токена.
+func InitAPITokens(maxTTL time.Duration) {
+	apiTokenMaxTTL = maxTTL
+}
+
+func APITokenMaxTTL() time.Duration {
+	return apiTokenMaxTTL
+}
+
+// AvailableScopes возвращает права из каталога, которые группы дают сейчас;
+// только их можно передать API-токену.
+func AvailableScopes(groups []string) ([]string, error) {
+	var result []string
+	for _, def := range PermissionCatalog {
+		ok, err := GroupsHavePermission(groups, def.Code)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, def.Code)
+		}
+	}
+	return result, nil
+}
+
+// CreateAPIToken создаёт токен с правами scopes. Права должны входить
+// в права групп владельца. Возвращает запись и значение токена, которое
+// больше нигде не сохраняется.
+func CreateAPIToken(tx *gorm.DB, userID uint, groups []string, name string, scopes []string, ttl time.Duration) (*models.APIToken, string, error) {
+	available, err := AvailableScopes(groups)
+	if err != nil {
+		return nil, "", err
+	}
+	var unique []string
+	for _, scope := range scopes {
+		if !containsString(available, scope) {
+			return nil, "", fmt.Errorf("%w: %s", ErrAPITokenScope, scope)
+		}
+		if !containsString(unique, scope) {
+			unique = append(unique, scope)
+		}
+	}
+
+	if ttl <= 0 || ttl > apiTokenMaxTTL {
+		ttl = apiTokenMaxTTL
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	secret, err := RandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	raw := APITokenPrefix + secret
+
+	token := models.APIToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    raw[:len(APITokenPrefix)+6],
+		TokenHash: HashToken(raw),
+		Scopes:    strings.Join(unique, ","),
+		ExpiresAt: &expiresAt,
+	}
+	if err := tx.Create(&token).Error; err != nil {
+		return nil, "", err
+	}
+	return &token, raw, nil
+}
+
+// AuthenticateAPIToken находит действующий токен по значению
+// и отмечает время его использования.
+func AuthenticateAPIToken(conn *gorm.DB, raw, ip string) (*models.APIToken, error) {
+	if !strings.HasPrefix(raw, APITokenPrefix) {
+		return nil, ErrAPITokenInvalid
+	}
+
+	var token models.APIToken
+	if err := conn.Where("token_hash = ?", HashToken(raw)).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPITokenInvalid
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil || (token.ExpiresAt != nil && now.After(*token.ExpiresAt)) {
+		return nil, ErrAPITokenInvalid
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > apiTokenTouchInterval || token.LastUsedIP != ip {
+		if err := conn.Model(&token).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &token, nil
+}
+
+// RevokeAPIToken отзывает токен пользователя. Возвращает gorm.ErrRecordNotFound,
+// если токена нет или он уже отозван.
+func RevokeAPIToken(tx *gorm.DB, userID, tokenID uint) error {
+	res := tx.Model(&models.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// APITokenScopes разбирает сохранённый список прав токена.
+func APITokenScopes(token models.APIToken) []string {
+	if token.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(token.Scopes, ",")
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxAttributeValueLength — максимальная длина строкового значения дополнительного поля.
+const MaxAttributeValueLength = 1000
+
+var ErrInvalidAttributeValue = errors.New("invalid attribute value")
+
+// Код поля используется в параметрах attr.<код> и заголовках столбцов загрузки,
+// поэтому точки и пробелы в нём не допускаются.
+var attributeCodeRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// LoadAttributes возвращает действующие дополнительные поля с действующими значениями справочников.
+func LoadAttributes(conn *gorm.DB) ([]models.CustomAttribute, error) {
+	var attrs []models.CustomAttribute
+	err := conn.
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("name, id") }).
+		Order("name, id").
+		Find(&attrs).Error
+	return attrs, err
+}
+
+// ValidateAttributeDefinition проверяет описание дополнительного поля.
+func ValidateAttributeDefinition(input models.CustomAttributeRequest) error {
+	v := &ValidationError{}
+
+	if !attributeCodeRe.MatchString(input.Code) {
+		v.Add("code", "invalid", "Код — латинские строчные буквы, цифры и _, начинается с буквы, до 64 символов")
+	}
+	checkName(v, "name", input.Name, true, "Укажите название")
+
+	switch input.Type {
+	case models.AttributeString, models.AttributeNumber, models.AttributeDate, models.AttributeEnum:
+	default:
+		v.Add("type", "invalid", "Тип поля: string, number, date или enum")
+	}
+
+	if input.Pattern != "" {
+		if input.Type != models.AttributeString {
+			v.Add("pattern", "not_applicable", "Шаблон задаётся только для строковых полей")
+		} else if _, err := regexp.Compile(input.Pattern); err != nil {
+			v.Add("pattern", "invalid", "Неверное регулярное выражение")
+		}
+	}
+
+	if input.Min != nil || input.Max != nil {
+		if input.Type != models.AttributeString && input.Type != models.AttributeNumber {
+			v.Add("min", "not_applicable", "Границы задаются только для числовых и строковых полей")
+		} else if input.Min != nil && input.Max != nil && *input.Min > *input.Max {
+			v.Add("max", "less_than_min", "Максимум меньше минимума")
+		}
+	}
+
+	return v.Err()
+}
+
+// ValidateAttributeOption проверяет значение справочника поля типа enum.
+func ValidateAttributeOption(input models.CustomAttributeOptionRequest) error {
+	v := &ValidationError{}
+	if !attributeCodeRe.MatchString(input.Code) {
+		v.Add("code", "invalid", "Код — латинские строчные буквы, цифры и _, начинается с буквы, до 64 символов")
+	}
+	checkName(v, "name", input.Name, true, "Укажите название")
+	return v.Err()
+}
+
+// CheckAttributes проверяет значения дополнительных полей values (код → значение) по
+// описаниям attrs и возвращает их в виде для хранения по ID поля. null и пустая строка
+// означают, что значение не задано. Если requireAll, незаданные обязательные поля —
+// тоже ошибка. Поля в ошибках называются attributes.<код>.
+func CheckAttributes(attrs []models.CustomAttribute, values map[string]interface{}, requireAll bool) (map[uint]string, error) {
+	v := &ValidationError{}
+	result := checkAttributes(attrs, v, values, requireAll)
+	return result, v.Err()
+}
+
+func checkAttributes(attrs []models.CustomAttribute, v *ValidationError, values map[string]interface{}, requireAll bool) map[uint]string {
+	byCode := make(map[string]models.CustomAttribute, len(attrs))
+	for _, a := range attrs {
+		byCode[a.Code] = a
+	}
+
+	codes := make([]string, 0, len(values))
+	for code := range values {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	result := map[uint]string{}
+	failed := map[string]bool{}
+	for _, code := range codes {
+		field := "attributes." + code
+		attr, ok := byCode[code]
+		if !ok {
+			v.Add(field, "unknown", "Неизвестное поле")
+			continue
+		}
+		value, errCode, message := normalizeAttributeValue(attr, values[code])
+		if errCode != "" {
+			v.Add(field, errCode, message)
+			failed[code] = true
+			continue
+		}
+		if value != "" {
+			result[attr.ID] = value
+		}
+	}
+
+	if requireAll {
+		for _, a := range attrs {
+			if _, ok := result[a.ID]; a.Required && !ok && !failed[a.Code] {
+				v.Add("attributes."+a.Code, "required", "Обязательное поле «"+a.Name+"»")
+			}
+		}
+	}
+	return result
+}
+
+// normalizeAttributeValue приводит значение к виду для хранения и проверяет ограничения поля.
+// Пустой результат без кода ошибки — значение не задано.
+func normalizeAttributeValue(attr models.CustomAttribute, raw interface{}) (value, errCode, message string) {
+	var s string
+	switch x := raw.(type) {
+	case nil:
+		return "", "", ""
+	case string:
+		s = strings.TrimSpace(x)
+	case float64:
+		if attr.Type != models.AttributeNumber {
+			return "", "invalid_type", "Ожидается строка"
+		}
+		s = strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		return "", "invalid_type", "Неверный тип значения"
+	}
+	if s == "" {
+		return "", "", ""
+	}
+
+	value, err := parseAttributeValue(attr, s)
+	if err != nil {
+		return "", "invalid", attributeFormatMessage(attr.Type)
+	}
+
+	switch attr.Type {
+	case models.AttributeNumber:
+		n, _ := strconv.ParseFloat(value, 64)
+		if (attr.Min != nil && n < *attr.Min) || (attr.Max != nil && n > *attr.Max) {
+			return "", "out_of_range", "Значение должно быть " + attributeRange(attr)
+		}
+	case models.AttributeString:
+		length := float64(utf8.RuneCountInString(value))
+		switch {
+		case length > MaxAttributeValueLength:
+			return "", "too_long", fmt.Sprintf("Не больше %d символов", MaxAttributeValueLength)
+		case (attr.Min != nil && length < *attr.Min) || (attr.Max != nil && length > *attr.Max):
+			return "", "out_of_range", "Длина должна быть " + attributeRange(attr)
+		}
+		if attr.Pattern != "" {
+			re, err := regexp.Compile(attr.Pattern)
+			if err == nil && !re.MatchString(value) {
+				return "", "pattern_mismatch", "Значение не соответствует формату поля «" + attr.Name + "»"
+			}
+		}
+	case models.AttributeEnum:
+		for _, o := range attr.Options {
+			if o.Code == value || strings.EqualFold(o.Name, value) {
+				return o.Code, "", ""
+			}
+		}
+		return "", "not_found", "Нет такого значения в справочнике поля «" + attr.Name + "»"
+	}
+	return value, "", ""
+}
+
+// parseAttributeValue разбирает непустое значение s поля attr без проверки ограничений.
+// Число может быть записано с десятичной запятой; для enum s возвращается как есть.
+func parseAttributeValue(attr models.CustomAttribute, s string) (string, error) {
+	switch attr.Type {
+	case models.AttributeNumber:
+		n, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return "", ErrInvalidAttributeValue
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case models.AttributeDate:
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return "", ErrInvalidAttributeValue
+		}
+		return d.Format("2006-01-02"), nil
+	}
+	return s, nil
+}
+
+// ParseAttributeFilter разбирает значение фильтра по полю attr в вид для хранения.
+func ParseAttributeFilter(attr models.CustomAttribute, s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", ErrInvalidAttributeValue
+	}
+	return parseAttributeValue(attr, s)
+}
+
+// SaveEmployeeAttributes записывает значения дополнительных полей сотрудника (ID поля → значение).
+// При replace значения остальных действующих полей удаляются; значения удалённых полей
+// сохраняются, чтобы вернуться вместе с полем из корзины.
+func SaveEmployeeAttributes(tx *gorm.DB, employeeID uint, values map[uint]string, replace bool) error {
+	ids := make([]uint, 0, len(values))
+	for id := range values {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	if replace {
+		query := tx.Where("employee_id = ? AND attribute_id IN (SELECT id FROM custom_attributes WHERE deleted_at IS NULL)", employeeID)
+		if len(ids) > 0 {
+			query = query.Where("attribute_id NOT IN ?", ids)
+		}
+		if err := query.Delete(&models.EmployeeAttributeValue{}).Error; err != nil {
+			return err
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows := make([]models.EmployeeAttributeValue, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, models.EmployeeAttributeValue{EmployeeID: employeeID, AttributeID: id, Value: values[id]})
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "employee_id"}, {Name: "attribute_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&rows).Error
+}
+
+// EmployeeAttributes возвращает значения действующих дополнительных полей сотрудников:
+// ID сотрудника → код поля → значение (number — числом, остальные типы — строкой).
+func EmployeeAttributes(conn *gorm.DB, employeeIDs []uint) (map[uint]map[string]interface{}, error) {
+	result := make(map[uint]map[string]interface{}, len(employeeIDs))
+	if len(employeeIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		EmployeeID uint
+		Code       string
+		Type       string
+		Value      string
+	}
+	if err := conn.Table("employee_attribute_values v").
+		Select("v.employee_id, a.code, a.type, v.value").
+		Joins("JOIN custom_attributes a ON a.id = v.attribute_id AND a.deleted_at IS NULL").
+		Where("v.employee_id IN ?", employeeIDs).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, r := range rows {
+		if result[r.EmployeeID] == nil {
+			result[r.EmployeeID] = map[string]interface{}{}
+		}
+		result[r.EmployeeID][r.Code] = attributeValue(r.Type, r.Value)
+	}
+	return result, nil
+}
+
+func attributeValue(attrType, value string) interface{} {
+	if attrType == models.AttributeNumber {
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
+
+func attributeFormatMessage(attrType string) string {
+	switch attrType {
+	case models.AttributeNumber:
+		return "Ожидается число"
+	case models.AttributeDate:
+		return "Дата должна быть в формате ГГГГ-ММ-ДД"
+	}
+	return "Неверное значение"
+}
+
+func attributeRange(attr models.CustomAttribute) string {
+	format := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	switch {
+	case attr.Min != nil && attr.Max != nil:
+		return "от " + format(*attr.Min) + " до " + format(*attr.Max)
+	case attr.Min != nil:
+		return "не меньше " + format(*attr.Min)
+	case attr.Max != nil:
+		return "не больше " + format(*attr.Max)
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FieldChange — изменение одного поля сущности.
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Audit записывает событие аудита в той же транзакции, что и само изменение.
+// before/after — состояние сущности до и после (nil при создании/удалении);
+// они сериализуются в JSON, по ним же строится diff изменённых полей.
+// c может быть nil для фоновых операций.
+func Audit(tx *gorm.DB, c *gin.Context, action, entityType string, entityID interface{}, before, after interface{}) error {
+	event := models.AuditEvent{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   fmt.Sprint(entityID),
+	}
+
+	beforeMap, err := toAuditMap(before)
+	if err != nil {
+		return err
+	}
+	afterMap, err := toAuditMap(after)
+	if err != nil {
+		return err
+	}
+
+	if event.Before, err = auditJSON(beforeMap); err != nil {
+		return err
+	}
+	if event.After, err = auditJSON(afterMap); err != nil {
+		return err
+	}
+	if diff := auditDiff(beforeMap, afterMap); len(diff) > 0 {
+		if event.Diff, err = auditJSON(diff); err != nil {
+			return err
+		}
+	}
+
+	if c != nil {
+		if id := c.GetUint("user_id"); id != 0 {
+			event.UserID = &id
+		}
+		if id := c.GetUint("actor_id"); id != 0 {
+			event.ActorID = &id
+		}
+		event.IP = c.ClientIP()
+		event.UserAgent = truncate(c.Request.UserAgent(), 512)
+		event.Method = c.Request.Method
+		event.Path = truncate(c.Request.URL.Path, 512)
+	}
+
+	return tx.Create(&event).Error
+}
+
+func toAuditMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func auditJSON(v interface{}) (*string, error) {
+	if v == nil || reflect.ValueOf(v).IsNil() {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := string(raw)
+	return &s, nil
+}
+
+func auditDiff(before, after map[string]interface{}) map[string]FieldChange {
+	diff := make(map[string]FieldChange)
+	for k, a := range after {
+		if b, ok := before[k]; !ok || !reflect.DeepEqual(a, b) {
+			diff[k] = FieldChange{From: before[k], To: a}
+		}
+	}
+	for k, b := range before {
+		if _, ok := after[k]; !ok {
+			diff[k] = FieldChange{From: b, To: nil}
+		}
+	}
+	return diff
+}
+
+// truncate обрезает s до n символов. Недопустимые в UTF-8 байты удаляются:
+// иначе Postgres отклонит строку целиком.
+func truncate(s string, n int) string {
+	s = strings.ToValidUTF8(s, "")
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
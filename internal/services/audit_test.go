@@ -0,0 +1,22 @@
+package services
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"Mozilla/5.0", 512, "Mozilla/5.0"},
+		{"Иванов", 6, "Иванов"},
+		{"Иванов", 3, "Ива"},
+		{"日本語テキスト", 2, "日本"},
+		{"ok\xff\xfe", 10, "ok"},
+	}
+	for _, tc := range cases {
+		if got := truncate(tc.in, tc.n); got != tc.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tc.in, tc.n, got, tc.want)
+		}
+	}
+}
@@ -1,13 +1,10 @@
 package services
 
 import (
-	"net/http"
-	"os"
+	"sync"
 	"time"
 
-	"github.com/MarBalueva/dashboard_efficiency/internal/db"
 	"github.com/MarBalueva/dashboard_efficiency/internal/models"
-	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -21,66 +18,41 @@ func CheckPassword(pass, hash string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(pass)) == nil
 }
 
-func GenerateJWT(userID uint, groups []string) (string, error) {
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// CheckDummyPassword выполняет сравнение с фиктивным хешем, чтобы ответ
+// для несуществующего логина занимал столько же времени, сколько и для
+// существующего.
+func CheckDummyPassword(pass string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), 12)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(pass))
+}
+
+func GenerateJWT(userID uint, groups []string, tokenVersion int, sessionID uint) (string, error) {
 	claims := jwt.MapClaims{
+		"typ":     TokenTypeAccess,
+		"aud":     AccessTokenAudience,
 		"user_id": userID,
 		"groups":  groups,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+		"ver":     tokenVersion,
+		"sid":     sessionID,
+		"exp":     time.Now().Add(AccessTokenTTL).Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
-}
-
-func RequireGroup(allowedGroups ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userIDVal, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id_not_found"})
-			c.Abort()
-			return
-		}
-
-		userID, ok := userIDVal.(uint)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_user_id"})
-			c.Abort()
-			return
-		}
-
-		var user models.User
-		if err := db.DB.
-			Preload("AccessGroups.AccessGroup").
-			First(&user, userID).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user_not_found"})
-			c.Abort()
-			return
-		}
-
-		for _, ag := range user.AccessGroups {
-			for _, group := range allowedGroups {
-				if ag.AccessGroup.Code == group {
-					c.Next()
-					return
-				}
-			}
-		}
-
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":   "forbidden",
-			"message": "Доступ запрещён",
-		})
-		c.Abort()
-	}
+	return SignToken(claims)
 }
 
-func HasAnyGroup(user models.User, groups ...string) bool {
+// GroupClaims возвращает список групп пользователя для claim "groups".
+// Пользователь должен быть загружен с Preload("AccessGroups.AccessGroup").
+func GroupClaims(user models.User) []string {
+	groups := make([]string, 0, len(user.AccessGroups))
 	for _, ug := range user.AccessGroups {
-		for _, g := range groups {
-			if ug.AccessGroup.Code == g {
-				return true
-			}
-		}
+		groups = append(groups, ug.AccessGroup.Code)
 	}
-	return false
+	return groups
 }
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidCredentials — провайдер знает пользователя, но пароль неверный
+	// (или учётная запись отключена).
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrUnknownUser — провайдер не обслуживает этого пользователя; проверка
+	// передаётся следующему провайдеру.
+	ErrUnknownUser = errors.New("user unknown to auth provider")
+	// ErrProviderUnavailable — провайдер временно не может проверить пароль
+	// (например, каталог недоступен).
+	ErrProviderUnavailable = errors.New("auth provider unavailable")
+)
+
+const LocalProviderName = "local"
+
+// AuthProvider проверяет логин и пароль и возвращает локального пользователя
+// с загруженными группами доступа.
+type AuthProvider interface {
+	Name() string
+	Authenticate(ctx context.Context, conn *gorm.DB, login, password string) (models.User, error)
+}
+
+var authProviders = []AuthProvider{&LocalProvider{}}
+
+// InitAuthProviders задаёт провайдеров в порядке проверки.
+func InitAuthProviders(providers ...AuthProvider) {
+	authProviders = providers
+}
+
+// Authenticate проверяет пароль у провайдеров по очереди и возвращает
+// пользователя и имя провайдера, подтвердившего пароль.
+//
+// Если ни один провайдер не подтвердил пароль, возвращается
+// ErrInvalidCredentials; ErrProviderUnavailable — только когда пароль никто
+// не отверг, но хотя бы один провайдер был недоступен. Так при сбое каталога
+// обычные пользователи не блокируются за неудачные попытки, а неверный пароль
+// резервной локальной учётной записи по-прежнему учитывается.
+func Authenticate(ctx context.Context, conn *gorm.DB, login, password string) (models.User, string, error) {
+	var rejected, unavailable bool
+	for _, p := range authProviders {
+		user, err := p.Authenticate(ctx, conn, login, password)
+		switch {
+		case err == nil:
+			return user, p.Name(), nil
+		case errors.Is(err, ErrInvalidCredentials):
+			rejected = true
+		case errors.Is(err, ErrUnknownUser):
+		case errors.Is(err, ErrProviderUnavailable):
+			unavailable = true
+		default:
+			return models.User{}, "", err
+		}
+	}
+	if unavailable && !rejected {
+		return models.User{}, "", ErrProviderUnavailable
+	}
+	return models.User{}, "", ErrInvalidCredentials
+}
+
+// LocalProvider проверяет пароль по bcrypt-хешу в таблице users.
+// Если AllowedGroups не пуст, локальный вход разрешён только участникам
+// этих групп — например, резервным администраторам при входе через LDAP.
+type LocalProvider struct {
+	AllowedGroups []string
+}
+
+func (p *LocalProvider) Name() string {
+	return LocalProviderName
+}
+
+func (p *LocalProvider) Authenticate(ctx context.Context, conn *gorm.DB, login, password string) (models.User, error) {
+	var user models.User
+	err := conn.WithContext(ctx).Preload("AccessGroups.AccessGroup").
+		Where("login = ? AND deleted_at IS NULL", login).
+		First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, err
+	}
+
+	// Для несуществующего логина пароль всё равно проверяется,
+	// чтобы по времени ответа нельзя было отличить его от неверного пароля.
+	if err != nil || !p.allowed(user) {
+		CheckDummyPassword(password)
+		return models.User{}, ErrUnknownUser
+	}
+	// Пароль сброшен по email: войти можно только после установки нового по ссылке.
+	if user.Password == ResetPendingPassword {
+		CheckDummyPassword(password)
+		return models.User{}, ErrInvalidCredentials
+	}
+	if !CheckPassword(password, user.Password) {
+		return models.User{}, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+func (p *LocalProvider) allowed(user models.User) bool {
+	if len(p.AllowedGroups) == 0 {
+		return true
+	}
+	for _, code := range GroupClaims(user) {
+		for _, allowed := range p.AllowedGroups {
+			if strings.EqualFold(code, allowed) {
+				return true
+			}
+		}
+	}
+	return false
+}
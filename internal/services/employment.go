@@ -0,0 +1,166 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Типы изменений в истории работы сотрудника.
+const (
+	EmploymentHire      = "hire"
+	EmploymentTransfer  = "transfer"
+	EmploymentPromotion = "promotion"
+	EmploymentSalary    = "salary"
+	EmploymentUpdate    = "update"
+	EmploymentTerminate = "termination"
+	EmploymentRehire    = "rehire"
+	EmploymentManager   = "manager"
+)
+
+var (
+	ErrEffectiveDateTooEarly = errors.New("effective date precedes the current employment record")
+	ErrEffectiveDateInFuture = errors.New("effective date is in the future")
+	ErrEmployeeTerminated    = errors.New("employee is terminated")
+	ErrEmployeeNotTerminated = errors.New("employee is not terminated")
+)
+
+// EffectiveHR — условие соединения кадровой записи hrAlias с моментом времени
+// timeExpr: запись должна действовать на этот момент.
+func EffectiveHR(hrAlias, timeExpr string) string {
+	return hrAlias + ".valid_from <= " + timeExpr +
+		" AND (" + hrAlias + ".valid_to IS NULL OR " + timeExpr + " < " + hrAlias + ".valid_to)"
+}
+
+// CurrentEmployment возвращает действующую кадровую запись сотрудника.
+func CurrentEmployment(conn *gorm.DB, employeeID uint) (models.EmployeeHR, error) {
+	var hr models.EmployeeHR
+	err := conn.Where("employee_id = ? AND valid_to IS NULL", employeeID).First(&hr).Error
+	return hr, err
+}
+
+// EmploymentHistory возвращает записи сотрудника от новых к старым.
+func EmploymentHistory(conn *gorm.DB, employeeID uint) ([]models.EmployeeHR, error) {
+	var records []models.EmployeeHR
+	err := conn.Preload("Department").Preload("Position").
+		Preload("TerminationReason", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("employee_id = ?", employeeID).
+		Order("valid_from DESC, id DESC").
+		Find(&records).Error
+	return records, err
+}
+
+// TerminateEmployment увольняет сотрудника с даты fireDate: с этой даты действует
+// запись с FireDate и причиной увольнения. Связанная учётная запись отключается,
+// её токены и сессии отзываются. Возвращает ID отключённого пользователя (0 — не было).
+func TerminateEmployment(tx *gorm.DB, employeeID uint, fireDate time.Time, reasonID uint, comment string) (uint, error) {
+	fireDate = truncateDay(fireDate)
+	if _, _, err := ChangeEmployment(tx, employeeID, EmploymentTerminate, fireDate, comment, func(hr *models.EmployeeHR) {
+		hr.FireDate = &fireDate
+		hr.TerminationReasonID = &reasonID
+	}); err != nil {
+		return 0, err
+	}
+
+	var user models.User
+	err := tx.Where("employee_id = ? AND deleted_at IS NULL", employeeID).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&user).Update("deleted_at", time.Now()).Error; err != nil {
+		return 0, err
+	}
+	return user.ID, RevokeUserTokens(tx, user.ID)
+}
+
+// RehireEmployment принимает уволенного сотрудника повторно с даты hireDate,
+// которая должна быть позже даты увольнения. Учётная запись не включается
+// автоматически — это делает администратор.
+func RehireEmployment(tx *gorm.DB, employeeID uint, hireDate time.Time, comment string, apply func(*models.EmployeeHR)) error {
+	hireDate = truncateDay(hireDate)
+	_, _, err := ChangeEmployment(tx, employeeID, EmploymentRehire, hireDate, comment, func(hr *models.EmployeeHR) {
+		apply(hr)
+		hr.HireDate = hireDate
+		hr.FireDate = nil
+		hr.TerminationReasonID = nil
+	})
+	return err
+}
+
+// ChangeEmployment применяет apply к текущей записи сотрудника с даты effective:
+// текущая запись закрывается этой датой, а изменённая копия становится новой текущей.
+// Если текущая запись начинается в тот же день, она исправляется на месте.
+// Новая запись не может начинаться в будущем. У уволенного сотрудника
+// допускается только повторный приём.
+// Возвращает запись до изменения и новую текущую запись.
+func ChangeEmployment(tx *gorm.DB, employeeID uint, changeType string, effective time.Time, comment string, apply func(*models.EmployeeHR)) (before, after models.EmployeeHR, err error) {
+	effective = truncateDay(effective)
+	if err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("employee_id = ? AND valid_to IS NULL", employeeID).
+		First(&before).Error; err != nil {
+		return before, after, err
+	}
+
+	terminated := before.FireDate != nil
+	if terminated && changeType != EmploymentRehire {
+		return before, after, ErrEmployeeTerminated
+	}
+	if !terminated && changeType == EmploymentRehire {
+		return before, after, ErrEmployeeNotTerminated
+	}
+
+	validFrom := truncateDay(before.ValidFrom)
+	if effective.Before(validFrom) || (terminated && effective.Equal(validFrom)) {
+		return before, after, ErrEffectiveDateTooEarly
+	}
+	if effective.After(validFrom) && effective.After(truncateDay(time.Now())) {
+		return before, after, ErrEffectiveDateInFuture
+	}
+
+	after = before
+	apply(&after)
+	after.ChangeType = changeType
+	after.Comment = comment
+	if err = ValidateAssignment(tx, before, after); err != nil {
+		return before, after, err
+	}
+
+	if effective.Equal(validFrom) {
+		// Приём на работу остаётся приёмом, даже если его условия исправили в тот же день.
+		if before.ChangeType == EmploymentHire || before.ChangeType == EmploymentRehire {
+			after.ChangeType = before.ChangeType
+		}
+		err = tx.Model(&after).
+			Select("department_id", "position_id", "manager_id", "is_remote", "salary", "hire_date", "fire_date",
+				"termination_reason_id", "change_type", "comment").
+			Updates(&after).Error
+		return before, after, err
+	}
+
+	if err = tx.Model(&before).Update("valid_to", effective).Error; err != nil {
+		return before, after, err
+	}
+
+	after.ID = 0
+	after.ValidFrom = effective
+	after.ValidTo = nil
+	after.CreatedAt = time.Time{}
+	after.Department = models.Department{}
+	after.Position = models.Position{}
+	after.Employee = models.Employee{}
+	after.TerminationReason = nil
+	after.Manager = nil
+	err = tx.Create(&after).Error
+	return before, after, err
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
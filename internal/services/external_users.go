@@ -0,0 +1,114 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+// Общие операции для пользователей внешних провайдеров (OIDC, LDAP).
+
+// NoLocalPassword — значение пароля учётных записей, созданных при входе
+// через внешний провайдер. Не совпадает ни с одним bcrypt-хешем, поэтому вход
+// по локальному паролю для них невозможен, пока пароль не задан явно.
+const NoLocalPassword = "!"
+
+// provisionExternalUser создаёт сотрудника и пользователя без локального пароля.
+func provisionExternalUser(tx *gorm.DB, login, lastName, firstName string, groups []string) (models.User, error) {
+	if len(groups) == 0 {
+		groups = []string{DefaultUserGroup}
+	}
+	if lastName == "" && firstName == "" {
+		lastName = login
+	}
+
+	employee := models.Employee{LastName: lastName, FirstName: firstName}
+	if err := tx.Create(&employee).Error; err != nil {
+		return models.User{}, err
+	}
+
+	user, err := CreateUser(tx, login, NoLocalPassword, employee.ID, groups...)
+	if err != nil {
+		return models.User{}, err
+	}
+	return *user, nil
+}
+
+// splitDisplayName делит отображаемое имя на фамилию и имя. Провайдеры обычно
+// передают его в порядке «Имя Фамилия».
+func splitDisplayName(name string) (lastName, firstName string) {
+	parts := strings.Fields(name)
+	switch len(parts) {
+	case 0:
+		return "", ""
+	case 1:
+		return parts[0], ""
+	}
+	return strings.Join(parts[1:], " "), parts[0]
+}
+
+// syncExternalGroups приводит группы пользователя к сопоставленным группам
+// провайдера. При изменении групп ранее выданные токены отзываются.
+func syncExternalGroups(tx *gorm.DB, userID uint, groups []string) error {
+	if len(groups) == 0 {
+		groups = []string{DefaultUserGroup}
+	}
+	changed, err := replaceUserGroups(tx, userID, groups)
+	if err != nil || !changed {
+		return err
+	}
+	return RevokeUserTokens(tx, userID)
+}
+
+// existingGroupCodes отбрасывает коды, для которых нет группы доступа.
+func existingGroupCodes(tx *gorm.DB, codes []string) ([]string, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	var existing []string
+	err := tx.Model(&models.AccessGroup{}).Where("code IN ?", codes).Order("code").Pluck("code", &existing).Error
+	return existing, err
+}
+
+// userInGroups сообщает, состоит ли пользователь хотя бы в одной из групп codes.
+func userInGroups(tx *gorm.DB, userID uint, codes []string) (bool, error) {
+	if len(codes) == 0 {
+		return false, nil
+	}
+	var count int64
+	err := tx.Model(&models.UserAccessGroup{}).
+		Joins("JOIN access_groups ON access_groups.id = user_access_groups.access_group_id").
+		Where("user_access_groups.user_id = ? AND access_groups.code IN ? AND access_groups.deleted_at IS NULL", userID, codes).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// replaceUserGroups приводит группы пользователя к указанному набору.
+func replaceUserGroups(tx *gorm.DB, userID uint, codes []string) (bool, error) {
+	var current []string
+	if err := tx.Model(&models.UserAccessGroup{}).
+		Joins("JOIN access_groups ON access_groups.id = user_access_groups.access_group_id").
+		Where("user_access_groups.user_id = ? AND access_groups.deleted_at IS NULL", userID).
+		Pluck("access_groups.code", &current).Error; err != nil {
+		return false, err
+	}
+
+	sort.Strings(current)
+	want := append([]string(nil), codes...)
+	sort.Strings(want)
+	if strings.Join(current, ",") == strings.Join(want, ",") {
+		return false, nil
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserAccessGroup{}).Error; err != nil {
+		return false, err
+	}
+	for _, code := range want {
+		if err := AddUserToGroup(tx, userID, code); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
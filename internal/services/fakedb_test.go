@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeHandler отвечает на запрос query с аргументами args. Для Exec
+// используется rowsAffected, для Query — columns и rows.
+type fakeHandler func(query string, args []driver.NamedValue) (fakeResult, error)
+
+type fakeResult struct {
+	rowsAffected int64
+	columns      []string
+	rows         [][]driver.Value
+}
+
+// openFakeDB возвращает gorm поверх драйвера, который передаёт каждый запрос
+// в handler. Подходит для проверки условных UPDATE без настоящей базы.
+func openFakeDB(t *testing.T, handler fakeHandler) *gorm.DB {
+	t.Helper()
+	sqlDB := sql.OpenDB(&fakeConnector{handler: handler})
+	t.Cleanup(func() { sqlDB.Close() })
+
+	conn, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+type fakeConnector struct {
+	mu      sync.Mutex
+	handler fakeHandler
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{c: c}, nil }
+func (c *fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("fakedb: use connector") }
+
+type fakeConn struct{ c *fakeConnector }
+
+func (fc *fakeConn) call(query string, args []driver.NamedValue) (fakeResult, error) {
+	fc.c.mu.Lock()
+	defer fc.c.mu.Unlock()
+	return fc.c.handler(query, args)
+}
+
+func (fc *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fakedb: prepared statements are not supported")
+}
+func (fc *fakeConn) Close() error              { return nil }
+func (fc *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+func (fc *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (fc *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res, err := fc.call(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(res.rowsAffected), nil
+}
+
+func (fc *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res, err := fc.call(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: res.columns, rows: res.rows}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
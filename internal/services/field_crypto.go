@@ -0,0 +1,150 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoFieldKey      = errors.New("field encryption key is not configured")
+	ErrUnknownFieldKey = errors.New("unknown field encryption key")
+	ErrBadCiphertext   = errors.New("malformed encrypted value")
+)
+
+// FieldKeyring шифрует чувствительные поля AES-256-GCM. Новые значения шифруются
+// активным ключом, читаются значения, зашифрованные любым ключом из набора, —
+// так старый ключ можно вывести из оборота после RotateFieldEncryption.
+// Формат значения: enc:<kid>:<base64(nonce|ciphertext)>; kid входит в AAD.
+type FieldKeyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// NewFieldKeyring создаёт набор ключей из пар kid → ключ в hex (32 байта).
+// active — kid ключа для шифрования; если ключ один, его можно не указывать.
+func NewFieldKeyring(keys map[string]string, active string) (*FieldKeyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoFieldKey
+	}
+	if active == "" {
+		if len(keys) > 1 {
+			return nil, errors.New("active field encryption key id is required when several keys are configured")
+		}
+		for kid := range keys {
+			active = kid
+		}
+	}
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("field encryption key %q is not configured", active)
+	}
+
+	k := &FieldKeyring{active: active, keys: make(map[string]cipher.AEAD, len(keys))}
+	for kid, encoded := range keys {
+		if strings.Contains(kid, ":") {
+			return nil, fmt.Errorf("field encryption key id %q must not contain ':'", kid)
+		}
+		raw, err := hex.DecodeString(encoded)
+		if err != nil || len(raw) != 32 {
+			return nil, fmt.Errorf("field encryption key %q must be 32 bytes in hex", kid)
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, err
+		}
+		if k.keys[kid], err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// ActiveKeyID возвращает kid ключа, которым шифруются новые значения.
+func (k *FieldKeyring) ActiveKeyID() string {
+	return k.active
+}
+
+func (k *FieldKeyring) Encrypt(plaintext []byte) (string, error) {
+	aead := k.keys[k.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(k.active))
+	return models.EncryptedPrefix + k.active + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (k *FieldKeyring) Decrypt(value string) ([]byte, error) {
+	kid, payload, ok := strings.Cut(strings.TrimPrefix(value, models.EncryptedPrefix), ":")
+	if !ok {
+		return nil, ErrBadCiphertext
+	}
+	aead, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownFieldKey, kid)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, ErrBadCiphertext
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(kid))
+	if err != nil {
+		return nil, ErrBadCiphertext
+	}
+	return plaintext, nil
+}
+
+var fieldKeyring *FieldKeyring
+
+// InitFieldEncryption включает шифрование чувствительных полей моделей.
+func InitFieldEncryption(k *FieldKeyring) {
+	fieldKeyring = k
+	models.SetFieldCipher(k)
+}
+
+// fieldRotationBatch — число кадровых записей, перешифровываемых за один запрос.
+const fieldRotationBatch = 500
+
+// RotateFieldEncryption перешифровывает активным ключом оклад и дату рождения
+// в кадровых записях (включая удалённые), зашифрованные другим ключом или ещё
+// не зашифрованные. Возвращает число обновлённых записей.
+func RotateFieldEncryption(conn *gorm.DB) (int, error) {
+	if fieldKeyring == nil {
+		return 0, ErrNoFieldKey
+	}
+	current := models.EncryptedPrefix + fieldKeyring.active + ":%"
+
+	updated := 0
+	var lastID uint
+	for {
+		var records []models.EmployeeHR
+		if err := conn.Unscoped().
+			Select("id", "salary", "birth_date").
+			Where("id > ? AND (salary NOT LIKE ? OR birth_date NOT LIKE ?)", lastID, current, current).
+			Order("id").
+			Limit(fieldRotationBatch).
+			Find(&records).Error; err != nil {
+			return updated, err
+		}
+		if len(records) == 0 {
+			return updated, nil
+		}
+
+		for _, r := range records {
+			if err := conn.Unscoped().Model(&r).
+				UpdateColumns(map[string]interface{}{"salary": r.Salary, "birth_date": r.BirthDate}).Error; err != nil {
+				return updated, err
+			}
+		}
+		updated += len(records)
+		lastID = records[len(records)-1].ID
+	}
+}
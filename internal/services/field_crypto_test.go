@@ -0,0 +1,213 @@
+package services
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+)
+
+const (
+	testFieldKey1 = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	testFieldKey2 = "1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+)
+
+func newTestKeyring(t *testing.T, keys map[string]string, active string) *FieldKeyring {
+	t.Helper()
+	k, err := NewFieldKeyring(keys, active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+// useFieldKeyring включает шифрование полей на время теста.
+func useFieldKeyring(t *testing.T, k *FieldKeyring) {
+	prev := fieldKeyring
+	InitFieldEncryption(k)
+	t.Cleanup(func() {
+		fieldKeyring = prev
+		if prev != nil {
+			models.SetFieldCipher(prev)
+		} else {
+			models.SetFieldCipher(nil)
+		}
+	})
+}
+
+func TestNewFieldKeyring(t *testing.T) {
+	cases := []struct {
+		name   string
+		keys   map[string]string
+		active string
+		ok     bool
+	}{
+		{"single key", map[string]string{"k1": testFieldKey1}, "", true},
+		{"active chosen", map[string]string{"k1": testFieldKey1, "k2": testFieldKey2}, "k2", true},
+		{"no keys", nil, "", false},
+		{"several keys without active", map[string]string{"k1": testFieldKey1, "k2": testFieldKey2}, "", false},
+		{"unknown active", map[string]string{"k1": testFieldKey1}, "k2", false},
+		{"short key", map[string]string{"k1": "0011"}, "", false},
+		{"not hex", map[string]string{"k1": strings.Repeat("zz", 32)}, "", false},
+		{"colon in kid", map[string]string{"k:1": testFieldKey1}, "", false},
+	}
+	for _, tc := range cases {
+		if _, err := NewFieldKeyring(tc.keys, tc.active); (err == nil) != tc.ok {
+			t.Errorf("%s: err = %v", tc.name, err)
+		}
+	}
+}
+
+func TestFieldKeyringRoundTrip(t *testing.T) {
+	k := newTestKeyring(t, map[string]string{"k1": testFieldKey1}, "")
+
+	first, err := k.Encrypt([]byte("125000.5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(first, models.EncryptedPrefix+"k1:") {
+		t.Errorf("value %q has no kid prefix", first)
+	}
+	second, err := k.Encrypt([]byte("125000.5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Error("equal values encrypted to the same ciphertext")
+	}
+
+	for _, value := range []string{first, second} {
+		plaintext, err := k.Decrypt(value)
+		if err != nil || string(plaintext) != "125000.5" {
+			t.Errorf("Decrypt(%q) = %q, %v", value, plaintext, err)
+		}
+	}
+}
+
+func TestFieldKeyringRejects(t *testing.T) {
+	both := newTestKeyring(t, map[string]string{"k1": testFieldKey1, "k2": testFieldKey2}, "k1")
+	value, err := both.Encrypt([]byte("1990-05-01T00:00:00Z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := strings.TrimPrefix(value, models.EncryptedPrefix+"k1:")
+
+	sealed, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed[len(sealed)-1] ^= 1
+	tampered := models.EncryptedPrefix + "k1:" + base64.RawStdEncoding.EncodeToString(sealed)
+
+	cases := []struct {
+		name    string
+		keyring *FieldKeyring
+		value   string
+		want    error
+	}{
+		{"key removed", newTestKeyring(t, map[string]string{"k2": testFieldKey2}, ""), value, ErrUnknownFieldKey},
+		{"kid swapped", both, models.EncryptedPrefix + "k2:" + payload, ErrBadCiphertext},
+		{"tampered", both, tampered, ErrBadCiphertext},
+		{"no kid", both, models.EncryptedPrefix + payload, ErrBadCiphertext},
+		{"not base64", both, models.EncryptedPrefix + "k1:***", ErrBadCiphertext},
+		{"too short", both, models.EncryptedPrefix + "k1:AAAA", ErrBadCiphertext},
+	}
+	for _, tc := range cases {
+		if _, err := tc.keyring.Decrypt(tc.value); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestEncryptedFieldsLegacyPlaintext(t *testing.T) {
+	useFieldKeyring(t, newTestKeyring(t, map[string]string{"k1": testFieldKey1}, ""))
+
+	// Значения, записанные до включения шифрования, читаются как есть.
+	var salary models.EncryptedFloat
+	if err := salary.Scan("98000.25"); err != nil || salary != 98000.25 {
+		t.Errorf("legacy salary = %v, %v", salary, err)
+	}
+	var birth models.EncryptedDate
+	if err := birth.Scan([]byte("1990-05-01T00:00:00Z")); err != nil || birth.Format("2006-01-02") != "1990-05-01" {
+		t.Errorf("legacy birth date = %v, %v", birth, err)
+	}
+
+	stored, err := models.EncryptedFloat(98000.25).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back models.EncryptedFloat
+	if err := back.Scan(stored); err != nil || back != 98000.25 {
+		t.Errorf("round trip salary = %v, %v", back, err)
+	}
+}
+
+func TestRotateFieldEncryption(t *testing.T) {
+	old := newTestKeyring(t, map[string]string{"k1": testFieldKey1}, "")
+	oldSalary, err := old.Encrypt([]byte("150000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldBirth, err := old.Encrypt([]byte("1985-11-30T00:00:00Z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := newTestKeyring(t, map[string]string{"k1": testFieldKey1, "k2": testFieldKey2}, "k2")
+	useFieldKeyring(t, k)
+
+	selects := 0
+	updates := map[int64][]driver.Value{}
+	conn := openFakeDB(t, func(query string, args []driver.NamedValue) (fakeResult, error) {
+		switch {
+		case strings.HasPrefix(query, `SELECT "id","salary","birth_date" FROM "employee_hrs"`):
+			selects++
+			if args[1].Value != models.EncryptedPrefix+"k2:%" {
+				t.Errorf("rotation filter = %v", args[1].Value)
+			}
+			if selects > 1 {
+				return fakeResult{columns: []string{"id"}}, nil
+			}
+			return fakeResult{
+				columns: []string{"id", "salary", "birth_date"},
+				rows: [][]driver.Value{
+					{int64(1), "98000", "1990-05-01T00:00:00Z"},
+					{int64(2), oldSalary, oldBirth},
+				},
+			}, nil
+		case strings.HasPrefix(query, `UPDATE "employee_hrs" SET`):
+			id := args[len(args)-1].Value.(int64)
+			for _, a := range args[:len(args)-1] {
+				updates[id] = append(updates[id], a.Value)
+			}
+			return fakeResult{rowsAffected: 1}, nil
+		}
+		t.Fatalf("unexpected query: %s", query)
+		return fakeResult{}, nil
+	})
+
+	n, err := RotateFieldEncryption(conn)
+	if err != nil || n != 2 {
+		t.Fatalf("RotateFieldEncryption = %d, %v", n, err)
+	}
+
+	want := map[int64][]string{1: {"1990-05-01T00:00:00Z", "98000"}, 2: {"1985-11-30T00:00:00Z", "150000"}}
+	for id, values := range want {
+		if len(updates[id]) != len(values) {
+			t.Fatalf("record %d: updated %v", id, updates[id])
+		}
+		for i, v := range updates[id] {
+			s, _ := v.(string)
+			if !strings.HasPrefix(s, models.EncryptedPrefix+"k2:") {
+				t.Errorf("record %d: value %q not encrypted with the active key", id, s)
+				continue
+			}
+			plaintext, err := k.Decrypt(s)
+			if err != nil || string(plaintext) != values[i] {
+				t.Errorf("record %d: decrypted %q, %v; want %q", id, plaintext, err, values[i])
+			}
+		}
+	}
+}
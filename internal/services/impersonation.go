@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrImpersonationInvalid   = errors.New("impersonation session invalid, expired or ended")
+	ErrImpersonationForbidden = errors.New("user cannot be impersonated")
+)
+
+var impersonationTTL = 30 * time.Minute
+
+// InitImpersonation задаёт срок действия сессии имперсонации.
+func InitImpersonation(ttl time.Duration) {
+	impersonationTTL = ttl
+}
+
+// StartImpersonation открывает сессию просмотра от имени target и выдаёт
+// access-токен: claims пользователя — от target, в "act" — администратор,
+// в "imp" — ID сессии. Нельзя действовать от своего имени и от имени
+// пользователя, который сам может использовать имперсонацию.
+// target должен быть загружен с Preload("AccessGroups.AccessGroup").
+func StartImpersonation(tx *gorm.DB, actorID uint, target models.User, reason string, allowWrite bool) (*models.ImpersonationResponse, error) {
+	if target.ID == actorID || target.DeletedAt != nil {
+		return nil, ErrImpersonationForbidden
+	}
+	groups := GroupClaims(target)
+	privileged, err := GroupsHavePermission(groups, PermUsersImpersonate)
+	if err != nil {
+		return nil, err
+	}
+	if privileged {
+		return nil, ErrImpersonationForbidden
+	}
+
+	session := models.ImpersonationSession{
+		ActorID:    actorID,
+		UserID:     target.ID,
+		Reason:     reason,
+		AllowWrite: allowWrite,
+		ExpiresAt:  time.Now().Add(impersonationTTL),
+	}
+	if err := tx.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	token, err := SignToken(jwt.MapClaims{
+		"typ":     TokenTypeAccess,
+		"aud":     AccessTokenAudience,
+		"user_id": target.ID,
+		"groups":  groups,
+		"ver":     target.TokenVersion,
+		"act":     actorID,
+		"imp":     session.ID,
+		"exp":     session.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ImpersonationResponse{
+		Token:      token,
+		ExpiresIn:  int(impersonationTTL.Seconds()),
+		SessionID:  session.ID,
+		UserID:     target.ID,
+		Login:      target.Login,
+		AllowWrite: allowWrite,
+	}, nil
+}
+
+// LoadImpersonation возвращает действующую сессию администратора actorID.
+func LoadImpersonation(conn *gorm.DB, sessionID, actorID uint) (*models.ImpersonationSession, error) {
+	var session models.ImpersonationSession
+	if err := conn.First(&session, sessionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImpersonationInvalid
+		}
+		return nil, err
+	}
+	if session.ActorID != actorID || session.EndedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, ErrImpersonationInvalid
+	}
+	return &session, nil
+}
+
+// StopImpersonation завершает сессию; её токен перестаёт действовать.
+func StopImpersonation(tx *gorm.DB, sessionID uint) error {
+	res := tx.Model(&models.ImpersonationSession{}).
+		Where("id = ? AND ended_at IS NULL", sessionID).
+		Update("ended_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrImpersonationInvalid
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrNoSigningKey = errors.New("jwt signing key is not configured")
+
+// Тип токена (claim "typ"). Все токены подписываются одним ключом, поэтому
+// ParseToken принимает токен только ожидаемого типа.
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeChallenge = "challenge"
+	TokenTypeOIDCState = "oidc_state"
+)
+
+// AccessTokenAudience — claim "aud" access-токенов: сервисы, проверяющие токены
+// по JWKS, отличают по нему access-токены от служебных.
+const AccessTokenAudience = "employee-dashboard-api"
+
+// KeySetConfig — источники ключей подписи токенов.
+type KeySetConfig struct {
+	// Algorithm — RS256, EdDSA или HS256. Если пуст, определяется по ключу.
+	Algorithm string
+	// SigningKeyFile — PEM-файл закрытого ключа RSA или Ed25519.
+	SigningKeyFile string
+	// SigningKeyID — kid ключа подписи; по умолчанию отпечаток ключа (RFC 7638).
+	SigningKeyID string
+	// VerifyKeyFiles — PEM-файлы открытых ключей, токены с которыми ещё
+	// принимаются (предыдущие ключи при ротации). Формат: "путь" или "kid=путь".
+	VerifyKeyFiles []string
+	// Secret — общий секрет для HS256. Используется, только если не задан SigningKeyFile.
+	Secret string
+}
+
+type jwtKey struct {
+	id     string
+	method jwt.SigningMethod
+	// private — ключ подписи: *rsa.PrivateKey, ed25519.PrivateKey или []byte для HS256.
+	private interface{}
+	// public — ключ проверки: *rsa.PublicKey, ed25519.PublicKey или []byte для HS256.
+	public interface{}
+}
+
+// KeySet — ключ подписи и все ключи, которыми проверяются токены.
+type KeySet struct {
+	signing *jwtKey
+	verify  map[string]*jwtKey
+}
+
+var keySet *KeySet
+
+// InitKeySet задаёт ключи подписи и проверки токенов.
+func InitKeySet(ks *KeySet) {
+	keySet = ks
+}
+
+// LoadKeySet загружает ключи из файлов. Ошибка возвращается, если ключ подписи
+// не задан, не читается или слишком слабый.
+func LoadKeySet(cfg KeySetConfig) (*KeySet, error) {
+	ks := &KeySet{verify: make(map[string]*jwtKey)}
+
+	switch {
+	case cfg.SigningKeyFile != "":
+		key, err := loadPrivateKey(cfg.SigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt signing key: %w", err)
+		}
+		ks.signing = key
+	case cfg.Secret != "":
+		if len(cfg.Secret) < 32 {
+			return nil, errors.New("jwt secret must be at least 32 bytes")
+		}
+		sum := sha256.Sum256([]byte(cfg.Secret))
+		ks.signing = &jwtKey{
+			id:      "hs-" + hex.EncodeToString(sum[:4]),
+			method:  jwt.SigningMethodHS256,
+			private: []byte(cfg.Secret),
+			public:  []byte(cfg.Secret),
+		}
+	default:
+		return nil, ErrNoSigningKey
+	}
+
+	if cfg.Algorithm != "" && cfg.Algorithm != ks.signing.method.Alg() {
+		return nil, fmt.Errorf("jwt algorithm %s does not match signing key (%s)", cfg.Algorithm, ks.signing.method.Alg())
+	}
+	if cfg.SigningKeyID != "" {
+		ks.signing.id = cfg.SigningKeyID
+	}
+	ks.verify[ks.signing.id] = ks.signing
+
+	for _, item := range cfg.VerifyKeyFiles {
+		kid, path := "", item
+		if i := strings.Index(item, "="); i > 0 {
+			kid, path = item[:i], item[i+1:]
+		}
+
+		key, err := loadPublicKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("jwt verification key %s: %w", path, err)
+		}
+		if kid != "" {
+			key.id = kid
+		}
+		if _, exists := ks.verify[key.id]; exists {
+			return nil, fmt.Errorf("jwt verification key %s: duplicate kid %q", path, key.id)
+		}
+		ks.verify[key.id] = key
+	}
+
+	return ks, nil
+}
+
+// SignToken подписывает claims текущим ключом и указывает его kid в заголовке.
+func SignToken(claims jwt.Claims) (string, error) {
+	if keySet == nil || keySet.signing == nil {
+		return "", ErrNoSigningKey
+	}
+	token := jwt.NewWithClaims(keySet.signing.method, claims)
+	token.Header["kid"] = keySet.signing.id
+	return token.SignedString(keySet.signing.private)
+}
+
+// ParseToken проверяет подпись, срок действия и тип typ токена (для access-токенов
+// также aud). Ключ выбирается по kid; токены без kid (выданные до ротации ключей)
+// проверяются текущим ключом.
+func ParseToken(raw, typ string) (jwt.MapClaims, error) {
+	if keySet == nil || keySet.signing == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	opts := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if typ == TokenTypeAccess {
+		opts = append(opts, jwt.WithAudience(AccessTokenAudience))
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		key := keySet.signing
+		if kid, ok := token.Header["kid"].(string); ok {
+			if key, ok = keySet.verify[kid]; !ok {
+				return nil, jwt.ErrTokenUnverifiable
+			}
+		}
+		// Алгоритм определяется ключом, а не заголовком токена.
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+		return key.public, nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims["typ"] != typ {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
+// PublicJWKS возвращает открытые ключи для /.well-known/jwks.json.
+// Симметричные ключи HS256 не публикуются.
+func PublicJWKS() models.JWKSet {
+	set := models.JWKSet{Keys: []models.JWK{}}
+	if keySet == nil {
+		return set
+	}
+
+	// Ключ подписи первым, остальные — в порядке kid.
+	ids := []string{keySet.signing.id}
+	var rest []string
+	for id := range keySet.verify {
+		if id != keySet.signing.id {
+			rest = append(rest, id)
+		}
+	}
+	sort.Strings(rest)
+	ids = append(ids, rest...)
+
+	for _, id := range ids {
+		if jwk, ok := publicJWK(keySet.verify[id]); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+func loadPrivateKey(path string) (*jwtKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		if rsaKey.N.BitLen() < 2048 {
+			return nil, errors.New("rsa key must be at least 2048 bits")
+		}
+		return newAsymmetricKey(jwt.SigningMethodRS256, rsaKey, &rsaKey.PublicKey)
+	}
+	if edKey, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		priv, ok := edKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("unsupported key type")
+		}
+		return newAsymmetricKey(jwt.SigningMethodEdDSA, priv, priv.Public())
+	}
+	return nil, errors.New("expected PEM-encoded RSA or Ed25519 private key")
+}
+
+func loadPublicKey(path string) (*jwtKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return newAsymmetricKey(jwt.SigningMethodRS256, nil, rsaKey)
+	}
+	if edKey, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return newAsymmetricKey(jwt.SigningMethodEdDSA, nil, edKey)
+	}
+	return nil, errors.New("expected PEM-encoded RSA or Ed25519 public key")
+}
+
+func newAsymmetricKey(method jwt.SigningMethod, private interface{}, public crypto.PublicKey) (*jwtKey, error) {
+	key := &jwtKey{method: method, private: private, public: public}
+	jwk, ok := publicJWK(key)
+	if !ok {
+		return nil, errors.New("unsupported key type")
+	}
+	key.id = jwkThumbprint(jwk)
+	return key, nil
+}
+
+func publicJWK(key *jwtKey) (models.JWK, bool) {
+	b64 := base64.RawURLEncoding
+	switch pub := key.public.(type) {
+	case *rsa.PublicKey:
+		return models.JWK{
+			Kty: "RSA",
+			Kid: key.id,
+			Use: "sig",
+			Alg: key.method.Alg(),
+			N:   b64.EncodeToString(pub.N.Bytes()),
+			E:   b64.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return models.JWK{
+			Kty: "OKP",
+			Kid: key.id,
+			Use: "sig",
+			Alg: key.method.Alg(),
+			Crv: "Ed25519",
+			X:   b64.EncodeToString(pub),
+		}, true
+	}
+	return models.JWK{}, false
+}
+
+// jwkThumbprint вычисляет отпечаток ключа по RFC 7638: SHA-256 от JSON
+// с обязательными полями в лексикографическом порядке.
+func jwkThumbprint(jwk models.JWK) string {
+	var fields interface{}
+	if jwk.Kty == "RSA" {
+		fields = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	} else {
+		fields = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+	raw, _ := json.Marshal(fields)
+	sum := sha256.Sum256(raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+)
+
+func TestParseTokenType(t *testing.T) {
+	ks, err := LoadKeySet(KeySetConfig{Secret: "test-secret-test-secret-test-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := keySet
+	InitKeySet(ks)
+	t.Cleanup(func() { keySet = prev })
+
+	access, err := GenerateJWT(7, []string{"user"}, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := GenerateChallengeToken(models.User{ID: 7}, ChallengeTwoFactor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		token string
+		typ   string
+		ok    bool
+	}{
+		{"access as access", access, TokenTypeAccess, true},
+		{"challenge as challenge", challenge, TokenTypeChallenge, true},
+		{"challenge as access", challenge, TokenTypeAccess, false},
+		{"access as challenge", access, TokenTypeChallenge, false},
+		{"access as oidc state", access, TokenTypeOIDCState, false},
+	}
+	for _, tc := range cases {
+		claims, err := ParseToken(tc.token, tc.typ)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: err = %v", tc.name, err)
+		}
+		if tc.ok && tc.typ == TokenTypeAccess && claims["aud"] != AccessTokenAudience {
+			t.Errorf("%s: aud = %v", tc.name, claims["aud"])
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/go-ldap/ldap/v3"
+	"gorm.io/gorm"
+)
+
+const LDAPProviderName = "ldap"
+
+// LDAPConfig — параметры проверки пароля в LDAP / Active Directory.
+type LDAPConfig struct {
+	URL                string // ldap://host:389 или ldaps://host:636
+	StartTLS           bool
+	InsecureSkipVerify bool
+	// CAFile — PEM-файл с сертификатами УЦ каталога; если пуст, используются системные.
+	CAFile  string
+	Timeout time.Duration
+	// BindDN и BindPassword — служебная учётная запись для поиска пользователя.
+	// Если BindDN пуст, поиск выполняется анонимно.
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// UserFilter — фильтр поиска; {login} заменяется экранированным логином,
+	// например (&(objectClass=user)(sAMAccountName={login})).
+	UserFilter string
+	// LoginAttribute — атрибут, значение которого становится логином пользователя.
+	LoginAttribute string
+	GroupAttribute string // обычно memberOf
+	// GroupMap сопоставляет DN группы каталога коду AccessGroup (без учёта регистра).
+	GroupMap map[string]string
+	// AutoProvision разрешает создавать пользователя и сотрудника при первом входе.
+	AutoProvision bool
+	// SyncGroups заменяет группы пользователя сопоставленными при каждом входе.
+	SyncGroups bool
+	// LocalGroups — группы резервных локальных учётных записей (AUTH_LOCAL_GROUPS).
+	// Их участники не входят через каталог, даже если логин совпал, и их группы
+	// не синхронизируются.
+	LocalGroups []string
+}
+
+// LDAPConn — операции с каталогом, которые использует провайдер.
+// Реализуется *ldap.Conn; в проверках можно подставить каталог в памяти.
+type LDAPConn interface {
+	Bind(username, password string) error
+	Search(req *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close() error
+}
+
+// LDAPProvider проверяет пароль привязкой (bind) от имени найденной записи
+// пользователя и синхронизирует его группы по атрибуту memberOf.
+// Локальный пользователь сопоставляется по логину, кроме участников LocalGroups.
+type LDAPProvider struct {
+	cfg      LDAPConfig
+	groupMap map[string]string
+
+	// Dial открывает соединение с каталогом; по умолчанию — по cfg.URL.
+	Dial func() (LDAPConn, error)
+}
+
+func NewLDAPProvider(cfg LDAPConfig) *LDAPProvider {
+	if cfg.GroupAttribute == "" {
+		cfg.GroupAttribute = "memberOf"
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	p := &LDAPProvider{cfg: cfg, groupMap: make(map[string]string, len(cfg.GroupMap))}
+	for dn, code := range cfg.GroupMap {
+		p.groupMap[normalizeDN(dn)] = code
+	}
+	p.Dial = p.dial
+	return p
+}
+
+func (p *LDAPProvider) Name() string {
+	return LDAPProviderName
+}
+
+func (p *LDAPProvider) Config() LDAPConfig {
+	return p.cfg
+}
+
+func (p *LDAPProvider) Authenticate(ctx context.Context, conn *gorm.DB, login, password string) (models.User, error) {
+	// Привязка с пустым паролем в LDAP считается анонимной и всегда успешна.
+	if password == "" {
+		return models.User{}, ErrInvalidCredentials
+	}
+
+	entry, err := p.verify(login, password)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	var user models.User
+	err = Transaction(conn.WithContext(ctx), func(tx *gorm.DB) error {
+		user, err = p.resolveUser(tx, entry)
+		return err
+	})
+	return user, err
+}
+
+// verify находит запись пользователя и проверяет пароль. Сетевые операции
+// выполняются до открытия транзакции в базе.
+func (p *LDAPProvider) verify(login, password string) (*ldap.Entry, error) {
+	dir, err := p.Dial()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+	}
+	defer dir.Close()
+
+	if p.cfg.BindDN != "" {
+		if err := dir.Bind(p.cfg.BindDN, p.cfg.BindPassword); err != nil {
+			return nil, fmt.Errorf("%w: service bind: %v", ErrProviderUnavailable, err)
+		}
+	}
+
+	filter := strings.ReplaceAll(p.cfg.UserFilter, "{login}", ldap.EscapeFilter(login))
+	res, err := dir.Search(ldap.NewSearchRequest(
+		p.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(p.cfg.Timeout/time.Second), false, filter,
+		[]string{p.cfg.LoginAttribute, p.cfg.GroupAttribute, "givenName", "sn", "cn"},
+		nil,
+	))
+	// Неоднозначный результат не позволяет понять, чей пароль проверять.
+	if errors.Is(err, ldap.ErrSizeLimitExceeded) || ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, ErrUnknownUser
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: search: %v", ErrProviderUnavailable, err)
+	}
+	if len(res.Entries) != 1 {
+		return nil, ErrUnknownUser
+	}
+
+	entry := res.Entries[0]
+	if err := dir.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("%w: user bind: %v", ErrProviderUnavailable, err)
+	}
+	return entry, nil
+}
+
+func (p *LDAPProvider) resolveUser(tx *gorm.DB, entry *ldap.Entry) (models.User, error) {
+	login := entry.GetAttributeValue(p.cfg.LoginAttribute)
+	if login == "" {
+		return models.User{}, ErrUnknownUser
+	}
+
+	groups, err := existingGroupCodes(tx, p.MapGroups(entry.GetAttributeValues(p.cfg.GroupAttribute)))
+	if err != nil {
+		return models.User{}, err
+	}
+
+	var user models.User
+	err = tx.Where("LOWER(login) = ?", strings.ToLower(login)).First(&user).Error
+	switch {
+	case err == nil:
+		if user.DeletedAt != nil {
+			return models.User{}, ErrInvalidCredentials
+		}
+		// Резервную учётную запись проверяет только LocalProvider: запись каталога
+		// с тем же логином не даёт входа от её имени и не меняет её группы.
+		local, err := userInGroups(tx, user.ID, p.cfg.LocalGroups)
+		if err != nil {
+			return models.User{}, err
+		}
+		if local {
+			return models.User{}, ErrUnknownUser
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if !p.cfg.AutoProvision {
+			return models.User{}, ErrUnknownUser
+		}
+		lastName, firstName := entry.GetAttributeValue("sn"), entry.GetAttributeValue("givenName")
+		if lastName == "" && firstName == "" {
+			lastName, firstName = splitDisplayName(entry.GetAttributeValue("cn"))
+		}
+		if user, err = provisionExternalUser(tx, login, lastName, firstName, groups); err != nil {
+			return models.User{}, err
+		}
+	default:
+		return models.User{}, err
+	}
+
+	if p.cfg.SyncGroups {
+		if err := syncExternalGroups(tx, user.ID, groups); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	err = tx.Preload("AccessGroups.AccessGroup").First(&user, user.ID).Error
+	return user, err
+}
+
+// MapGroups переводит DN групп каталога в коды групп доступа.
+// Группы без сопоставления пропускаются.
+func (p *LDAPProvider) MapGroups(dns []string) []string {
+	var result []string
+	for _, dn := range dns {
+		code, ok := p.groupMap[normalizeDN(dn)]
+		if ok && !containsString(result, code) {
+			result = append(result, code)
+		}
+	}
+	return result
+}
+
+func (p *LDAPProvider) dial() (LDAPConn, error) {
+	tlsCfg, err := p.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := ldap.DialURL(p.cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: p.cfg.Timeout}),
+		ldap.DialWithTLSConfig(tlsCfg),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(p.cfg.Timeout)
+
+	if p.cfg.StartTLS {
+		if err := conn.StartTLS(tlsCfg); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// tlsConfig возвращает параметры TLS для ldaps:// и StartTLS. Имя сервера берётся
+// из URL: без него StartTLS не проверяет, что сертификат выдан этому хосту.
+func (p *LDAPProvider) tlsConfig() (*tls.Config, error) {
+	u, err := url.Parse(p.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: p.cfg.InsecureSkipVerify,
+	}
+
+	if p.cfg.CAFile != "" {
+		pem, err := os.ReadFile(p.cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ldap: no certificates in %s", p.cfg.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// normalizeDN приводит DN к виду для сравнения: без пробелов вокруг
+// компонентов и в нижнем регистре.
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return strings.ToLower(strings.Join(parts, ","))
+}
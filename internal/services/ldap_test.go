@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeDirectory — каталог LDAP в памяти. Поиск находит записи, у которых
+// атрибут uid точно совпадает с условием фильтра (uid=...).
+type fakeDirectory struct {
+	entries   []*ldap.Entry
+	passwords map[string]string // DN → пароль
+
+	searchErr error
+	filters   []string
+	binds     []string
+	closed    bool
+}
+
+func (d *fakeDirectory) Bind(username, password string) error {
+	d.binds = append(d.binds, username)
+	if want, ok := d.passwords[username]; ok && want == password {
+		return nil
+	}
+	return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+}
+
+func (d *fakeDirectory) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	d.filters = append(d.filters, req.Filter)
+	if d.searchErr != nil {
+		return nil, d.searchErr
+	}
+	res := &ldap.SearchResult{}
+	for _, e := range d.entries {
+		if strings.Contains(req.Filter, "(uid="+e.GetAttributeValue("uid")+")") {
+			res.Entries = append(res.Entries, e)
+		}
+	}
+	return res, nil
+}
+
+func (d *fakeDirectory) Close() error {
+	d.closed = true
+	return nil
+}
+
+const testLDAPServiceDN = "cn=svc,dc=example,dc=com"
+
+func newFakeDirectory() *fakeDirectory {
+	return &fakeDirectory{
+		entries: []*ldap.Entry{
+			ldap.NewEntry("uid=ivanov,ou=people,dc=example,dc=com", map[string][]string{
+				"uid":      {"ivanov"},
+				"memberOf": {"CN=HR, OU=Groups, DC=example, DC=com"},
+			}),
+			ldap.NewEntry("uid=petrov,ou=people,dc=example,dc=com", map[string][]string{"uid": {"petrov"}}),
+		},
+		passwords: map[string]string{
+			testLDAPServiceDN:                        "svc-secret",
+			"uid=ivanov,ou=people,dc=example,dc=com": "ivanov-secret",
+			"uid=petrov,ou=people,dc=example,dc=com": "petrov-secret",
+		},
+	}
+}
+
+func newTestLDAPProvider(dir *fakeDirectory) *LDAPProvider {
+	p := NewLDAPProvider(LDAPConfig{
+		URL:            "ldap://ldap.example.com",
+		BindDN:         testLDAPServiceDN,
+		BindPassword:   "svc-secret",
+		BaseDN:         "dc=example,dc=com",
+		UserFilter:     "(&(objectClass=person)(uid={login}))",
+		LoginAttribute: "uid",
+		GroupMap:       map[string]string{"cn=hr,ou=groups,dc=example,dc=com": "hr"},
+	})
+	p.Dial = func() (LDAPConn, error) { return dir, nil }
+	return p
+}
+
+func TestLDAPVerify(t *testing.T) {
+	dir := newFakeDirectory()
+	p := newTestLDAPProvider(dir)
+
+	entry, err := p.verify("ivanov", "ivanov-secret")
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if entry.DN != "uid=ivanov,ou=people,dc=example,dc=com" {
+		t.Errorf("DN = %s", entry.DN)
+	}
+	if got := p.MapGroups(entry.GetAttributeValues("memberOf")); len(got) != 1 || got[0] != "hr" {
+		t.Errorf("groups = %v", got)
+	}
+	if len(dir.binds) != 2 || dir.binds[0] != testLDAPServiceDN {
+		t.Errorf("binds = %v", dir.binds)
+	}
+	if !dir.closed {
+		t.Error("connection not closed")
+	}
+}
+
+func TestLDAPEmptyPassword(t *testing.T) {
+	p := newTestLDAPProvider(newFakeDirectory())
+	p.Dial = func() (LDAPConn, error) {
+		t.Fatal("directory contacted for an empty password")
+		return nil, nil
+	}
+
+	_, err := p.Authenticate(context.Background(), nil, "ivanov", "")
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("got %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLDAPFilterInjection(t *testing.T) {
+	dir := newFakeDirectory()
+	p := newTestLDAPProvider(dir)
+
+	_, err := p.verify("*)(uid=*", "ivanov-secret")
+	if !errors.Is(err, ErrUnknownUser) {
+		t.Fatalf("got %v, want ErrUnknownUser", err)
+	}
+	want := `(&(objectClass=person)(uid=\2a\29\28uid=\2a))`
+	if len(dir.filters) != 1 || dir.filters[0] != want {
+		t.Fatalf("filter = %v, want %s", dir.filters, want)
+	}
+	if len(dir.binds) != 1 {
+		t.Errorf("user bind attempted: %v", dir.binds)
+	}
+}
+
+func TestLDAPAmbiguousUser(t *testing.T) {
+	t.Run("several entries", func(t *testing.T) {
+		dir := newFakeDirectory()
+		dir.entries = append(dir.entries, ldap.NewEntry("uid=ivanov,ou=contractors,dc=example,dc=com",
+			map[string][]string{"uid": {"ivanov"}}))
+		dir.passwords["uid=ivanov,ou=contractors,dc=example,dc=com"] = "ivanov-secret"
+
+		if _, err := newTestLDAPProvider(dir).verify("ivanov", "ivanov-secret"); !errors.Is(err, ErrUnknownUser) {
+			t.Fatalf("got %v, want ErrUnknownUser", err)
+		}
+		if len(dir.binds) != 1 {
+			t.Errorf("user bind attempted: %v", dir.binds)
+		}
+	})
+
+	t.Run("size limit exceeded", func(t *testing.T) {
+		dir := newFakeDirectory()
+		dir.searchErr = ldap.NewError(ldap.LDAPResultSizeLimitExceeded, errors.New("size limit exceeded"))
+
+		if _, err := newTestLDAPProvider(dir).verify("ivanov", "ivanov-secret"); !errors.Is(err, ErrUnknownUser) {
+			t.Fatalf("got %v, want ErrUnknownUser", err)
+		}
+	})
+}
+
+func TestLDAPWrongPassword(t *testing.T) {
+	dir := newFakeDirectory()
+	if _, err := newTestLDAPProvider(dir).verify("ivanov", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("got %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLDAPUnavailable(t *testing.T) {
+	t.Run("dial", func(t *testing.T) {
+		p := newTestLDAPProvider(newFakeDirectory())
+		p.Dial = func() (LDAPConn, error) { return nil, errors.New("connection refused") }
+
+		if _, err := p.verify("ivanov", "ivanov-secret"); !errors.Is(err, ErrProviderUnavailable) {
+			t.Fatalf("got %v, want ErrProviderUnavailable", err)
+		}
+	})
+
+	t.Run("service bind", func(t *testing.T) {
+		dir := newFakeDirectory()
+		dir.passwords[testLDAPServiceDN] = "rotated"
+
+		if _, err := newTestLDAPProvider(dir).verify("ivanov", "ivanov-secret"); !errors.Is(err, ErrProviderUnavailable) {
+			t.Fatalf("got %v, want ErrProviderUnavailable", err)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		dir := newFakeDirectory()
+		dir.searchErr = ldap.NewError(ldap.LDAPResultBusy, errors.New("busy"))
+
+		if _, err := newTestLDAPProvider(dir).verify("ivanov", "ivanov-secret"); !errors.Is(err, ErrProviderUnavailable) {
+			t.Fatalf("got %v, want ErrProviderUnavailable", err)
+		}
+	})
+}
+
+// localUsers отвечает на запросы LocalProvider: пользователь admin входит
+// в группу admin, пользователь ivanov — в группу hr.
+func localUsers(t *testing.T) fakeHandler {
+	hash, err := bcrypt.GenerateFromPassword([]byte("local-secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]int64{"admin": 1, "ivanov": 2}
+
+	return func(query string, args []driver.NamedValue) (fakeResult, error) {
+		switch {
+		case strings.HasPrefix(query, `SELECT * FROM "users" WHERE login = $1`):
+			login, _ := args[0].Value.(string)
+			if id, ok := ids[login]; ok {
+				return fakeResult{
+					columns: []string{"id", "login", "password"},
+					rows:    [][]driver.Value{{id, login, string(hash)}},
+				}, nil
+			}
+			return fakeResult{columns: []string{"id"}}, nil
+		case strings.HasPrefix(query, `SELECT * FROM "user_access_groups"`):
+			id := args[0].Value.(int64)
+			return fakeResult{
+				columns: []string{"user_id", "access_group_id"},
+				rows:    [][]driver.Value{{id, id}},
+			}, nil
+		case strings.HasPrefix(query, `SELECT * FROM "access_groups"`):
+			codes := map[int64]string{1: "admin", 2: "hr"}
+			id := args[0].Value.(int64)
+			return fakeResult{
+				columns: []string{"id", "code"},
+				rows:    [][]driver.Value{{id, codes[id]}},
+			}, nil
+		}
+		t.Fatalf("unexpected query: %s", query)
+		return fakeResult{}, nil
+	}
+}
+
+func TestAuthenticateLocalBreakGlass(t *testing.T) {
+	saved := authProviders
+	t.Cleanup(func() { authProviders = saved })
+
+	ldapDown := newTestLDAPProvider(newFakeDirectory())
+	ldapDown.Dial = func() (LDAPConn, error) { return nil, errors.New("connection refused") }
+	InitAuthProviders(ldapDown, &LocalProvider{AllowedGroups: []string{"admin"}})
+
+	conn := openFakeDB(t, localUsers(t))
+	ctx := context.Background()
+
+	user, provider, err := Authenticate(ctx, conn, "admin", "local-secret")
+	if err != nil || provider != LocalProviderName || user.ID != 1 {
+		t.Fatalf("break-glass admin: user %d, provider %q, err %v", user.ID, provider, err)
+	}
+
+	// Пользователь вне AllowedGroups не входит локально, даже зная локальный пароль.
+	if _, _, err := Authenticate(ctx, conn, "ivanov", "local-secret"); !errors.Is(err, ErrProviderUnavailable) {
+		t.Fatalf("non-admin: got %v, want ErrProviderUnavailable", err)
+	}
+
+	// Неверный пароль резервной учётной записи отвергается, а не маскируется недоступностью каталога.
+	if _, _, err := Authenticate(ctx, conn, "admin", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("admin, wrong password: got %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLDAPTLSConfig(t *testing.T) {
+	for url, want := range map[string]string{
+		"ldaps://dc1.example.com:636": "dc1.example.com",
+		"ldap://dc1.example.com":      "dc1.example.com",
+		"ldap://[2001:db8::1]:389":    "2001:db8::1",
+	} {
+		cfg, err := NewLDAPProvider(LDAPConfig{URL: url}).tlsConfig()
+		if err != nil {
+			t.Fatalf("%s: %v", url, err)
+		}
+		if cfg.ServerName != want {
+			t.Errorf("%s: ServerName = %q, want %q", url, cfg.ServerName, want)
+		}
+	}
+
+	if _, err := NewLDAPProvider(LDAPConfig{URL: "ldaps://dc1.example.com", CAFile: "testdata/missing.pem"}).tlsConfig(); err == nil {
+		t.Error("missing CA file accepted")
+	}
+}
+
+func TestLDAPSkipsLocalGroupUsers(t *testing.T) {
+	dir := newFakeDirectory()
+	dir.entries = append(dir.entries, ldap.NewEntry("uid=admin,ou=people,dc=example,dc=com", map[string][]string{
+		"uid":      {"admin"},
+		"memberOf": {"CN=HR, OU=Groups, DC=example, DC=com"},
+	}))
+	dir.passwords["uid=admin,ou=people,dc=example,dc=com"] = "ldap-secret"
+
+	p := newTestLDAPProvider(dir)
+	p.cfg.SyncGroups = true
+	p.cfg.LocalGroups = []string{"admin"}
+
+	conn := openFakeDB(t, func(query string, args []driver.NamedValue) (fakeResult, error) {
+		switch {
+		case strings.HasPrefix(query, `SELECT "code" FROM "access_groups"`):
+			return fakeResult{columns: []string{"code"}, rows: [][]driver.Value{{"hr"}}}, nil
+		case strings.HasPrefix(query, `SELECT * FROM "users" WHERE LOWER(login) = $1`):
+			return fakeResult{
+				columns: []string{"id", "login", "password"},
+				rows:    [][]driver.Value{{int64(1), "admin", "local-hash"}},
+			}, nil
+		case strings.HasPrefix(query, `SELECT count(*) FROM "user_access_groups"`):
+			if args[0].Value != int64(1) || args[1].Value != "admin" {
+				t.Errorf("local group check args = %v", args)
+			}
+			return fakeResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}}}, nil
+		}
+		t.Errorf("unexpected query: %s", query)
+		return fakeResult{}, nil
+	})
+
+	// Запись каталога с логином резервного администратора не даёт входа
+	// и не меняет его группы: пароль проверит LocalProvider.
+	if _, err := p.Authenticate(context.Background(), conn, "admin", "ldap-secret"); !errors.Is(err, ErrUnknownUser) {
+		t.Fatalf("got %v, want ErrUnknownUser", err)
+	}
+}
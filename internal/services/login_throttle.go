@@ -0,0 +1,378 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrLoginThrottled — попытка сделана раньше, чем истекла пауза после предыдущей ошибки.
+	ErrLoginThrottled = errors.New("login throttled")
+	// ErrLoginLocked — логин или адрес временно заблокирован.
+	ErrLoginLocked = errors.New("login locked")
+)
+
+// AttemptState — состояние счётчика неудачных попыток для одного ключа.
+type AttemptState struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// AttemptStore хранит счётчики неудачных попыток входа.
+type AttemptStore interface {
+	// Get возвращает состояние ключа; для неизвестного ключа — нулевое состояние.
+	Get(key string) (AttemptState, error)
+	// Update атомарно изменяет состояние ключа.
+	Update(key string, fn func(*AttemptState)) (AttemptState, error)
+	// Delete сбрасывает счётчик ключа.
+	Delete(key string) error
+	// Locked возвращает ключи с указанным префиксом, заблокированные на момент now.
+	Locked(prefix string, now time.Time) ([]AttemptState, error)
+}
+
+// MemoryAttemptStore хранит счётчики в памяти процесса. Ключ с истёкшей
+// блокировкой или без блокировки и без ошибок дольше retention забывается;
+// число ключей ограничено maxEntries, при переполнении вытесняются самые старые.
+type MemoryAttemptStore struct {
+	retention  time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	items   map[string]AttemptState
+	sweptAt time.Time
+}
+
+// memoryAttemptSweepInterval — как часто Update удаляет устаревшие ключи.
+const memoryAttemptSweepInterval = time.Minute
+
+func NewMemoryAttemptStore(retention time.Duration, maxEntries int) *MemoryAttemptStore {
+	return &MemoryAttemptStore{
+		retention:  retention,
+		maxEntries: maxEntries,
+		items:      make(map[string]AttemptState),
+		sweptAt:    time.Now(),
+	}
+}
+
+func (s *MemoryAttemptStore) Get(key string) (AttemptState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(key, time.Now()), nil
+}
+
+func (s *MemoryAttemptStore) Update(key string, fn func(*AttemptState)) (AttemptState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.sweptAt) >= memoryAttemptSweepInterval {
+		s.sweep(now)
+	}
+	st := s.get(key, now)
+	if _, ok := s.items[key]; !ok && s.maxEntries > 0 && len(s.items) >= s.maxEntries {
+		s.sweep(now)
+		for len(s.items) >= s.maxEntries {
+			s.evictOldest()
+		}
+	}
+	fn(&st)
+	s.items[key] = st
+	return st, nil
+}
+
+func (s *MemoryAttemptStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	return nil
+}
+
+func (s *MemoryAttemptStore) Locked(prefix string, now time.Time) ([]AttemptState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []AttemptState
+	for key, st := range s.items {
+		if strings.HasPrefix(key, prefix) && st.LockedUntil != nil && st.LockedUntil.After(now) {
+			result = append(result, st)
+		}
+	}
+	return result, nil
+}
+
+// get возвращает состояние ключа, удаляя его, если оно устарело.
+func (s *MemoryAttemptStore) get(key string, now time.Time) AttemptState {
+	st, ok := s.items[key]
+	if ok && s.expired(st, now) {
+		delete(s.items, key)
+		ok = false
+	}
+	if !ok {
+		return AttemptState{Key: key}
+	}
+	return st
+}
+
+func (s *MemoryAttemptStore) expired(st AttemptState, now time.Time) bool {
+	if st.LockedUntil != nil {
+		return !st.LockedUntil.After(now)
+	}
+	return now.Sub(st.LastFailureAt) >= s.retention
+}
+
+func (s *MemoryAttemptStore) sweep(now time.Time) {
+	for key, st := range s.items {
+		if s.expired(st, now) {
+			delete(s.items, key)
+		}
+	}
+	s.sweptAt = now
+}
+
+// evictOldest вытесняет ключ с самой давней ошибкой; незаблокированные
+// ключи вытесняются раньше заблокированных.
+func (s *MemoryAttemptStore) evictOldest() {
+	var victim string
+	var oldest AttemptState
+	for key, st := range s.items {
+		if victim == "" || evictBefore(st, oldest) {
+			victim, oldest = key, st
+		}
+	}
+	delete(s.items, victim)
+}
+
+func evictBefore(a, b AttemptState) bool {
+	if (a.LockedUntil == nil) != (b.LockedUntil == nil) {
+		return a.LockedUntil == nil
+	}
+	return a.LastFailureAt.Before(b.LastFailureAt)
+}
+
+// PostgresAttemptStore хранит счётчики в таблице login_attempts,
+// поэтому они общие для всех экземпляров и переживают перезапуск.
+type PostgresAttemptStore struct {
+	conn *gorm.DB
+}
+
+func NewPostgresAttemptStore(conn *gorm.DB) *PostgresAttemptStore {
+	return &PostgresAttemptStore{conn: conn}
+}
+
+func (s *PostgresAttemptStore) Get(key string) (AttemptState, error) {
+	var row models.LoginAttempt
+	err := s.conn.Where("key = ?", key).Limit(1).Find(&row).Error
+	if err != nil {
+		return AttemptState{}, err
+	}
+	if row.Key == "" {
+		return AttemptState{Key: key}, nil
+	}
+	return attemptStateFromRow(row), nil
+}
+
+func (s *PostgresAttemptStore) Update(key string, fn func(*AttemptState)) (AttemptState, error) {
+	var st AttemptState
+	err := s.conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LoginAttempt{Key: key}).Error; err != nil {
+			return err
+		}
+
+		var row models.LoginAttempt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ?", key).First(&row).Error; err != nil {
+			return err
+		}
+
+		st = attemptStateFromRow(row)
+		fn(&st)
+
+		return tx.Model(&models.LoginAttempt{}).Where("key = ?", key).Updates(map[string]interface{}{
+			"failures":        st.Failures,
+			"last_failure_at": st.LastFailureAt,
+			"locked_until":    st.LockedUntil,
+			"updated_at":      time.Now(),
+		}).Error
+	})
+	return st, err
+}
+
+func (s *PostgresAttemptStore) Delete(key string) error {
+	return s.conn.Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+func (s *PostgresAttemptStore) Locked(prefix string, now time.Time) ([]AttemptState, error) {
+	var rows []models.LoginAttempt
+	if err := s.conn.
+		Where("key LIKE ? AND locked_until > ?", prefix+"%", now).
+		Order("locked_until DESC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make([]AttemptState, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, attemptStateFromRow(row))
+	}
+	return result, nil
+}
+
+func attemptStateFromRow(row models.LoginAttempt) AttemptState {
+	return AttemptState{
+		Key:           row.Key,
+		Failures:      row.Failures,
+		LastFailureAt: row.LastFailureAt,
+		LockedUntil:   row.LockedUntil,
+	}
+}
+
+// LoginThrottle ограничивает частоту попыток входа по логину и по IP-адресу:
+// после каждой ошибки следующая попытка возможна не раньше, чем через
+// экспоненциально растущую паузу, а после MaxFailures ошибок ключ
+// блокируется на LockoutDuration.
+type LoginThrottle struct {
+	Store           AttemptStore
+	MaxFailures     int
+	IPMaxFailures   int
+	LockoutDuration time.Duration
+	BackoffBase     time.Duration
+	BackoffMax      time.Duration
+}
+
+// DefaultMemoryAttemptEntries — предел числа ключей MemoryAttemptStore по умолчанию.
+const DefaultMemoryAttemptEntries = 100000
+
+const (
+	loginKeyPrefix = "login:"
+	ipKeyPrefix    = "ip:"
+)
+
+var loginThrottle = &LoginThrottle{
+	Store:           NewMemoryAttemptStore(15*time.Minute, DefaultMemoryAttemptEntries),
+	MaxFailures:     5,
+	IPMaxFailures:   50,
+	LockoutDuration: 15 * time.Minute,
+	BackoffBase:     time.Second,
+	BackoffMax:      time.Minute,
+}
+
+// InitLoginThrottle задаёт параметры защиты от подбора пароля.
+func InitLoginThrottle(t *LoginThrottle) {
+	loginThrottle = t
+}
+
+// LoginAttemptKey возвращает ключ счётчика для логина.
+func LoginAttemptKey(login string) string {
+	return loginKeyPrefix + strings.ToLower(strings.TrimSpace(login))
+}
+
+func ipAttemptKey(ip string) string {
+	return ipKeyPrefix + ip
+}
+
+// CheckLoginAllowed проверяет, можно ли сейчас пытаться войти с этим логином
+// и адресом. При отказе возвращает время, через которое стоит повторить.
+func CheckLoginAllowed(login, ip string, now time.Time) (time.Duration, error) {
+	t := loginThrottle
+	states := make([]AttemptState, 0, 2)
+	for _, key := range []string{LoginAttemptKey(login), ipAttemptKey(ip)} {
+		st, err := t.Store.Get(key)
+		if err != nil {
+			return 0, err
+		}
+		states = append(states, st)
+	}
+
+	var locked, throttled time.Duration
+	for _, st := range states {
+		if st.LockedUntil != nil {
+			if d := st.LockedUntil.Sub(now); d > locked {
+				locked = d
+			}
+			continue
+		}
+		if st.Failures == 0 {
+			continue
+		}
+		if d := st.LastFailureAt.Add(t.backoff(st.Failures)).Sub(now); d > throttled {
+			throttled = d
+		}
+	}
+
+	switch {
+	case locked > 0:
+		return locked, ErrLoginLocked
+	case throttled > 0:
+		return throttled, ErrLoginThrottled
+	}
+	return 0, nil
+}
+
+// RegisterLoginFailure учитывает неудачную попытку входа.
+func RegisterLoginFailure(login, ip string, now time.Time) error {
+	t := loginThrottle
+	if _, err := t.Store.Update(LoginAttemptKey(login), t.fail(t.MaxFailures, now)); err != nil {
+		return err
+	}
+	_, err := t.Store.Update(ipAttemptKey(ip), t.fail(t.IPMaxFailures, now))
+	return err
+}
+
+// RegisterLoginSuccess сбрасывает счётчик логина после успешного входа.
+// Счётчик адреса не сбрасывается, чтобы вход в собственную учётную запись
+// не обнулял перебор чужих с того же адреса.
+func RegisterLoginSuccess(login string) error {
+	return loginThrottle.Store.Delete(LoginAttemptKey(login))
+}
+
+// UnlockLogin снимает блокировку логина.
+func UnlockLogin(login string) error {
+	return loginThrottle.Store.Delete(LoginAttemptKey(login))
+}
+
+// LockedLogins возвращает заблокированные логины.
+func LockedLogins(now time.Time) (map[string]AttemptState, error) {
+	states, err := loginThrottle.Store.Locked(loginKeyPrefix, now)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]AttemptState, len(states))
+	for _, st := range states {
+		result[strings.TrimPrefix(st.Key, loginKeyPrefix)] = st
+	}
+	return result, nil
+}
+
+func (t *LoginThrottle) fail(max int, now time.Time) func(*AttemptState) {
+	return func(st *AttemptState) {
+		// После окончания блокировки счёт начинается заново.
+		if st.LockedUntil != nil && !st.LockedUntil.After(now) {
+			st.Failures = 0
+			st.LockedUntil = nil
+		}
+		st.Failures++
+		st.LastFailureAt = now
+		if max > 0 && st.Failures >= max {
+			until := now.Add(t.LockoutDuration)
+			st.LockedUntil = &until
+		}
+	}
+}
+
+func (t *LoginThrottle) backoff(failures int) time.Duration {
+	d := t.BackoffBase
+	for i := 1; i < failures && d < t.BackoffMax; i++ {
+		d *= 2
+	}
+	if d > t.BackoffMax {
+		d = t.BackoffMax
+	}
+	return d
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func failAt(at time.Time, locked *time.Time) func(*AttemptState) {
+	return func(st *AttemptState) {
+		st.Failures++
+		st.LastFailureAt = at
+		st.LockedUntil = locked
+	}
+}
+
+func TestMemoryAttemptStoreExpiry(t *testing.T) {
+	s := NewMemoryAttemptStore(15*time.Minute, 0)
+	now := time.Now()
+	past, future := now.Add(-time.Second), now.Add(time.Hour)
+
+	s.Update("login:fresh", failAt(now, nil))
+	s.Update("login:stale", failAt(now.Add(-16*time.Minute), nil))
+	s.Update("login:unlocked", failAt(now.Add(-time.Hour), &past))
+	s.Update("login:locked", failAt(now.Add(-time.Hour), &future))
+
+	for key, want := range map[string]int{
+		"login:fresh":    1,
+		"login:stale":    0,
+		"login:unlocked": 0,
+		"login:locked":   1,
+	} {
+		st, _ := s.Get(key)
+		if st.Failures != want || st.Key != key {
+			t.Errorf("%s: %+v, want %d failures", key, st, want)
+		}
+	}
+	if len(s.items) != 2 {
+		t.Errorf("expired keys kept: %v", s.items)
+	}
+}
+
+func TestMemoryAttemptStoreSweep(t *testing.T) {
+	s := NewMemoryAttemptStore(15*time.Minute, 0)
+	now := time.Now()
+	s.Update("ip:10.0.0.1", failAt(now.Add(-time.Hour), nil))
+
+	s.sweptAt = now.Add(-memoryAttemptSweepInterval)
+	s.Update("ip:10.0.0.2", failAt(now, nil))
+	if _, ok := s.items["ip:10.0.0.1"]; ok {
+		t.Error("stale key not swept")
+	}
+}
+
+func TestMemoryAttemptStoreLimit(t *testing.T) {
+	s := NewMemoryAttemptStore(time.Hour, 3)
+	now := time.Now()
+	future := now.Add(time.Hour)
+
+	s.Update("login:locked", failAt(now.Add(-30*time.Minute), &future))
+	s.Update("login:old", failAt(now.Add(-20*time.Minute), nil))
+	s.Update("login:recent", failAt(now.Add(-time.Minute), nil))
+	s.Update("login:new", failAt(now, nil))
+
+	if len(s.items) != 3 {
+		t.Fatalf("len = %d, want 3", len(s.items))
+	}
+	if _, ok := s.items["login:old"]; ok {
+		t.Error("oldest unlocked key not evicted")
+	}
+	for _, key := range []string{"login:locked", "login:recent", "login:new"} {
+		if _, ok := s.items[key]; !ok {
+			t.Errorf("%s evicted", key)
+		}
+	}
+
+	// Обновление существующего ключа не вытесняет другие.
+	s.Update("login:new", failAt(now, nil))
+	if len(s.items) != 3 {
+		t.Errorf("len = %d after updating an existing key", len(s.items))
+	}
+}
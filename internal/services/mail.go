@@ -0,0 +1,69 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MailMessage — письмо пользователю.
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// MailSender отправляет письма. Реализация выбирается в конфигурации.
+type MailSender interface {
+	Send(msg MailMessage) error
+}
+
+// LogMailSender выводит письма в лог приложения. Подходит для разработки.
+type LogMailSender struct{}
+
+func (LogMailSender) Send(msg MailMessage) error {
+	log.Printf("mail to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileMailSender сохраняет каждое письмо в отдельный .eml файл в каталоге Dir.
+type FileMailSender struct {
+	Dir string
+}
+
+func (s FileMailSender) Send(msg MailMessage) error {
+	if err := os.MkdirAll(s.Dir, 0o750); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102-150405.000000000"), sanitizeFileName(msg.To))
+	content := fmt.Sprintf("Date: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		now.Format(time.RFC1123Z), msg.To, msg.Subject, msg.Body)
+
+	return os.WriteFile(filepath.Join(s.Dir, name), []byte(content), 0o640)
+}
+
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+var mailSender MailSender = LogMailSender{}
+
+// InitMailSender задаёт способ отправки писем.
+func InitMailSender(s MailSender) {
+	mailSender = s
+}
+
+// SendMail отправляет письмо через настроенный MailSender.
+func SendMail(msg MailMessage) error {
+	return mailSender.Send(msg)
+}
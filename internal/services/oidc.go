@@ -0,0 +1,467 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrOIDCDisabled     = errors.New("oidc login is not configured")
+	ErrOIDCTokenInvalid = errors.New("oidc id token invalid")
+)
+
+// OIDCConfig — параметры входа через внешний OIDC-провайдер.
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL — адрес /auth/oidc/callback этого сервиса, зарегистрированный у провайдера.
+	RedirectURL string
+	Scopes      []string
+	// GroupsClaim — claim с группами пользователя; вложенные claim задаются через точку
+	// (например, realm_access.roles).
+	GroupsClaim string
+	// GroupMap сопоставляет группы провайдера кодам AccessGroup. Если пуст,
+	// группы провайдера сравниваются с кодами напрямую.
+	GroupMap map[string]string
+	// AutoProvision разрешает создавать пользователя и сотрудника при первом входе.
+	AutoProvision bool
+	// SyncGroups заменяет группы пользователя сопоставленными при каждом входе.
+	SyncGroups bool
+	// TrustedMFA — значения claim amr или acr, при которых вход у провайдера считается
+	// многофакторным и собственная 2FA не запрашивается. Если пуст, 2FA запрашивается всегда.
+	TrustedMFA []string
+	// FrontendRedirectURL — страница фронтенда, которой передаются токены
+	// (во фрагменте URL) или код ошибки после входа.
+	FrontendRedirectURL string
+}
+
+// OIDCIdentity — проверенные данные пользователя из ID-токена.
+type OIDCIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	GivenName     string
+	FamilyName    string
+	Groups        []string
+	// AMR и ACR — способы и уровень аутентификации у провайдера.
+	AMR []string
+	ACR string
+}
+
+type oidcMetadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	TokenAuthMethods      []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// OIDCProvider выполняет authorization code flow с PKCE. Метаданные провайдера
+// загружаются при первом обращении, поэтому недоступность провайдера
+// при старте не мешает запуску приложения.
+type OIDCProvider struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	meta          *oidcMetadata
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+// oidcKeysMinRefresh ограничивает частоту перезагрузки ключей провайдера
+// при встрече неизвестного kid.
+const oidcKeysMinRefresh = time.Minute
+
+func NewOIDCProvider(cfg OIDCConfig, client *http.Client) *OIDCProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	cfg.Issuer = strings.TrimRight(cfg.Issuer, "/")
+	return &OIDCProvider{cfg: cfg, client: client}
+}
+
+var oidcProvider *OIDCProvider
+
+// InitOIDC включает вход через OIDC; nil отключает его.
+func InitOIDC(p *OIDCProvider) {
+	oidcProvider = p
+}
+
+// OIDC возвращает настроенный провайдер или ErrOIDCDisabled.
+func OIDC() (*OIDCProvider, error) {
+	if oidcProvider == nil {
+		return nil, ErrOIDCDisabled
+	}
+	return oidcProvider, nil
+}
+
+func (p *OIDCProvider) Config() OIDCConfig {
+	return p.cfg
+}
+
+// AuthCodeURL возвращает адрес страницы входа провайдера.
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", PKCEChallenge(verifier))
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange обменивает код авторизации на ID-токен и проверяет его:
+// подпись, issuer, audience, срок действия и nonce.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error) {
+	meta, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", p.cfg.ClientID)
+
+	basic := len(meta.TokenAuthMethods) == 0 || containsString(meta.TokenAuthMethods, "client_secret_basic")
+	if !basic {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if basic {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var tokenResp struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.doJSON(req, &tokenResp)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK || tokenResp.IDToken == "" {
+		return nil, fmt.Errorf("oidc token endpoint: status %d: %s %s", status, tokenResp.Error, tokenResp.ErrorDescription)
+	}
+
+	return p.verifyIDToken(ctx, meta, tokenResp.IDToken, nonce)
+}
+
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, meta *oidcMetadata, raw, nonce string) (*OIDCIdentity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCTokenInvalid, err)
+	}
+
+	if got, _ := claims["nonce"].(string); got == "" || got != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOIDCTokenInvalid)
+	}
+
+	identity := &OIDCIdentity{Issuer: meta.Issuer}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+	identity.GivenName, _ = claims["given_name"].(string)
+	identity.FamilyName, _ = claims["family_name"].(string)
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = v
+	case string:
+		identity.EmailVerified = v == "true"
+	}
+	identity.Groups = claimStrings(claims, p.cfg.GroupsClaim)
+	identity.AMR = claimStrings(claims, "amr")
+	identity.ACR, _ = claims["acr"].(string)
+
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrOIDCTokenInvalid)
+	}
+	return identity, nil
+}
+
+// MFAVerified сообщает, подтвердил ли провайдер многофакторный вход
+// одним из значений TrustedMFA в amr или acr.
+func (p *OIDCProvider) MFAVerified(identity *OIDCIdentity) bool {
+	for _, v := range p.cfg.TrustedMFA {
+		if containsString(identity.AMR, v) || identity.ACR == v {
+			return true
+		}
+	}
+	return false
+}
+
+// MapGroups переводит группы провайдера в коды AccessGroup.
+func (p *OIDCProvider) MapGroups(groups []string) []string {
+	var result []string
+	for _, g := range groups {
+		code := g
+		if len(p.cfg.GroupMap) > 0 {
+			var ok bool
+			if code, ok = p.cfg.GroupMap[g]; !ok {
+				continue
+			}
+		}
+		if !containsString(result, code) {
+			result = append(result, code)
+		}
+	}
+	return result
+}
+
+func (p *OIDCProvider) metadata(ctx context.Context) (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var meta oidcMetadata
+	status, err := p.doJSON(req, &meta)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc discovery: status %d", status)
+	}
+	if strings.TrimRight(meta.Issuer, "/") != p.cfg.Issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match configured %q", meta.Issuer, p.cfg.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: incomplete provider metadata")
+	}
+
+	p.meta = &meta
+	return p.meta, nil
+}
+
+// key возвращает открытый ключ провайдера по kid, при необходимости
+// перезагружая набор ключей (ротация на стороне провайдера).
+func (p *OIDCProvider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if p.keys != nil && time.Since(p.keysFetchedAt) < oidcKeysMinRefresh {
+		return nil, fmt.Errorf("oidc: unknown key %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.meta.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set models.JWKSet
+	status, err := p.doJSON(req, &set)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc jwks: status %d", status)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if pub, err := jwkPublicKey(jwk); err == nil {
+			keys[jwk.Kid] = pub
+		}
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("oidc: unknown key %q", kid)
+}
+
+func (p *OIDCProvider) lookupKey(kid string) (interface{}, bool) {
+	if key, ok := p.keys[kid]; ok {
+		return key, true
+	}
+	// Токен без kid допустим, если у провайдера единственный ключ.
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+func (p *OIDCProvider) doJSON(req *http.Request, dst interface{}) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, dst); err != nil && resp.StatusCode == http.StatusOK {
+			return resp.StatusCode, err
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// PKCEChallenge вычисляет code_challenge по методу S256 (RFC 7636).
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func jwkPublicKey(jwk models.JWK) (interface{}, error) {
+	b64 := base64.RawURLEncoding
+	switch jwk.Kty {
+	case "RSA":
+		n, err := b64.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := b64.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := b64.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := b64.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := b64.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+}
+
+// claimStrings извлекает список строк из claim; путь к вложенному claim
+// задаётся через точку. Поддерживаются массив строк и строка через пробел.
+func claimStrings(claims map[string]interface{}, path string) []string {
+	var value interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[part]
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	case string:
+		return strings.Fields(v)
+	}
+	return nil
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
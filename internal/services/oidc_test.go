@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testOIDCClientID = "dashboard"
+	testOIDCNonce    = "nonce-1"
+)
+
+// mockOIDC — провайдер OIDC на httptest.Server: discovery, JWKS и token endpoint.
+// Token endpoint выдаёт ID-токен, собранный из claims и подписанный ключом signKey с kid.
+type mockOIDC struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	signKey *rsa.PrivateKey
+	kid     string
+	claims  jwt.MapClaims
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockOIDC{key: key, signKey: key, kid: "k1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		b64 := base64.RawURLEncoding
+		json.NewEncoder(w).Encode(models.JWKSet{Keys: []models.JWK{{
+			Kty: "RSA",
+			Kid: "k1",
+			Use: "sig",
+			Alg: "RS256",
+			N:   b64.EncodeToString(key.N.Bytes()),
+			E:   b64.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("code") != "code-1" || r.PostFormValue("code_verifier") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, m.claims)
+		token.Header["kid"] = m.kid
+		raw, err := token.SignedString(m.signKey)
+		if err != nil {
+			t.Error(err)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": raw})
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *mockOIDC) validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            m.server.URL,
+		"aud":            testOIDCClientID,
+		"sub":            "user-1",
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          testOIDCNonce,
+		"email":          "Ivanov@Example.com",
+		"email_verified": true,
+		"groups":         []string{"hr-team", "staff"},
+		"amr":            []string{"pwd", "otp"},
+	}
+}
+
+func (m *mockOIDC) provider() *OIDCProvider {
+	return NewOIDCProvider(OIDCConfig{
+		Issuer:       m.server.URL,
+		ClientID:     testOIDCClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/auth/oidc/callback",
+	}, m.server.Client())
+}
+
+func TestOIDCExchangeValidToken(t *testing.T) {
+	m := newMockOIDC(t)
+	m.claims = m.validClaims()
+
+	identity, err := m.provider().Exchange(context.Background(), "code-1", "verifier", testOIDCNonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if identity.Issuer != m.server.URL || identity.Subject != "user-1" || !identity.EmailVerified {
+		t.Errorf("identity = %+v", identity)
+	}
+	if !reflect.DeepEqual(identity.Groups, []string{"hr-team", "staff"}) {
+		t.Errorf("groups = %v", identity.Groups)
+	}
+	if !reflect.DeepEqual(identity.AMR, []string{"pwd", "otp"}) {
+		t.Errorf("amr = %v", identity.AMR)
+	}
+}
+
+func TestOIDCExchangeRejectsInvalidToken(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		nonce string
+		setup func(m *mockOIDC)
+	}{
+		{"bad nonce", "other-nonce", func(m *mockOIDC) {}},
+		{"missing nonce", testOIDCNonce, func(m *mockOIDC) { delete(m.claims, "nonce") }},
+		{"wrong aud", testOIDCNonce, func(m *mockOIDC) { m.claims["aud"] = "another-client" }},
+		{"wrong iss", testOIDCNonce, func(m *mockOIDC) { m.claims["iss"] = "https://evil.example.com" }},
+		{"expired", testOIDCNonce, func(m *mockOIDC) { m.claims["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"missing exp", testOIDCNonce, func(m *mockOIDC) { delete(m.claims, "exp") }},
+		{"unknown kid", testOIDCNonce, func(m *mockOIDC) { m.kid, m.signKey = "k2", otherKey }},
+		{"wrong key for kid", testOIDCNonce, func(m *mockOIDC) { m.signKey = otherKey }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newMockOIDC(t)
+			m.claims = m.validClaims()
+			tc.setup(m)
+
+			_, err := m.provider().Exchange(context.Background(), "code-1", "verifier", tc.nonce)
+			if !errors.Is(err, ErrOIDCTokenInvalid) {
+				t.Fatalf("got %v, want ErrOIDCTokenInvalid", err)
+			}
+		})
+	}
+}
+
+func TestOIDCExchangeTokenEndpointError(t *testing.T) {
+	m := newMockOIDC(t)
+	m.claims = m.validClaims()
+
+	_, err := m.provider().Exchange(context.Background(), "bad-code", "verifier", testOIDCNonce)
+	if err == nil || errors.Is(err, ErrOIDCTokenInvalid) {
+		t.Fatalf("got %v, want token endpoint error", err)
+	}
+}
+
+func TestOIDCMapGroups(t *testing.T) {
+	direct := NewOIDCProvider(OIDCConfig{}, nil)
+	if got := direct.MapGroups([]string{"admin", "hr", "admin"}); !reflect.DeepEqual(got, []string{"admin", "hr"}) {
+		t.Errorf("without GroupMap: %v", got)
+	}
+
+	mapped := NewOIDCProvider(OIDCConfig{GroupMap: map[string]string{
+		"hr-team":  "hr",
+		"hr-leads": "hr",
+		"it":       "admin",
+	}}, nil)
+	got := mapped.MapGroups([]string{"hr-team", "staff", "hr-leads", "it"})
+	if !reflect.DeepEqual(got, []string{"hr", "admin"}) {
+		t.Errorf("with GroupMap: %v", got)
+	}
+}
+
+// oidcUserStore отвечает на запросы ResolveOIDCUser: сохранённой связи нет,
+// пользователь с логином login существует.
+type oidcUserStore struct {
+	login   string
+	queries []string
+}
+
+func (s *oidcUserStore) handle(query string, args []driver.NamedValue) (fakeResult, error) {
+	s.queries = append(s.queries, query)
+	user := fakeResult{columns: []string{"id", "login"}, rows: [][]driver.Value{{int64(7), s.login}}}
+	switch {
+	case strings.HasPrefix(query, `SELECT * FROM "users" WHERE LOWER(login) = $1`):
+		if args[0].Value == strings.ToLower(s.login) {
+			return user, nil
+		}
+	case strings.HasPrefix(query, `SELECT * FROM "users" WHERE "users"."id" = $1`):
+		return user, nil
+	case strings.HasPrefix(query, `INSERT INTO "user_identities"`):
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}, nil
+	case strings.HasPrefix(query, "UPDATE"):
+		return fakeResult{rowsAffected: 1}, nil
+	}
+	return fakeResult{}, nil
+}
+
+func (s *oidcUserStore) queried(prefix string) bool {
+	for _, q := range s.queries {
+		if strings.HasPrefix(q, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestResolveOIDCUserLinksVerifiedEmailOnly(t *testing.T) {
+	p := NewOIDCProvider(OIDCConfig{Issuer: "https://idp.example.com"}, nil)
+	identity := func(verified bool) *OIDCIdentity {
+		return &OIDCIdentity{
+			Issuer:        "https://idp.example.com",
+			Subject:       "user-1",
+			Email:         "Ivanov@Example.com",
+			EmailVerified: verified,
+		}
+	}
+
+	t.Run("unverified", func(t *testing.T) {
+		store := &oidcUserStore{login: "ivanov@example.com"}
+		_, err := ResolveOIDCUser(openFakeDB(t, store.handle), p, identity(false))
+		if !errors.Is(err, ErrOIDCUserNotFound) {
+			t.Fatalf("got %v, want ErrOIDCUserNotFound", err)
+		}
+		if store.queried(`SELECT * FROM "users" WHERE LOWER(login)`) {
+			t.Error("user looked up by unverified email")
+		}
+		if store.queried(`INSERT INTO "user_identities"`) {
+			t.Error("identity linked by unverified email")
+		}
+	})
+
+	t.Run("verified", func(t *testing.T) {
+		store := &oidcUserStore{login: "ivanov@example.com"}
+		user, err := ResolveOIDCUser(openFakeDB(t, store.handle), p, identity(true))
+		if err != nil {
+			t.Fatalf("ResolveOIDCUser: %v", err)
+		}
+		if user.ID != 7 {
+			t.Errorf("user ID = %d, want 7", user.ID)
+		}
+		if !store.queried(`INSERT INTO "user_identities"`) {
+			t.Error("identity was not linked")
+		}
+	})
+
+	t.Run("verified, other login", func(t *testing.T) {
+		store := &oidcUserStore{login: "petrov@example.com"}
+		_, err := ResolveOIDCUser(openFakeDB(t, store.handle), p, identity(true))
+		if !errors.Is(err, ErrOIDCUserNotFound) {
+			t.Fatalf("got %v, want ErrOIDCUserNotFound", err)
+		}
+	})
+}
+
+func TestOIDCMFAVerified(t *testing.T) {
+	identity := &OIDCIdentity{AMR: []string{"pwd", "otp"}, ACR: "urn:example:loa:1"}
+
+	if NewOIDCProvider(OIDCConfig{}, nil).MFAVerified(identity) {
+		t.Error("MFA trusted without TrustedMFA")
+	}
+	if NewOIDCProvider(OIDCConfig{TrustedMFA: []string{"mfa", "hwk"}}, nil).MFAVerified(identity) {
+		t.Error("MFA trusted without a matching amr")
+	}
+	if !NewOIDCProvider(OIDCConfig{TrustedMFA: []string{"otp"}}, nil).MFAVerified(identity) {
+		t.Error("amr otp not trusted")
+	}
+	if !NewOIDCProvider(OIDCConfig{TrustedMFA: []string{"urn:example:loa:1"}}, nil).MFAVerified(identity) {
+		t.Error("acr not trusted")
+	}
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOIDCUserNotFound = errors.New("no user linked to oidc identity")
+	ErrOIDCUserDisabled = errors.New("user linked to oidc identity is disabled")
+)
+
+// ResolveOIDCUser находит пользователя для внешней учётной записи:
+// по сохранённой связи issuer+subject, затем по подтверждённому email
+// (совпадающему с логином), затем — если разрешено — создаёт нового
+// пользователя и сотрудника. Группы провайдера сопоставляются группам доступа.
+func ResolveOIDCUser(tx *gorm.DB, p *OIDCProvider, identity *OIDCIdentity) (models.User, error) {
+	cfg := p.Config()
+	var user models.User
+
+	groups, err := existingGroupCodes(tx, p.MapGroups(identity.Groups))
+	if err != nil {
+		return user, err
+	}
+
+	var link models.UserIdentity
+	err = tx.Where("issuer = ? AND subject = ?", identity.Issuer, identity.Subject).First(&link).Error
+	switch {
+	case err == nil:
+		if err := tx.First(&user, link.UserID).Error; err != nil {
+			return user, err
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		found, err := findUserByEmail(tx, identity)
+		if err != nil {
+			return user, err
+		}
+		if found != nil {
+			user = *found
+		} else {
+			if !cfg.AutoProvision {
+				return user, ErrOIDCUserNotFound
+			}
+			if user, err = provisionOIDCUser(tx, identity, groups); err != nil {
+				return user, err
+			}
+		}
+
+		link = models.UserIdentity{UserID: user.ID, Issuer: identity.Issuer, Subject: identity.Subject}
+		if err := tx.Create(&link).Error; err != nil {
+			return user, err
+		}
+	default:
+		return user, err
+	}
+
+	if user.DeletedAt != nil {
+		return user, ErrOIDCUserDisabled
+	}
+
+	now := time.Now()
+	if err := tx.Model(&link).Updates(map[string]interface{}{
+		"email":         identity.Email,
+		"last_login_at": now,
+	}).Error; err != nil {
+		return user, err
+	}
+
+	if cfg.SyncGroups {
+		if err := syncExternalGroups(tx, user.ID, groups); err != nil {
+			return user, err
+		}
+	}
+
+	err = tx.Preload("AccessGroups.AccessGroup").First(&user, user.ID).Error
+	return user, err
+}
+
+func findUserByEmail(tx *gorm.DB, identity *OIDCIdentity) (*models.User, error) {
+	// Привязка по email допустима, только если провайдер подтвердил адрес.
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, nil
+	}
+
+	var user models.User
+	err := tx.Where("LOWER(login) = ?", strings.ToLower(identity.Email)).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func provisionOIDCUser(tx *gorm.DB, identity *OIDCIdentity, groups []string) (models.User, error) {
+	login := strings.ToLower(identity.Email)
+	if login == "" || !identity.EmailVerified {
+		login = "oidc:" + identity.Subject
+	}
+
+	lastName, firstName := identity.FamilyName, identity.GivenName
+	if lastName == "" && firstName == "" {
+		lastName, firstName = splitDisplayName(identity.Name)
+	}
+	return provisionExternalUser(tx, login, lastName, firstName, groups)
+}
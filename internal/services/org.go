@@ -0,0 +1,76 @@
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrManagerCycle   = errors.New("manager assignment creates a reporting cycle")
+	ErrManagerInvalid = errors.New("manager not found or terminated")
+)
+
+// subtreeSQL выбирает подчинённых сотрудника (прямых и косвенных) по текущим
+// кадровым записям; depth = 1 у прямых подчинённых. Глубина обхода ограничена
+// 50 уровнями на случай цикла, записанного в обход ValidateManager.
+const subtreeSQL = `WITH RECURSIVE subtree AS (
+	SELECT employee_id, 1 AS depth FROM employee_hrs
+	WHERE manager_id = ? AND valid_to IS NULL AND deleted_at IS NULL
+	UNION ALL
+	SELECT h.employee_id, s.depth + 1 FROM employee_hrs h
+	JOIN subtree s ON h.manager_id = s.employee_id
+	WHERE h.valid_to IS NULL AND h.deleted_at IS NULL AND s.depth < 50
+) SELECT employee_id, MIN(depth) AS depth FROM subtree GROUP BY employee_id`
+
+// Subtree возвращает подчинённых сотрудника: ID → уровень (1 — прямые подчинённые).
+func Subtree(conn *gorm.DB, managerID uint) (map[uint]int, error) {
+	var rows []struct {
+		EmployeeID uint
+		Depth      int
+	}
+	if err := conn.Raw(subtreeSQL, managerID).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[uint]int, len(rows))
+	for _, r := range rows {
+		result[r.EmployeeID] = r.Depth
+	}
+	return result, nil
+}
+
+// InSubtree проверяет, подчиняется ли employeeID (прямо или косвенно) managerID.
+func InSubtree(conn *gorm.DB, managerID, employeeID uint) (bool, error) {
+	var found bool
+	err := conn.Raw("SELECT EXISTS (SELECT 1 FROM ("+subtreeSQL+") t WHERE t.employee_id = ?)", managerID, employeeID).
+		Scan(&found).Error
+	return found, err
+}
+
+// ValidateManager проверяет, что managerID может стать руководителем employeeID:
+// руководитель работает (не уволен) и не подчиняется самому сотруднику.
+func ValidateManager(conn *gorm.DB, employeeID, managerID uint) error {
+	if managerID == employeeID {
+		return ErrManagerCycle
+	}
+
+	manager, err := CurrentEmployment(conn, managerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrManagerInvalid
+	}
+	if err != nil {
+		return err
+	}
+	if manager.FireDate != nil {
+		return ErrManagerInvalid
+	}
+
+	cycle, err := InSubtree(conn, employeeID, managerID)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return ErrManagerCycle
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrPasswordReused = errors.New("password was used recently")
+
+// PasswordViolation — нарушенное требование к паролю.
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError возвращается, если пароль не соответствует политике.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	codes := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		codes = append(codes, v.Code)
+	}
+	return "password policy violated: " + strings.Join(codes, ", ")
+}
+
+// PasswordPolicy — требования к паролям пользователей.
+type PasswordPolicy struct {
+	MinLength int
+	// MinClasses — сколько классов символов из четырёх (строчные, заглавные,
+	// цифры, прочие) должно присутствовать в пароле.
+	MinClasses int
+	// History — сколько последних паролей нельзя использовать повторно.
+	History  int
+	DenyList map[string]struct{}
+}
+
+// defaultDenyList — самые распространённые пароли; дополняется файлом из конфигурации.
+var defaultDenyList = []string{
+	"password", "password1", "password123", "passw0rd", "p@ssw0rd",
+	"12345678", "123456789", "1234567890", "87654321", "11111111", "00000000",
+	"qwerty", "qwerty123", "qwertyuiop", "1q2w3e4r", "1q2w3e4r5t", "zaq12wsx",
+	"abc12345", "admin123", "administrator", "iloveyou", "welcome1", "letmein",
+	"changeme", "secret123", "йцукен", "йцукенгш", "пароль", "пароль123",
+}
+
+var passwordPolicy = &PasswordPolicy{
+	MinLength:  8,
+	MinClasses: 3,
+	History:    5,
+	DenyList:   makeDenyList(nil),
+}
+
+// InitPasswordPolicy задаёт политику паролей.
+func InitPasswordPolicy(p *PasswordPolicy) {
+	if p.DenyList == nil {
+		p.DenyList = makeDenyList(nil)
+	}
+	passwordPolicy = p
+}
+
+// LoadDenyList читает запрещённые пароли из файла (по одному в строке)
+// и добавляет к ним встроенный список.
+func LoadDenyList(path string) (map[string]struct{}, error) {
+	if path == "" {
+		return makeDenyList(nil), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var extra []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			extra = append(extra, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return makeDenyList(extra), nil
+}
+
+func makeDenyList(extra []string) map[string]struct{} {
+	list := make(map[string]struct{}, len(defaultDenyList)+len(extra))
+	for _, items := range [][]string{defaultDenyList, extra} {
+		for _, p := range items {
+			list[strings.ToLower(p)] = struct{}{}
+		}
+	}
+	return list
+}
+
+// ValidatePassword проверяет пароль на соответствие политике.
+// login используется, чтобы запретить пароли, содержащие логин.
+func ValidatePassword(password, login string) error {
+	p := passwordPolicy
+	var violations []PasswordViolation
+
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, PasswordViolation{
+			Code:    "too_short",
+			Message: fmt.Sprintf("Пароль должен содержать не менее %d символов", p.MinLength),
+		})
+	}
+
+	if classes := passwordClasses(password); classes < p.MinClasses {
+		violations = append(violations, PasswordViolation{
+			Code: "too_simple",
+			Message: fmt.Sprintf("Пароль должен содержать символы не менее %d типов из четырёх: строчные и заглавные буквы, цифры, спецсимволы",
+				p.MinClasses),
+		})
+	}
+
+	lower := strings.ToLower(password)
+	if _, denied := p.DenyList[lower]; denied {
+		violations = append(violations, PasswordViolation{
+			Code:    "too_common",
+			Message: "Пароль слишком распространён",
+		})
+	}
+
+	name := strings.ToLower(login)
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	if len([]rune(name)) >= 3 && strings.Contains(lower, name) {
+		violations = append(violations, PasswordViolation{
+			Code:    "contains_login",
+			Message: "Пароль не должен содержать логин",
+		})
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func passwordClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	n := 0
+	for _, ok := range []bool{lower, upper, digit, other} {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// SetPassword проверяет пароль по политике и истории, сохраняет его хеш
+// и снимает требование смены пароля. Сессии пользователя не отзываются —
+// это решает вызывающий.
+func SetPassword(tx *gorm.DB, user models.User, password string) error {
+	if err := ValidatePassword(password, user.Login); err != nil {
+		return err
+	}
+
+	reused, err := passwordReused(tx, user, password)
+	if err != nil {
+		return err
+	}
+	if reused {
+		return ErrPasswordReused
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"password":             hash,
+		"must_change_password": false,
+		"password_changed_at":  time.Now(),
+	}).Error; err != nil {
+		return err
+	}
+
+	return RecordPasswordHistory(tx, user.ID, hash)
+}
+
+// RecordPasswordHistory добавляет хеш в историю паролей и удаляет записи
+// старше глубины, заданной политикой.
+func RecordPasswordHistory(tx *gorm.DB, userID uint, hash string) error {
+	if err := tx.Create(&models.PasswordHistory{UserID: userID, Hash: hash}).Error; err != nil {
+		return err
+	}
+
+	keep := passwordPolicy.History
+	if keep < 1 {
+		keep = 1
+	}
+	return tx.Where("user_id = ? AND id NOT IN (?)", userID,
+		tx.Model(&models.PasswordHistory{}).
+			Select("id").
+			Where("user_id = ?", userID).
+			Order("id DESC").
+			Limit(keep),
+	).Delete(&models.PasswordHistory{}).Error
+}
+
+func passwordReused(tx *gorm.DB, user models.User, password string) (bool, error) {
+	if passwordPolicy.History <= 0 {
+		return false, nil
+	}
+
+	var hashes []string
+	if err := tx.Model(&models.PasswordHistory{}).
+		Where("user_id = ?", user.ID).
+		Order("id DESC").
+		Limit(passwordPolicy.History).
+		Pluck("hash", &hashes).Error; err != nil {
+		return false, err
+	}
+	// Для пользователей, созданных до появления истории, проверяется хотя бы текущий пароль.
+	if len(hashes) == 0 && user.Password != "" {
+		hashes = append(hashes, user.Password)
+	}
+
+	for _, h := range hashes {
+		if CheckPassword(password, h) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
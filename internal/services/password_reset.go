@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrResetTokenInvalid = errors.New("password reset token invalid or expired")
+	ErrNoEmail           = errors.New("user has no email address")
+)
+
+// ResetPendingPassword — значение хеша пароля, пока пользователь не задал новый
+// пароль по ссылке из письма; ни один пароль с ним не совпадает.
+const ResetPendingPassword = ""
+
+var (
+	passwordResetTTL = 24 * time.Hour
+	appBaseURL       = "http://localhost:5173"
+)
+
+// InitPasswordReset задаёт срок действия токена сброса и адрес фронтенда для ссылки в письме.
+func InitPasswordReset(ttl time.Duration, baseURL string) {
+	if ttl > 0 {
+		passwordResetTTL = ttl
+	}
+	if baseURL != "" {
+		appBaseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// UserEmail возвращает адрес для писем пользователю. Логином пользователей,
+// зарегистрированных самостоятельно, является email.
+func UserEmail(user models.User) (string, error) {
+	addr, err := mail.ParseAddress(user.Login)
+	if err != nil {
+		return "", ErrNoEmail
+	}
+	return addr.Address, nil
+}
+
+// StartPasswordReset выдаёт одноразовый токен сброса, делает текущий пароль
+// недействительным и завершает все сессии: задать новый пароль можно только по
+// ссылке из письма. Возвращает письмо, которое вызывающий отправляет через
+// SendMail после фиксации транзакции tx, чтобы медленный почтовый сервер не
+// держал блокировки.
+func StartPasswordReset(tx *gorm.DB, user models.User, createdBy uint) (MailMessage, time.Time, error) {
+	email, err := UserEmail(user)
+	if err != nil {
+		return MailMessage{}, time.Time{}, err
+	}
+
+	raw, err := RandomToken(32)
+	if err != nil {
+		return MailMessage{}, time.Time{}, err
+	}
+
+	// Ранее выданные токены перестают действовать.
+	if err := tx.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", user.ID).
+		Update("used_at", time.Now()).Error; err != nil {
+		return MailMessage{}, time.Time{}, err
+	}
+
+	token := models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: HashToken(raw),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}
+	if createdBy != 0 {
+		token.CreatedBy = &createdBy
+	}
+	if err := tx.Create(&token).Error; err != nil {
+		return MailMessage{}, time.Time{}, err
+	}
+
+	// Со старым паролем войти больше нельзя: иначе тот, кто его знает, задал бы
+	// новый пароль через обязательную смену при входе, минуя ссылку.
+	if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"password":             ResetPendingPassword,
+		"must_change_password": true,
+	}).Error; err != nil {
+		return MailMessage{}, time.Time{}, err
+	}
+	if err := RevokeUserTokens(tx, user.ID); err != nil {
+		return MailMessage{}, time.Time{}, err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", appBaseURL, raw)
+	msg := MailMessage{
+		To:      email,
+		Subject: "Сброс пароля",
+		Body: fmt.Sprintf("Администратор сбросил пароль вашей учётной записи %s.\n\n"+
+			"Чтобы задать новый пароль, перейдите по ссылке:\n%s\n\n"+
+			"Ссылка действует до %s и может быть использована один раз.",
+			user.Login, link, token.ExpiresAt.Format("02.01.2006 15:04")),
+	}
+	return msg, token.ExpiresAt, nil
+}
+
+// ConsumePasswordResetToken помечает токен использованным и возвращает пользователя.
+func ConsumePasswordResetToken(tx *gorm.DB, raw string) (models.User, error) {
+	var user models.User
+
+	var token models.PasswordResetToken
+	if err := tx.Where("token_hash = ?", HashToken(raw)).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, ErrResetTokenInvalid
+		}
+		return user, err
+	}
+
+	res := tx.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, time.Now()).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return user, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return user, ErrResetTokenInvalid
+	}
+
+	if err := tx.Where("deleted_at IS NULL").First(&user, token.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, ErrResetTokenInvalid
+		}
+		return user, err
+	}
+	return user, nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	PermEmployeesReadAll      = "employees.read_all"
+	PermEmployeesWrite        = "employees.write"
+	PermEmployeesSalaryRead   = "employees.salary.read"
+	PermWorkReadAll           = "work.read_all"
+	PermWorkDelete            = "work.delete"
+	PermUploadPreview         = "upload.preview"
+	PermUploadConfirm         = "upload.confirm"
+	PermDictWrite             = "dict.write"
+	PermDictAccessGroupsWrite = "dict.access_groups.write"
+	PermUsersManage           = "users.manage"
+	PermDashboardReadAll      = "dashboard.read_all"
+	PermScopeAllDepartments   = "scope.all_departments"
+	PermAuditRead             = "audit.read"
+	PermUsersImpersonate      = "users.impersonate"
+	PermTrashManage           = "trash.manage"
+)
+
+type PermissionDefinition struct {
+	Code string
+	Name string
+	// Groups — группы, которым право выдаётся при его первом появлении в БД.
+	Groups []string
+}
+
+// PermissionCatalog — все права, которые проверяет код приложения.
+var PermissionCatalog = []PermissionDefinition{
+	{PermEmployeesReadAll, "Просмотр всех сотрудников", []string{"admin", "manager"}},
+	{PermEmployeesWrite, "Создание, изменение и удаление сотрудников", []string{"admin", "manager"}},
+	{PermEmployeesSalaryRead, "Просмотр зарплат и дат рождения", []string{"admin", "manager"}},
+	{PermWorkReadAll, "Просмотр рабочих данных всех сотрудников", []string{"admin", "manager"}},
+	{PermWorkDelete, "Удаление рабочих данных", []string{"admin", "manager"}},
+	{PermUploadPreview, "Предпросмотр загрузки данных", []string{"admin", "manager"}},
+	{PermUploadConfirm, "Подтверждение загрузки данных", []string{"admin", "manager"}},
+	{PermDictWrite, "Изменение справочников отделов и должностей", []string{"admin", "manager"}},
+	{PermDictAccessGroupsWrite, "Изменение групп доступа и их прав", []string{"admin"}},
+	{PermUsersManage, "Управление учётными записями", []string{"admin"}},
+	{PermDashboardReadAll, "Просмотр сводных показателей по сотрудникам", []string{"admin", "manager"}},
+	{PermScopeAllDepartments, "Доступ к данным всех отделов", []string{"admin"}},
+	{PermAuditRead, "Просмотр журнала аудита", []string{"admin"}},
+	{PermUsersImpersonate, "Просмотр системы от имени пользователя", []string{"admin"}},
+	{PermTrashManage, "Восстановление и окончательное удаление удалённых записей", []string{"admin"}},
+}
+
+// SeedPermissions добавляет в БД права из каталога. Новое право сразу
+// выдаётся группам по умолчанию; уже существующие связи не трогаются,
+// чтобы не перезаписывать настройки администратора.
+func SeedPermissions(conn *gorm.DB) error {
+	return conn.Transaction(func(tx *gorm.DB) error {
+		for _, def := range PermissionCatalog {
+			perm := models.Permission{Code: def.Code, Name: def.Name}
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&perm)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+
+			var groups []models.AccessGroup
+			if err := tx.Where("code IN ?", def.Groups).Find(&groups).Error; err != nil {
+				return err
+			}
+			for _, g := range groups {
+				if err := tx.Create(&models.AccessGroupPermission{
+					AccessGroupID: g.ID,
+					PermissionID:  perm.ID,
+				}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// PermissionCacheTTL — время жизни кеша прав групп.
+const PermissionCacheTTL = time.Minute
+
+type groupPermissionCache struct {
+	mu       sync.RWMutex
+	byGroup  map[string]map[string]bool
+	loadedAt time.Time
+	conn     *gorm.DB
+}
+
+var groupPermissions = &groupPermissionCache{}
+
+// InitPermissions задаёт подключение, из которого загружаются права групп.
+func InitPermissions(conn *gorm.DB) {
+	groupPermissions.mu.Lock()
+	groupPermissions.conn = conn
+	groupPermissions.byGroup = nil
+	groupPermissions.mu.Unlock()
+}
+
+// InvalidatePermissions сбрасывает кеш после изменения прав или групп доступа.
+func InvalidatePermissions() {
+	groupPermissions.mu.Lock()
+	groupPermissions.byGroup = nil
+	groupPermissions.mu.Unlock()
+}
+
+func (g *groupPermissionCache) load() (map[string]map[string]bool, error) {
+	g.mu.RLock()
+	byGroup, loadedAt := g.byGroup, g.loadedAt
+	g.mu.RUnlock()
+	if byGroup != nil && time.Since(loadedAt) < PermissionCacheTTL {
+		return byGroup, nil
+	}
+
+	var rows []struct {
+		GroupCode      string
+		PermissionCode string
+	}
+	if err := g.conn.Table("access_group_permissions agp").
+		Select("ag.code AS group_code, p.code AS permission_code").
+		Joins("JOIN access_groups ag ON ag.id = agp.access_group_id AND ag.deleted_at IS NULL").
+		Joins("JOIN permissions p ON p.id = agp.permission_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	byGroup = make(map[string]map[string]bool)
+	for _, r := range rows {
+		if byGroup[r.GroupCode] == nil {
+			byGroup[r.GroupCode] = make(map[string]bool)
+		}
+		byGroup[r.GroupCode][r.PermissionCode] = true
+	}
+
+	g.mu.Lock()
+	g.byGroup = byGroup
+	g.loadedAt = time.Now()
+	g.mu.Unlock()
+
+	return byGroup, nil
+}
+
+// GroupsHavePermission проверяет, даёт ли хотя бы одна из групп указанное право.
+func GroupsHavePermission(groups []string, permission string) (bool, error) {
+	byGroup, err := groupPermissions.load()
+	if err != nil {
+		return false, err
+	}
+	for _, g := range groups {
+		if byGroup[g][permission] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasPermission проверяет право текущего пользователя по его группам из токена.
+// При входе по API-токену право должно также входить в scopes токена.
+func HasPermission(c *gin.Context, permission string) bool {
+	if scopes, ok := c.Get("token_scopes"); ok && !containsString(scopes.([]string), permission) {
+		return false
+	}
+	ok, err := GroupsHavePermission(c.GetStringSlice("groups"), permission)
+	return err == nil && ok
+}
+
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("user_id"); !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id_not_found"})
+			c.Abort()
+			return
+		}
+
+		if HasPermission(c, permission) {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "forbidden",
+			"message": "Доступ запрещён",
+		})
+		c.Abort()
+	}
+}
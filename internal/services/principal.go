@@ -0,0 +1,79 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+// PrincipalCacheTTL ограничивает время, в течение которого изменения,
+// сделанные другими экземплярами приложения, могут быть не видны.
+const PrincipalCacheTTL = 30 * time.Second
+
+// Principal — закешированное состояние пользователя, необходимое для
+// проверки токенов без обращения к БД на каждый запрос.
+type Principal struct {
+	UserID        uint
+	EmployeeID    uint
+	Groups        []string
+	DepartmentIDs []uint
+	TokenVersion  int
+
+	loadedAt time.Time
+}
+
+type principalCache struct {
+	mu    sync.RWMutex
+	items map[uint]*Principal
+}
+
+var principals = &principalCache{items: make(map[uint]*Principal)}
+
+// LoadPrincipal возвращает состояние активного пользователя из кеша,
+// при отсутствии или устаревании записи — из БД.
+func LoadPrincipal(conn *gorm.DB, userID uint) (*Principal, error) {
+	principals.mu.RLock()
+	p, ok := principals.items[userID]
+	principals.mu.RUnlock()
+	if ok && time.Since(p.loadedAt) < PrincipalCacheTTL {
+		return p, nil
+	}
+
+	var user models.User
+	if err := conn.Preload("AccessGroups.AccessGroup").
+		Where("deleted_at IS NULL").
+		First(&user, userID).Error; err != nil {
+		InvalidatePrincipal(userID)
+		return nil, err
+	}
+
+	var departmentIDs []uint
+	if err := conn.Model(&models.UserDepartment{}).
+		Where("user_id = ?", userID).
+		Pluck("department_id", &departmentIDs).Error; err != nil {
+		return nil, err
+	}
+
+	p = &Principal{
+		UserID:        user.ID,
+		EmployeeID:    user.EmployeeID,
+		Groups:        GroupClaims(user),
+		DepartmentIDs: departmentIDs,
+		TokenVersion:  user.TokenVersion,
+		loadedAt:      time.Now(),
+	}
+
+	principals.mu.Lock()
+	principals.items[userID] = p
+	principals.mu.Unlock()
+
+	return p, nil
+}
+
+func InvalidatePrincipal(userID uint) {
+	principals.mu.Lock()
+	delete(principals.items, userID)
+	principals.mu.Unlock()
+}
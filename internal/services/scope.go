@@ -0,0 +1,107 @@
+package services
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// EmployeeScope описывает, данные каких сотрудников доступны текущему пользователю.
+type EmployeeScope struct {
+	// All — доступ без ограничений по отделам.
+	All bool
+	// Self — сотрудник, связанный с пользователем; свои данные доступны на чтение
+	// всегда, как и данные его подчинённых по линии подчинения (прямых и косвенных).
+	Self uint
+	// DepartmentIDs — отделы, закреплённые за менеджером; доступны сотрудники,
+	// которые работают в них сейчас.
+	DepartmentIDs []uint
+}
+
+// ScopeFor строит область видимости для права readAll: без этого права
+// доступны только собственные данные и данные подчинённых, с ним — ещё и данные
+// закреплённых отделов, а с правом scope.all_departments — данные всех сотрудников.
+func ScopeFor(c *gin.Context, readAll string) EmployeeScope {
+	scope := EmployeeScope{Self: c.GetUint("employee_id")}
+	if !HasPermission(c, readAll) {
+		return scope
+	}
+	if HasPermission(c, PermScopeAllDepartments) {
+		scope.All = true
+		return scope
+	}
+	if ids, ok := c.Get("department_ids"); ok {
+		scope.DepartmentIDs, _ = ids.([]uint)
+	}
+	return scope
+}
+
+// Apply ограничивает запрос по колонке с ID сотрудника.
+func (s EmployeeScope) Apply(query *gorm.DB, employeeColumn string) *gorm.DB {
+	if s.All {
+		return query
+	}
+
+	cond := "(" + employeeColumn + " = ? OR " + employeeColumn + " IN (SELECT employee_id FROM (" + subtreeSQL + ") reports)"
+	args := []interface{}{s.Self, s.Self}
+	if len(s.DepartmentIDs) > 0 {
+		cond += " OR " + employeeColumn + " IN (?)"
+		args = append(args, query.Session(&gorm.Session{NewDB: true}).
+			Table("employee_hrs").
+			Select("employee_id").
+			Where("department_id IN ? AND valid_to IS NULL AND deleted_at IS NULL", s.DepartmentIDs))
+	}
+	return query.Where(cond+")", args...)
+}
+
+// Allows проверяет доступ к данным конкретного сотрудника на чтение.
+func (s EmployeeScope) Allows(conn *gorm.DB, employeeID uint) (bool, error) {
+	if s.All || employeeID == s.Self {
+		return true, nil
+	}
+	if s.Self != 0 {
+		subordinate, err := InSubtree(conn, s.Self, employeeID)
+		if err != nil || subordinate {
+			return subordinate, err
+		}
+	}
+	return s.inDepartments(conn, employeeID)
+}
+
+// AllowsWrite проверяет право изменять данные сотрудника. Свои данные и данные
+// подчинённых доступны только на чтение: изменять можно сотрудников закреплённых
+// отделов, а с правом scope.all_departments — всех, кроме себя.
+func (s EmployeeScope) AllowsWrite(conn *gorm.DB, employeeID uint) (bool, error) {
+	if s.Self != 0 && employeeID == s.Self {
+		return false, nil
+	}
+	if s.All {
+		return true, nil
+	}
+	return s.inDepartments(conn, employeeID)
+}
+
+// inDepartments проверяет, работает ли сотрудник сейчас в закреплённом отделе.
+func (s EmployeeScope) inDepartments(conn *gorm.DB, employeeID uint) (bool, error) {
+	if len(s.DepartmentIDs) == 0 {
+		return false, nil
+	}
+
+	var count int64
+	err := conn.Table("employee_hrs").
+		Where("employee_id = ? AND department_id IN ? AND valid_to IS NULL AND deleted_at IS NULL", employeeID, s.DepartmentIDs).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// AllowsDepartment проверяет, может ли пользователь назначать сотрудников в отдел.
+func (s EmployeeScope) AllowsDepartment(departmentID uint) bool {
+	if s.All {
+		return true
+	}
+	for _, id := range s.DepartmentIDs {
+		if id == departmentID {
+			return true
+		}
+	}
+	return false
+}
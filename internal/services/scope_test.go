@@ -0,0 +1,64 @@
+package services
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+// departmentStaff отвечает на запросы EmployeeScope: сотрудник 2 подчинён
+// сотруднику 1, сотрудник 3 работает в отделе 10.
+func departmentStaff(t *testing.T) fakeHandler {
+	return func(query string, args []driver.NamedValue) (fakeResult, error) {
+		count := func(ok bool) (fakeResult, error) {
+			n := int64(0)
+			if ok {
+				n = 1
+			}
+			return fakeResult{columns: []string{"count"}, rows: [][]driver.Value{{n}}}, nil
+		}
+		switch {
+		case strings.Contains(query, `FROM "employee_hrs"`) && strings.Contains(query, "department_id IN"):
+			return count(args[0].Value == int64(3) && args[1].Value == int64(10))
+		case strings.Contains(query, "WITH RECURSIVE"):
+			var found bool
+			for _, a := range args {
+				found = found || a.Value == int64(2)
+			}
+			return count(found)
+		}
+		t.Fatalf("unexpected query: %s", query)
+		return fakeResult{}, nil
+	}
+}
+
+func TestEmployeeScopeAllowsWrite(t *testing.T) {
+	conn := openFakeDB(t, departmentStaff(t))
+
+	cases := []struct {
+		name        string
+		scope       EmployeeScope
+		employee    uint
+		read, write bool
+	}{
+		{"self", EmployeeScope{Self: 1}, 1, true, false},
+		{"self with all departments", EmployeeScope{Self: 1, All: true}, 1, true, false},
+		{"subordinate", EmployeeScope{Self: 1}, 2, true, false},
+		{"department", EmployeeScope{Self: 1, DepartmentIDs: []uint{10}}, 3, true, true},
+		{"other department", EmployeeScope{Self: 1, DepartmentIDs: []uint{11}}, 3, false, false},
+		{"all departments", EmployeeScope{Self: 1, All: true}, 3, true, true},
+		{"no linked employee", EmployeeScope{All: true}, 1, true, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			read, err := tc.scope.Allows(conn, tc.employee)
+			if err != nil || read != tc.read {
+				t.Errorf("Allows = %v, %v, want %v", read, err, tc.read)
+			}
+			write, err := tc.scope.AllowsWrite(conn, tc.employee)
+			if err != nil || write != tc.write {
+				t.Errorf("AllowsWrite = %v, %v, want %v", write, err, tc.write)
+			}
+		})
+	}
+}
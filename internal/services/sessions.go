@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrSessionRevoked = errors.New("session revoked or expired")
+
+// sessionTouchInterval ограничивает частоту записи last_seen_at.
+const sessionTouchInterval = time.Minute
+
+// StartSession создаёт сессию входа и выдаёт для неё пару токенов.
+func StartSession(tx *gorm.DB, user models.User, userAgent, ip string) (*models.TokenResponse, error) {
+	session, err := createSession(tx, user.ID, userAgent, ip)
+	if err != nil {
+		return nil, err
+	}
+	tokens, _, err := IssueTokens(tx, user, GroupClaims(user), session.ID)
+	return tokens, err
+}
+
+func createSession(tx *gorm.DB, userID uint, userAgent, ip string) (*models.UserSession, error) {
+	now := time.Now()
+	session := models.UserSession{
+		UserID:     userID,
+		UserAgent:  truncate(userAgent, 512),
+		IP:         ip,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(RefreshTokenTTL),
+	}
+	if err := tx.Create(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ActiveSessions возвращает неотозванные и неистёкшие сессии пользователя.
+func ActiveSessions(conn *gorm.DB, userID uint) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := conn.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// RevokeSession отзывает сессию пользователя и её refresh-токены.
+// Возвращает gorm.ErrRecordNotFound, если активной сессии нет.
+func RevokeSession(tx *gorm.DB, userID, sessionID uint) error {
+	n, err := revokeSessions(tx, "user_id = ? AND id = ?", userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeOtherSessions отзывает все сессии пользователя, кроме keepID.
+func RevokeOtherSessions(tx *gorm.DB, userID, keepID uint) (int64, error) {
+	return revokeSessions(tx, "user_id = ? AND id <> ?", userID, keepID)
+}
+
+func revokeSessions(tx *gorm.DB, query string, args ...interface{}) (int64, error) {
+	var ids []uint
+	if err := tx.Model(&models.UserSession{}).
+		Where(query, args...).
+		Where("revoked_at IS NULL").
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	defer AfterCommit(tx, func() { invalidateSessions(ids...) })
+
+	now := time.Now()
+	if err := tx.Model(&models.UserSession{}).
+		Where("id IN ?", ids).
+		Update("revoked_at", now).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&models.RefreshToken{}).
+		Where("session_id IN ? AND revoked_at IS NULL", ids).
+		Update("revoked_at", now).Error; err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+type sessionState struct {
+	userID    uint
+	active    bool
+	loadedAt  time.Time
+	expiresAt time.Time
+}
+
+type sessionCache struct {
+	mu    sync.RWMutex
+	items map[uint]*sessionState
+	// prunedAt — время последней очистки устаревших записей.
+	prunedAt time.Time
+}
+
+// put сохраняет состояние сессии и не чаще раза в PrincipalCacheTTL удаляет
+// записи истёкших сессий и записи, которые всё равно будут перечитаны из базы.
+func (c *sessionCache) put(id uint, st *sessionState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now := st.loadedAt; now.Sub(c.prunedAt) >= PrincipalCacheTTL {
+		for cached, item := range c.items {
+			if !now.Before(item.expiresAt) || now.Sub(item.loadedAt) >= PrincipalCacheTTL {
+				delete(c.items, cached)
+			}
+		}
+		c.prunedAt = now
+	}
+	c.items[id] = st
+}
+
+var sessions = &sessionCache{items: make(map[uint]*sessionState)}
+
+// CheckSession проверяет, что сессия access-токена не отозвана, и отмечает
+// время и адрес последнего обращения. Состояние кешируется на PrincipalCacheTTL.
+func CheckSession(conn *gorm.DB, userID, sessionID uint, ip string) error {
+	sessions.mu.RLock()
+	st, ok := sessions.items[sessionID]
+	sessions.mu.RUnlock()
+
+	if !ok || time.Since(st.loadedAt) >= PrincipalCacheTTL {
+		var session models.UserSession
+		if err := conn.First(&session, sessionID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		now := time.Now()
+		st = &sessionState{
+			userID:    session.UserID,
+			active:    session.ID != 0 && session.RevokedAt == nil && now.Before(session.ExpiresAt),
+			loadedAt:  now,
+			expiresAt: session.ExpiresAt,
+		}
+		if st.active && (now.Sub(session.LastSeenAt) > sessionTouchInterval || session.IP != ip) {
+			if err := conn.Model(&session).Updates(map[string]interface{}{
+				"last_seen_at": now,
+				"ip":           ip,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		sessions.put(sessionID, st)
+	}
+
+	if !st.active || st.userID != userID {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+func invalidateSessions(ids ...uint) {
+	sessions.mu.Lock()
+	for _, id := range ids {
+		delete(sessions.items, id)
+	}
+	sessions.mu.Unlock()
+}
+
+func invalidateUserSessions(userID uint) {
+	sessions.mu.Lock()
+	for id, st := range sessions.items {
+		if st.userID == userID {
+			delete(sessions.items, id)
+		}
+	}
+	sessions.mu.Unlock()
+}
+
+// DescribeUserAgent кратко описывает браузер и ОС по заголовку User-Agent,
+// например «Chrome, Windows». Для неизвестных клиентов возвращает пустую строку.
+func DescribeUserAgent(ua string) string {
+	var browser, os string
+	switch {
+	case strings.Contains(ua, "YaBrowser/"):
+		browser = "Яндекс Браузер"
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(ua, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	}
+	switch {
+	case strings.Contains(ua, "Android"):
+		os = "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		os = "iOS"
+	case strings.Contains(ua, "Windows"):
+		os = "Windows"
+	case strings.Contains(ua, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(ua, "Linux"):
+		os = "Linux"
+	}
+
+	switch {
+	case browser != "" && os != "":
+		return browser + ", " + os
+	case browser != "":
+		return browser
+	}
+	return os
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestSessionCachePrunesExpired(t *testing.T) {
+	now := time.Now()
+	c := &sessionCache{items: map[uint]*sessionState{
+		1: {userID: 1, active: true, loadedAt: now.Add(-time.Second), expiresAt: now.Add(time.Hour)},
+		2: {userID: 1, active: true, loadedAt: now.Add(-time.Second), expiresAt: now.Add(-time.Second)},
+		3: {userID: 2, active: true, loadedAt: now.Add(-PrincipalCacheTTL), expiresAt: now.Add(time.Hour)},
+	}}
+
+	c.put(4, &sessionState{userID: 3, loadedAt: now, expiresAt: now.Add(-time.Minute)})
+
+	for id, want := range map[uint]bool{1: true, 2: false, 3: false, 4: true} {
+		if _, ok := c.items[id]; ok != want {
+			t.Errorf("session %d cached = %v, want %v", id, ok, want)
+		}
+	}
+
+	// Следующая очистка — не раньше чем через PrincipalCacheTTL.
+	c.items[5] = &sessionState{userID: 4, loadedAt: now, expiresAt: now}
+	c.put(6, &sessionState{userID: 5, loadedAt: now.Add(time.Second), expiresAt: now.Add(time.Hour)})
+	if _, ok := c.items[5]; !ok {
+		t.Error("pruned again before PrincipalCacheTTL passed")
+	}
+	c.put(7, &sessionState{userID: 6, loadedAt: now.Add(PrincipalCacheTTL), expiresAt: now.Add(time.Hour)})
+	if _, ok := c.items[5]; ok {
+		t.Error("expired session 5 not pruned")
+	}
+}
+
+func TestRevokeSessionInvalidatesAfterCommit(t *testing.T) {
+	conn := openFakeDB(t, func(query string, args []driver.NamedValue) (fakeResult, error) {
+		if strings.HasPrefix(query, `SELECT "id" FROM "user_sessions"`) {
+			return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(9)}}}, nil
+		}
+		return fakeResult{rowsAffected: 1}, nil
+	})
+	cached := func() bool {
+		sessions.mu.RLock()
+		defer sessions.mu.RUnlock()
+		_, ok := sessions.items[9]
+		return ok
+	}
+	sessions.mu.Lock()
+	sessions.items[9] = &sessionState{userID: 1, active: true, loadedAt: time.Now(), expiresAt: time.Now().Add(time.Hour)}
+	sessions.mu.Unlock()
+
+	err := Transaction(conn, func(tx *gorm.DB) error {
+		if err := RevokeSession(tx, 1, 9); err != nil {
+			return err
+		}
+		if !cached() {
+			t.Error("session invalidated before commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached() {
+		t.Error("session not invalidated after commit")
+	}
+}
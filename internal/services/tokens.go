@@ -0,0 +1,220 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+)
+
+// IssueTokens выдаёт пару access/refresh токенов сессии sessionID.
+// Refresh-токен хранится в БД только в виде SHA-256 хеша.
+func IssueTokens(tx *gorm.DB, user models.User, groups []string, sessionID uint) (*models.TokenResponse, *models.RefreshToken, error) {
+	access, err := GenerateJWT(user.ID, groups, user.TokenVersion, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw, err := RandomToken(32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refresh := models.RefreshToken{
+		UserID:    user.ID,
+		SessionID: &sessionID,
+		TokenHash: HashToken(raw),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}
+	if err := tx.Create(&refresh).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return &models.TokenResponse{
+		Token:        access,
+		RefreshToken: raw,
+		ExpiresIn:    int(AccessTokenTTL.Seconds()),
+	}, &refresh, nil
+}
+
+// RotateRefreshToken отзывает предъявленный refresh-токен и выдаёт новую пару
+// в той же сессии, продлевая её. Повторное предъявление токена, уже заменённого
+// при ротации, считается утечкой: все сессии пользователя отзываются. Токен,
+// отозванный выходом, завершением сессии или сменой пароля, просто недействителен.
+func RotateRefreshToken(conn *gorm.DB, raw, userAgent, ip string) (*models.TokenResponse, error) {
+	var stored models.RefreshToken
+	if err := conn.Where("token_hash = ?", HashToken(raw)).First(&stored).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		if stored.ReplacedBy == nil {
+			return nil, ErrRefreshTokenInvalid
+		}
+		if err := Transaction(conn, func(tx *gorm.DB) error {
+			return RevokeUserTokens(tx, stored.UserID)
+		}); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	var pair *models.TokenResponse
+	err := Transaction(conn, func(tx *gorm.DB) error {
+		// Условное обновление защищает от одновременной ротации одного токена.
+		res := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", stored.ID).
+			Update("revoked_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRefreshTokenInvalid
+		}
+
+		var user models.User
+		if err := tx.Preload("AccessGroups.AccessGroup").
+			Where("deleted_at IS NULL").
+			First(&user, stored.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRefreshTokenInvalid
+			}
+			return err
+		}
+
+		// Сессии, начатые до включения обязательной 2FA, продлевать нельзя:
+		// пользователь должен войти заново и подключить второй фактор.
+		if !user.TOTPEnabled && TwoFactorRequired(GroupClaims(user)) {
+			return ErrRefreshTokenInvalid
+		}
+
+		sessionID, err := continueSession(tx, stored, userAgent, ip)
+		if err != nil {
+			return err
+		}
+
+		var next *models.RefreshToken
+		pair, next, err = IssueTokens(tx, user, GroupClaims(user), sessionID)
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.RefreshToken{}).
+			Where("id = ?", stored.ID).
+			Update("replaced_by", next.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// continueSession продлевает сессию refresh-токена. Для токенов, выданных
+// до появления сессий, сессия создаётся.
+func continueSession(tx *gorm.DB, stored models.RefreshToken, userAgent, ip string) (uint, error) {
+	if stored.SessionID == nil {
+		session, err := createSession(tx, stored.UserID, userAgent, ip)
+		if err != nil {
+			return 0, err
+		}
+		return session.ID, nil
+	}
+
+	now := time.Now()
+	res := tx.Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", *stored.SessionID).
+		Updates(map[string]interface{}{
+			"last_seen_at": now,
+			"expires_at":   now.Add(RefreshTokenTTL),
+			"ip":           ip,
+			"user_agent":   truncate(userAgent, 512),
+		})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return 0, ErrRefreshTokenInvalid
+	}
+	return *stored.SessionID, nil
+}
+
+// RevokeRefreshToken завершает сессию, которой принадлежит refresh-токен.
+func RevokeRefreshToken(tx *gorm.DB, raw string) error {
+	var stored models.RefreshToken
+	err := tx.Where("token_hash = ?", HashToken(raw)).First(&stored).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if stored.SessionID != nil {
+		_, err := revokeSessions(tx, "id = ?", *stored.SessionID)
+		return err
+	}
+	return tx.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", stored.ID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserTokens делает недействительными все токены пользователя:
+// увеличивает token_version, завершает сессии и отзывает refresh-токены.
+// Кеши пользователя сбрасываются после фиксации транзакции (см. AfterCommit).
+func RevokeUserTokens(tx *gorm.DB, userID uint) error {
+	defer AfterCommit(tx, func() {
+		InvalidatePrincipal(userID)
+		invalidateUserSessions(userID)
+	})
+
+	if err := tx.Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func RandomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func HashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateRefreshTokenRevoked(t *testing.T) {
+	revokedAt := time.Now().Add(-time.Minute)
+	cases := []struct {
+		name       string
+		replacedBy driver.Value
+		want       error
+		revokeAll  bool
+	}{
+		{"revoked by logout", nil, ErrRefreshTokenInvalid, false},
+		{"replaced by rotation", int64(8), ErrRefreshTokenReused, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var updates []string
+			conn := openFakeDB(t, func(query string, args []driver.NamedValue) (fakeResult, error) {
+				if strings.HasPrefix(query, `SELECT * FROM "refresh_tokens"`) {
+					return fakeResult{
+						columns: []string{"id", "user_id", "revoked_at", "replaced_by", "expires_at"},
+						rows:    [][]driver.Value{{int64(7), int64(3), revokedAt, tc.replacedBy, time.Now().Add(time.Hour)}},
+					}, nil
+				}
+				updates = append(updates, query)
+				return fakeResult{rowsAffected: 1}, nil
+			})
+
+			_, err := RotateRefreshToken(conn, "raw", "ua", "127.0.0.1")
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got %v, want %v", err, tc.want)
+			}
+			if revoked := len(updates) > 0; revoked != tc.revokeAll {
+				t.Errorf("user tokens revoked = %v, want %v: %v", revoked, tc.revokeAll, updates)
+			}
+		})
+	}
+}
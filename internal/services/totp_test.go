@@ -0,0 +1,164 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+// Секрет из приложения B RFC 6238 для SHA1: ASCII "12345678901234567890".
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// В RFC коды восьмизначные; при TOTPDigits = 6 это их последние шесть цифр.
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, v := range vectors {
+		now := time.Unix(v.unix, 0).UTC()
+		want := v.code[len(v.code)-TOTPDigits:]
+
+		got, err := TOTPCode(rfcTOTPSecret, now)
+		if err != nil {
+			t.Fatalf("TOTPCode(%d): %v", v.unix, err)
+		}
+		if got != want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", v.unix, got, want)
+		}
+		if step, ok := ValidateTOTP(rfcTOTPSecret, want, now); !ok || step != TOTPStep(now) {
+			t.Errorf("ValidateTOTP(%d) = %d, %v, want %d, true", v.unix, step, ok, TOTPStep(now))
+		}
+	}
+}
+
+func TestValidateTOTPWindow(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := TOTPStep(now)
+
+	for _, shift := range []int64{-1, 0, 1} {
+		code, err := totpCodeAt(rfcTOTPSecret, step+shift)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := ValidateTOTP(rfcTOTPSecret, code, now)
+		if !ok || got != step+shift {
+			t.Errorf("code of step %+d: got %d, %v, want %d, true", shift, got, ok, step+shift)
+		}
+	}
+
+	for _, shift := range []int64{-2, 2} {
+		code, err := totpCodeAt(rfcTOTPSecret, step+shift)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := ValidateTOTP(rfcTOTPSecret, code, now); ok {
+			t.Errorf("code of step %+d accepted outside the window", shift)
+		}
+	}
+
+	for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := ValidateTOTP(rfcTOTPSecret, code, now); ok {
+			t.Errorf("malformed code %q accepted", code)
+		}
+	}
+}
+
+// twoFactorStore имитирует таблицы users и recovery_codes для условных UPDATE
+// из VerifySecondFactor.
+type twoFactorStore struct {
+	lastStep int64
+	// recovery — хеш кода восстановления → использован ли он.
+	recovery map[string]bool
+}
+
+func (s *twoFactorStore) open(t *testing.T) *gorm.DB {
+	return openFakeDB(t, func(query string, args []driver.NamedValue) (fakeResult, error) {
+		switch {
+		case strings.HasPrefix(query, `UPDATE "users" SET "totp_last_step"`):
+			step := args[0].Value.(int64)
+			if s.lastStep >= step {
+				return fakeResult{}, nil
+			}
+			s.lastStep = step
+			return fakeResult{rowsAffected: 1}, nil
+		case strings.HasPrefix(query, `UPDATE "recovery_codes" SET "used_at"`):
+			hash := args[2].Value.(string)
+			if used, ok := s.recovery[hash]; !ok || used {
+				return fakeResult{}, nil
+			}
+			s.recovery[hash] = true
+			return fakeResult{rowsAffected: 1}, nil
+		}
+		t.Fatalf("unexpected query: %s", query)
+		return fakeResult{}, nil
+	})
+}
+
+func TestVerifySecondFactorRejectsReplay(t *testing.T) {
+	store := &twoFactorStore{}
+	conn := store.open(t)
+	user := models.User{ID: 1, TOTPEnabled: true, TOTPSecret: rfcTOTPSecret}
+	now := time.Unix(1234567890, 0)
+
+	code, _ := TOTPCode(rfcTOTPSecret, now)
+	if err := VerifySecondFactor(conn, user, code, now); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if store.lastStep != TOTPStep(now) {
+		t.Fatalf("TOTPLastStep = %d, want %d", store.lastStep, TOTPStep(now))
+	}
+	if err := VerifySecondFactor(conn, user, code, now); !errors.Is(err, ErrTwoFactorCodeInvalid) {
+		t.Fatalf("replay: got %v, want ErrTwoFactorCodeInvalid", err)
+	}
+
+	// Код предыдущего интервала входит в окно, но уже не новее принятого.
+	prev, _ := totpCodeAt(rfcTOTPSecret, TOTPStep(now)-1)
+	if err := VerifySecondFactor(conn, user, prev, now); !errors.Is(err, ErrTwoFactorCodeInvalid) {
+		t.Fatalf("older step: got %v, want ErrTwoFactorCodeInvalid", err)
+	}
+
+	later := now.Add(TOTPPeriod)
+	next, _ := TOTPCode(rfcTOTPSecret, later)
+	if err := VerifySecondFactor(conn, user, next, later); err != nil {
+		t.Fatalf("next step: %v", err)
+	}
+}
+
+func TestVerifySecondFactorRecoveryCodeOnce(t *testing.T) {
+	store := &twoFactorStore{recovery: map[string]bool{HashToken("a1b2c3d4e5"): false}}
+	conn := store.open(t)
+	user := models.User{ID: 1, TOTPEnabled: true, TOTPSecret: rfcTOTPSecret}
+	now := time.Now()
+
+	// Код принимается в любом регистре, с дефисом и пробелами.
+	if err := VerifySecondFactor(conn, user, " A1B2C-3D4E5 ", now); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := VerifySecondFactor(conn, user, "a1b2c-3d4e5", now); !errors.Is(err, ErrTwoFactorCodeInvalid) {
+		t.Fatalf("second use: got %v, want ErrTwoFactorCodeInvalid", err)
+	}
+	if err := VerifySecondFactor(conn, user, "00000-00000", now); !errors.Is(err, ErrTwoFactorCodeInvalid) {
+		t.Fatalf("unknown code: got %v, want ErrTwoFactorCodeInvalid", err)
+	}
+}
+
+func TestVerifySecondFactorNotSetUp(t *testing.T) {
+	conn := (&twoFactorStore{}).open(t)
+	user := models.User{ID: 1, TOTPSecret: rfcTOTPSecret}
+	if err := VerifySecondFactor(conn, user, "123456", time.Now()); !errors.Is(err, ErrTwoFactorNotSetUp) {
+		t.Fatalf("got %v, want ErrTwoFactorNotSetUp", err)
+	}
+}
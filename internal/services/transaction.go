@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type afterCommitKey struct{}
+
+type afterCommitHooks struct {
+	fns []func()
+}
+
+// Transaction выполняет fn в транзакции и после её фиксации вызывает действия,
+// отложенные через AfterCommit. Вложенный вызов присоединяется к внешней
+// транзакции: отложенные действия выполняются после фиксации внешней.
+func Transaction(conn *gorm.DB, fn func(tx *gorm.DB) error) error {
+	ctx := conn.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Value(afterCommitKey{}).(*afterCommitHooks); ok {
+		return conn.Transaction(fn)
+	}
+
+	hooks := &afterCommitHooks{}
+	if err := conn.WithContext(context.WithValue(ctx, afterCommitKey{}, hooks)).Transaction(fn); err != nil {
+		return err
+	}
+	for _, f := range hooks.fns {
+		f()
+	}
+	return nil
+}
+
+// AfterCommit откладывает fn до фиксации транзакции, начатой через Transaction;
+// при откате fn не вызывается. Вне такой транзакции fn вызывается сразу.
+//
+// Так сбрасываются кеши: если сбросить кеш до фиксации, параллельный запрос
+// успеет загрузить в него ещё не изменённые данные.
+func AfterCommit(tx *gorm.DB, fn func()) {
+	if tx.Statement.Context != nil {
+		if hooks, ok := tx.Statement.Context.Value(afterCommitKey{}).(*afterCommitHooks); ok {
+			hooks.fns = append(hooks.fns, fn)
+			return
+		}
+	}
+	fn()
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func acceptAll(string, []driver.NamedValue) (fakeResult, error) {
+	return fakeResult{rowsAffected: 1}, nil
+}
+
+func TestAfterCommit(t *testing.T) {
+	conn := openFakeDB(t, acceptAll)
+
+	var calls []string
+	err := Transaction(conn, func(tx *gorm.DB) error {
+		AfterCommit(tx, func() { calls = append(calls, "outer") })
+		if err := Transaction(tx, func(tx *gorm.DB) error {
+			AfterCommit(tx, func() { calls = append(calls, "nested") })
+			return nil
+		}); err != nil {
+			return err
+		}
+		if len(calls) != 0 {
+			t.Errorf("hooks ran before commit: %v", calls)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0] != "outer" || calls[1] != "nested" {
+		t.Errorf("calls = %v", calls)
+	}
+
+	calls = nil
+	errRollback := errors.New("rollback")
+	if err := Transaction(conn, func(tx *gorm.DB) error {
+		AfterCommit(tx, func() { calls = append(calls, "rolled back") })
+		return errRollback
+	}); !errors.Is(err, errRollback) {
+		t.Fatalf("got %v, want rollback error", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("hooks ran after rollback: %v", calls)
+	}
+
+	AfterCommit(conn, func() { calls = append(calls, "no transaction") })
+	if len(calls) != 1 {
+		t.Errorf("hook outside transaction not run: %v", calls)
+	}
+}
+
+func TestRevokeUserTokensInvalidatesAfterCommit(t *testing.T) {
+	conn := openFakeDB(t, acceptAll)
+	const userID = 42
+
+	cache := func() (principal, session bool) {
+		principals.mu.RLock()
+		_, principal = principals.items[userID]
+		principals.mu.RUnlock()
+		sessions.mu.RLock()
+		_, session = sessions.items[userID]
+		sessions.mu.RUnlock()
+		return
+	}
+	principals.mu.Lock()
+	principals.items[userID] = &Principal{UserID: userID}
+	principals.mu.Unlock()
+	sessions.mu.Lock()
+	sessions.items[userID] = &sessionState{userID: userID, active: true}
+	sessions.mu.Unlock()
+
+	err := Transaction(conn, func(tx *gorm.DB) error {
+		if err := RevokeUserTokens(tx, userID); err != nil {
+			return err
+		}
+		if principal, session := cache(); !principal || !session {
+			t.Error("cache invalidated before commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal, session := cache(); principal || session {
+		t.Error("cache not invalidated after commit")
+	}
+}
@@ -0,0 +1,517 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Типы записей корзины.
+const (
+	TrashEmployees          = "employees"
+	TrashWorkDays           = "work_days"
+	TrashDepartments        = "departments"
+	TrashPositions          = "positions"
+	TrashAccessGroups       = "access_groups"
+	TrashTerminationReasons = "termination_reasons"
+	TrashCustomAttributes   = "custom_attributes"
+)
+
+var (
+	ErrTrashUnknownType = errors.New("unknown trash item type")
+	ErrTrashInUse       = errors.New("deleted record is still referenced")
+	ErrTrashCodeTaken   = errors.New("code of deleted record is taken by another record")
+)
+
+var trashRetention = 30 * 24 * time.Hour
+
+// InitTrash задаёт срок хранения удалённых записей; 0 отключает автоочистку.
+func InitTrash(retention time.Duration) {
+	trashRetention = retention
+}
+
+// TrashRetention возвращает срок хранения удалённых записей.
+func TrashRetention() time.Duration {
+	return trashRetention
+}
+
+// trashRef — колонка, ссылающаяся на запись справочника.
+type trashRef struct {
+	table, column string
+}
+
+// trashEntity описывает тип записей корзины. auditType — тип сущности в журнале
+// аудита, по событию удаления которой определяется, кто удалил запись.
+type trashEntity struct {
+	auditType string
+	list      func(conn *gorm.DB) ([]models.TrashItem, error)
+	restore   func(tx *gorm.DB, id uint) error
+	purge     func(tx *gorm.DB, id uint) error
+}
+
+var trashTypes = []string{
+	TrashEmployees, TrashWorkDays, TrashDepartments, TrashPositions, TrashAccessGroups, TrashTerminationReasons,
+	TrashCustomAttributes,
+}
+
+var trashEntities = map[string]trashEntity{
+	TrashEmployees: {
+		auditType: "employee",
+		list:      listDeletedEmployees,
+		restore:   restoreEmployee,
+		purge:     purgeEmployee,
+	},
+	TrashWorkDays: {
+		auditType: "work_days",
+		list:      listDeletedWorkData,
+		restore:   restoreDeletedWorkData,
+		purge:     purgeDeletedWorkData,
+	},
+	// Ссылки из кадровых записей мешают окончательному удалению справочника,
+	// а зависимые строки (закрепления отделов, состав групп) удаляются вместе с ним.
+	TrashDepartments: dictionaryTrash("departments", "department",
+		[]trashRef{{"employee_hrs", "department_id"}},
+		[]trashRef{{"user_departments", "department_id"}}),
+	TrashPositions: dictionaryTrash("positions", "position",
+		[]trashRef{{"employee_hrs", "position_id"}}, nil),
+	TrashAccessGroups: invalidatingPermissions(dictionaryTrash("access_groups", "access_group", nil,
+		[]trashRef{{"access_group_permissions", "access_group_id"}, {"user_access_groups", "access_group_id"}})),
+	TrashTerminationReasons: dictionaryTrash("termination_reasons", "termination_reason",
+		[]trashRef{{"employee_hrs", "termination_reason_id"}}, nil),
+	// Значения сотрудников и справочник поля удаляются вместе с ним окончательно.
+	TrashCustomAttributes: customAttributeTrash(),
+}
+
+// ListTrash возвращает удалённые записи типа itemType (пустая строка — всех типов), новые сверху.
+func ListTrash(conn *gorm.DB, itemType string) ([]models.TrashItem, error) {
+	types := trashTypes
+	if itemType != "" {
+		if _, ok := trashEntities[itemType]; !ok {
+			return nil, ErrTrashUnknownType
+		}
+		types = []string{itemType}
+	}
+
+	items := []models.TrashItem{}
+	for _, t := range types {
+		entity := trashEntities[t]
+		list, err := entity.list(conn)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list {
+			list[i].Type = t
+			if trashRetention > 0 {
+				purgeAt := list[i].DeletedAt.Add(trashRetention)
+				list[i].PurgeAt = &purgeAt
+			}
+		}
+		if err := fillDeletedBy(conn, entity.auditType, list); err != nil {
+			return nil, err
+		}
+		items = append(items, list...)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+// RestoreTrashItem восстанавливает удалённую запись вместе с зависимыми.
+func RestoreTrashItem(tx *gorm.DB, c *gin.Context, itemType string, id uint) error {
+	entity, ok := trashEntities[itemType]
+	if !ok {
+		return ErrTrashUnknownType
+	}
+	if err := entity.restore(tx, id); err != nil {
+		return err
+	}
+	return Audit(tx, c, models.AuditRestore, entity.auditType, id, nil, nil)
+}
+
+// PurgeTrashItem окончательно удаляет запись из корзины вместе с зависимыми.
+// Справочник, на который ссылаются кадровые записи, не удаляется: ErrTrashInUse.
+func PurgeTrashItem(tx *gorm.DB, c *gin.Context, itemType string, id uint) error {
+	entity, ok := trashEntities[itemType]
+	if !ok {
+		return ErrTrashUnknownType
+	}
+	if err := entity.purge(tx, id); err != nil {
+		return err
+	}
+	return Audit(tx, c, models.AuditPurge, entity.auditType, id, nil, nil)
+}
+
+// PurgeExpiredTrash окончательно удаляет записи, пролежавшие в корзине дольше срока хранения.
+// Записи, на которые ещё ссылаются, пропускаются; ошибка удаления одной записи
+// записывается в лог и не мешает удалить остальные. Возвращает число удалённых записей.
+func PurgeExpiredTrash(conn *gorm.DB, now time.Time) (int, error) {
+	if trashRetention <= 0 {
+		return 0, nil
+	}
+	items, err := ListTrash(conn, "")
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, item := range items {
+		if item.PurgeAt == nil || item.PurgeAt.After(now) {
+			continue
+		}
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			return PurgeTrashItem(tx, nil, item.Type, item.ID)
+		})
+		switch {
+		case err == nil:
+			purged++
+		case errors.Is(err, ErrTrashInUse), errors.Is(err, gorm.ErrRecordNotFound):
+		default:
+			log.Printf("trash purge: %s %d: %v", item.Type, item.ID, err)
+		}
+	}
+	return purged, nil
+}
+
+// StartTrashPurger запускает фоновую очистку корзины с периодом interval.
+// Первая очистка выполняется сразу. При нулевом сроке хранения ничего не делает.
+func StartTrashPurger(conn *gorm.DB, interval time.Duration) {
+	if trashRetention <= 0 || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			n, err := PurgeExpiredTrash(conn, time.Now())
+			if err != nil {
+				log.Println("trash purge error:", err)
+			} else if n > 0 {
+				log.Printf("trash purge: removed %d records", n)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// DeleteEmployee помечает удалёнными сотрудника, его кадровые записи и рабочие данные.
+// Все строки получают одно время удаления, по нему восстанавливаются рабочие данные.
+func DeleteEmployee(tx *gorm.DB, employeeID uint) error {
+	now := time.Now()
+	tx = tx.Session(&gorm.Session{NowFunc: func() time.Time { return now }})
+
+	if err := tx.Delete(&models.EmployeeHR{}, "employee_id = ?", employeeID).Error; err != nil {
+		return err
+	}
+	if _, err := DeleteWorkData(tx, employeeID); err != nil {
+		return err
+	}
+	return tx.Delete(&models.Employee{}, employeeID).Error
+}
+
+// DeleteWorkData помечает удалёнными рабочие дни сотрудника с процессами и метриками.
+// Возвращает ID удалённых рабочих дней.
+func DeleteWorkData(tx *gorm.DB, employeeID uint) ([]uint, error) {
+	var ids []uint
+	if err := tx.Model(&models.WorkDay{}).Where("employee_id = ?", employeeID).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	if err := tx.Where("work_day_id IN ?", ids).Delete(&models.WorkProcess{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("work_day_id IN ?", ids).Delete(&models.SatisfactionMetric{}).Error; err != nil {
+		return nil, err
+	}
+	return ids, tx.Where("id IN ?", ids).Delete(&models.WorkDay{}).Error
+}
+
+const employeeNameSQL = `e.last_name || ' ' || e.first_name || COALESCE(' ' || NULLIF(e.middle_name, ''), '')`
+
+func listDeletedEmployees(conn *gorm.DB) ([]models.TrashItem, error) {
+	var items []models.TrashItem
+	err := conn.Table("employees e").
+		Select("e.id, " + employeeNameSQL + " AS name, e.deleted_at").
+		Where("e.deleted_at IS NOT NULL").
+		Scan(&items).Error
+	return items, err
+}
+
+func restoreEmployee(tx *gorm.DB, id uint) error {
+	var employee models.Employee
+	if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&employee).Error; err != nil {
+		return err
+	}
+
+	// Кадровые записи удаляются только вместе с сотрудником, рабочие данные —
+	// и отдельно: вместе с сотрудником восстанавливаются удалённые в тот же момент.
+	if err := tx.Unscoped().Model(&models.EmployeeHR{}).
+		Where("employee_id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	deletedAt := employee.DeletedAt.Time
+	if err := restoreWorkData(tx, id, &deletedAt); err != nil {
+		return err
+	}
+	return tx.Unscoped().Model(&employee).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"version":    gorm.Expr("version + 1"),
+	}).Error
+}
+
+func purgeEmployee(tx *gorm.DB, id uint) error {
+	var employee models.Employee
+	if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&employee).Error; err != nil {
+		return err
+	}
+
+	// Отключённые пользователи тоже ссылаются на сотрудника: их учётная запись
+	// сохраняется и может быть включена снова.
+	var users int64
+	if err := tx.Model(&models.User{}).Where("employee_id = ?", id).Count(&users).Error; err != nil {
+		return err
+	}
+	if users > 0 {
+		return ErrTrashInUse
+	}
+
+	if err := purgeWorkData(tx, id, false); err != nil {
+		return err
+	}
+	// Сотрудник перестаёт быть руководителем и в записях своих бывших подчинённых.
+	if err := tx.Unscoped().Model(&models.EmployeeHR{}).
+		Where("manager_id = ?", id).
+		Update("manager_id", nil).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("employee_id = ?", id).Delete(&models.EmployeeHR{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("employee_id = ?", id).Delete(&models.EmployeeAttributeValue{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("employee_id = ?", id).Delete(&models.RegistrationInvite{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Registration{}).Where("employee_id = ?", id).Update("employee_id", nil).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&employee).Error
+}
+
+// listDeletedWorkData группирует удалённые рабочие дни по сотрудникам. Рабочие данные
+// удалённых сотрудников в список не входят — они восстанавливаются вместе с сотрудником.
+func listDeletedWorkData(conn *gorm.DB) ([]models.TrashItem, error) {
+	var items []models.TrashItem
+	err := conn.Table("work_days wd").
+		Select("e.id, " + employeeNameSQL + " AS name, COUNT(*) AS count, MAX(wd.deleted_at) AS deleted_at").
+		Joins("JOIN employees e ON e.id = wd.employee_id AND e.deleted_at IS NULL").
+		Where("wd.deleted_at IS NOT NULL").
+		Group("e.id, e.last_name, e.first_name, e.middle_name").
+		Scan(&items).Error
+	return items, err
+}
+
+func restoreDeletedWorkData(tx *gorm.DB, employeeID uint) error {
+	if err := tx.First(&models.Employee{}, employeeID).Error; err != nil {
+		return err
+	}
+	var deleted int64
+	if err := tx.Unscoped().Model(&models.WorkDay{}).
+		Where("employee_id = ? AND deleted_at IS NOT NULL", employeeID).
+		Count(&deleted).Error; err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return restoreWorkData(tx, employeeID, nil)
+}
+
+func purgeDeletedWorkData(tx *gorm.DB, employeeID uint) error {
+	var deleted int64
+	if err := tx.Unscoped().Model(&models.WorkDay{}).
+		Where("employee_id = ? AND deleted_at IS NOT NULL", employeeID).
+		Count(&deleted).Error; err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return purgeWorkData(tx, employeeID, true)
+}
+
+// restoreWorkData восстанавливает удалённые рабочие дни сотрудника с процессами и метриками;
+// если deletedAt задано — только удалённые в этот момент.
+func restoreWorkData(tx *gorm.DB, employeeID uint, deletedAt *time.Time) error {
+	query := tx.Unscoped().Model(&models.WorkDay{}).Where("employee_id = ? AND deleted_at IS NOT NULL", employeeID)
+	if deletedAt != nil {
+		query = query.Where("deleted_at = ?", *deletedAt)
+	}
+	var ids []uint
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	for _, model := range []interface{}{&models.WorkProcess{}, &models.SatisfactionMetric{}} {
+		if err := tx.Unscoped().Model(model).
+			Where("work_day_id IN ? AND deleted_at IS NOT NULL", ids).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Unscoped().Model(&models.WorkDay{}).Where("id IN ?", ids).Update("deleted_at", nil).Error
+}
+
+// purgeWorkData окончательно удаляет рабочие дни сотрудника (только удалённые, если deletedOnly)
+// с процессами и метриками.
+func purgeWorkData(tx *gorm.DB, employeeID uint, deletedOnly bool) error {
+	query := tx.Unscoped().Model(&models.WorkDay{}).Where("employee_id = ?", employeeID)
+	if deletedOnly {
+		query = query.Where("deleted_at IS NOT NULL")
+	}
+	var ids []uint
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := tx.Unscoped().Where("work_day_id IN ?", ids).Delete(&models.WorkProcess{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("work_day_id IN ?", ids).Delete(&models.SatisfactionMetric{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&models.WorkDay{}).Error
+}
+
+// dictionaryTrash описывает справочник table. refs — ссылки, при которых запись
+// нельзя удалить окончательно, dependents — строки, удаляемые вместе с записью.
+func dictionaryTrash(table, auditType string, refs, dependents []trashRef) trashEntity {
+	return trashEntity{
+		auditType: auditType,
+		list: func(conn *gorm.DB) ([]models.TrashItem, error) {
+			var items []models.TrashItem
+			err := conn.Table(table).Select("id, name, deleted_at").Where("deleted_at IS NOT NULL").Scan(&items).Error
+			return items, err
+		},
+		restore: func(tx *gorm.DB, id uint) error {
+			res := tx.Table(table).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+			if res.Error == nil && res.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+			return res.Error
+		},
+		purge: func(tx *gorm.DB, id uint) error {
+			var deleted int64
+			if err := tx.Table(table).Where("id = ? AND deleted_at IS NOT NULL", id).Count(&deleted).Error; err != nil {
+				return err
+			}
+			if deleted == 0 {
+				return gorm.ErrRecordNotFound
+			}
+			for _, ref := range refs {
+				var used bool
+				if err := tx.Raw("SELECT EXISTS (SELECT 1 FROM "+ref.table+" WHERE "+ref.column+" = ?)", id).
+					Scan(&used).Error; err != nil {
+					return err
+				}
+				if used {
+					return ErrTrashInUse
+				}
+			}
+			for _, dep := range dependents {
+				if err := tx.Exec("DELETE FROM "+dep.table+" WHERE "+dep.column+" = ?", id).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Exec("DELETE FROM "+table+" WHERE id = ?", id).Error
+		},
+	}
+}
+
+// invalidatingPermissions сбрасывает кеш прав групп после восстановления
+// или удаления записи, когда транзакция зафиксирована.
+func invalidatingPermissions(entity trashEntity) trashEntity {
+	restore, purge := entity.restore, entity.purge
+	entity.restore = func(tx *gorm.DB, id uint) error {
+		defer AfterCommit(tx, InvalidatePermissions)
+		return restore(tx, id)
+	}
+	entity.purge = func(tx *gorm.DB, id uint) error {
+		defer AfterCommit(tx, InvalidatePermissions)
+		return purge(tx, id)
+	}
+	return entity
+}
+
+// customAttributeTrash описывает дополнительные поля сотрудников. Поле нельзя
+// восстановить, пока его код занят новым полем.
+func customAttributeTrash() trashEntity {
+	entity := dictionaryTrash("custom_attributes", "custom_attribute", nil,
+		[]trashRef{{"employee_attribute_values", "attribute_id"}, {"custom_attribute_options", "attribute_id"}})
+	restore := entity.restore
+	entity.restore = func(tx *gorm.DB, id uint) error {
+		var taken bool
+		if err := tx.Raw(`SELECT EXISTS (SELECT 1 FROM custom_attributes a
+			JOIN custom_attributes d ON d.code = a.code AND d.id = ? AND d.deleted_at IS NOT NULL
+			WHERE a.deleted_at IS NULL)`, id).Scan(&taken).Error; err != nil {
+			return err
+		}
+		if taken {
+			return ErrTrashCodeTaken
+		}
+		return restore(tx, id)
+	}
+	return entity
+}
+
+// fillDeletedBy заполняет DeletedBy по последнему событию удаления в журнале аудита.
+func fillDeletedBy(conn *gorm.DB, auditType string, items []models.TrashItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, strconv.FormatUint(uint64(item.ID), 10))
+	}
+
+	var rows []struct {
+		EntityID string
+		UserID   *uint
+		Login    string
+	}
+	if err := conn.Raw(`SELECT DISTINCT ON (a.entity_id) a.entity_id, a.user_id, COALESCE(u.login, '') AS login
+		FROM audit_events a LEFT JOIN users u ON u.id = a.user_id
+		WHERE a.action = ? AND a.entity_type = ? AND a.entity_id IN ?
+		ORDER BY a.entity_id, a.created_at DESC, a.id DESC`, models.AuditDelete, auditType, ids).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	byID := make(map[string]int, len(rows))
+	for i, r := range rows {
+		byID[r.EntityID] = i
+	}
+	for i := range items {
+		if j, ok := byID[ids[i]]; ok {
+			items[i].DeletedBy = rows[j].UserID
+			items[i].DeletedByLogin = rows[j].Login
+		}
+	}
+	return nil
+}
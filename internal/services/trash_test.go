@@ -0,0 +1,73 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestPurgeEmployeeKeepsDisabledUserLinks(t *testing.T) {
+	var userCount string
+	conn := openFakeDB(t, func(query string, args []driver.NamedValue) (fakeResult, error) {
+		switch {
+		case strings.HasPrefix(query, `SELECT * FROM "employees"`):
+			return fakeResult{
+				columns: []string{"id", "deleted_at"},
+				rows:    [][]driver.Value{{int64(5), time.Now().Add(-time.Hour)}},
+			}, nil
+		case strings.HasPrefix(query, `SELECT count(*) FROM "users"`):
+			userCount = query
+			// Учётная запись сотрудника отключена, но не удалена.
+			return fakeResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(1)}}}, nil
+		}
+		t.Fatalf("employee purged while a disabled user references it: %s", query)
+		return fakeResult{}, nil
+	})
+
+	if err := purgeEmployee(conn, 5); !errors.Is(err, ErrTrashInUse) {
+		t.Fatalf("got %v, want ErrTrashInUse", err)
+	}
+	if strings.Contains(userCount, "deleted_at") {
+		t.Errorf("disabled users excluded from the reference check: %s", userCount)
+	}
+}
+
+func TestRestoreAccessGroupInvalidatesPermissions(t *testing.T) {
+	conn := openFakeDB(t, func(query string, args []driver.NamedValue) (fakeResult, error) {
+		if strings.HasPrefix(query, `UPDATE "access_groups" SET "deleted_at"=$1`) {
+			return fakeResult{rowsAffected: 1}, nil
+		}
+		t.Fatalf("unexpected query: %s", query)
+		return fakeResult{}, nil
+	})
+
+	cached := func() bool {
+		groupPermissions.mu.RLock()
+		defer groupPermissions.mu.RUnlock()
+		return groupPermissions.byGroup != nil
+	}
+	groupPermissions.mu.Lock()
+	groupPermissions.byGroup = map[string]map[string]bool{}
+	groupPermissions.mu.Unlock()
+	t.Cleanup(InvalidatePermissions)
+
+	err := Transaction(conn, func(tx *gorm.DB) error {
+		if err := trashEntities[TrashAccessGroups].restore(tx, 3); err != nil {
+			return err
+		}
+		if !cached() {
+			t.Error("permission cache dropped before commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached() {
+		t.Error("permission cache kept after restoring an access group")
+	}
+}
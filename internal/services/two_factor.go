@@ -0,0 +1,217 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Назначение challenge-токена, выдаваемого на промежуточных шагах входа.
+const (
+	ChallengeTwoFactor      = "2fa"
+	ChallengeTwoFactorSetup = "2fa_setup"
+	ChallengePasswordChange = "password_change"
+)
+
+const (
+	ChallengeTokenTTL  = 5 * time.Minute
+	recoveryCodesCount = 10
+)
+
+var (
+	ErrChallengeInvalid     = errors.New("challenge token invalid or expired")
+	ErrTwoFactorCodeInvalid = errors.New("two-factor code invalid")
+	ErrTwoFactorNotSetUp    = errors.New("two-factor authentication not set up")
+)
+
+var (
+	twoFactorIssuer         = "Employee Dashboard"
+	twoFactorRequiredGroups []string
+)
+
+// InitTwoFactor задаёт название сервиса в приложении-аутентификаторе и группы,
+// для которых 2FA обязательна.
+func InitTwoFactor(issuer string, requiredGroups []string) {
+	if issuer != "" {
+		twoFactorIssuer = issuer
+	}
+	twoFactorRequiredGroups = requiredGroups
+}
+
+// TwoFactorRequired сообщает, обязательна ли 2FA для пользователя с такими группами.
+func TwoFactorRequired(groups []string) bool {
+	for _, g := range groups {
+		for _, r := range twoFactorRequiredGroups {
+			if g == r {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TwoFactorURI возвращает otpauth:// URI для секрета пользователя.
+func TwoFactorURI(login, secret string) string {
+	return TOTPURI(twoFactorIssuer, login, secret)
+}
+
+// GenerateChallengeToken выдаёт короткоживущий токен промежуточного шага входа.
+// Токен имеет тип TokenTypeChallenge и не принимается AuthMiddleware.
+func GenerateChallengeToken(user models.User, purpose string) (string, error) {
+	claims := jwt.MapClaims{
+		"typ":     TokenTypeChallenge,
+		"user_id": user.ID,
+		"purpose": purpose,
+		"ver":     user.TokenVersion,
+		"exp":     time.Now().Add(ChallengeTokenTTL).Unix(),
+	}
+
+	return SignToken(claims)
+}
+
+// ParseChallengeToken проверяет challenge-токен и загружает активного пользователя.
+func ParseChallengeToken(conn *gorm.DB, raw, purpose string) (models.User, error) {
+	var user models.User
+
+	claims, err := ParseToken(raw, TokenTypeChallenge)
+	if err != nil || claims["purpose"] != purpose {
+		return user, ErrChallengeInvalid
+	}
+	userID, _ := claims["user_id"].(float64)
+	version, _ := claims["ver"].(float64)
+
+	if err := conn.Preload("AccessGroups.AccessGroup").
+		Where("deleted_at IS NULL").
+		First(&user, uint(userID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, ErrChallengeInvalid
+		}
+		return user, err
+	}
+	if user.TokenVersion != int(version) {
+		return user, ErrChallengeInvalid
+	}
+	return user, nil
+}
+
+// SecondFactorStep возвращает шаг входа, которым пользователь подтверждает второй
+// фактор: ChallengeTwoFactor, ChallengeTwoFactorSetup или "", если 2FA не требуется.
+func SecondFactorStep(user models.User) string {
+	switch {
+	case user.TOTPEnabled:
+		return ChallengeTwoFactor
+	case TwoFactorRequired(GroupClaims(user)):
+		return ChallengeTwoFactorSetup
+	}
+	return ""
+}
+
+// VerifySecondFactor принимает код TOTP или неиспользованный код восстановления.
+// Каждый код TOTP принимается только один раз.
+func VerifySecondFactor(tx *gorm.DB, user models.User, code string, now time.Time) error {
+	if !user.TOTPEnabled || user.TOTPSecret == "" {
+		return ErrTwoFactorNotSetUp
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == TOTPDigits {
+		return acceptTOTP(tx, user, code, now)
+	}
+
+	res := tx.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, HashToken(normalizeRecoveryCode(code))).
+		Update("used_at", now)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrTwoFactorCodeInvalid
+	}
+	return nil
+}
+
+// EnableTOTP подтверждает подключение 2FA первым кодом из приложения.
+func EnableTOTP(tx *gorm.DB, user models.User, code string, now time.Time) error {
+	if user.TOTPSecret == "" {
+		return ErrTwoFactorNotSetUp
+	}
+	if err := acceptTOTP(tx, user, code, now); err != nil {
+		return err
+	}
+	return tx.Model(&models.User{}).Where("id = ?", user.ID).Update("totp_enabled", true).Error
+}
+
+// DisableTOTP отключает 2FA и удаляет коды восстановления.
+func DisableTOTP(tx *gorm.DB, userID uint) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"totp_secret":    "",
+		"totp_enabled":   false,
+		"totp_last_step": 0,
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
+
+// GenerateRecoveryCodes заменяет коды восстановления пользователя новыми.
+func GenerateRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodesCount)
+	rows := make([]models.RecoveryCode, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := hex.EncodeToString(b)
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		rows = append(rows, models.RecoveryCode{UserID: userID, CodeHash: HashToken(raw)})
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RecoveryCodesLeft возвращает число неиспользованных кодов восстановления.
+func RecoveryCodesLeft(conn *gorm.DB, userID uint) (int64, error) {
+	var n int64
+	err := conn.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&n).Error
+	return n, err
+}
+
+func acceptTOTP(tx *gorm.DB, user models.User, code string, now time.Time) error {
+	step, ok := ValidateTOTP(user.TOTPSecret, code, now)
+	if !ok {
+		return ErrTwoFactorCodeInvalid
+	}
+
+	// Условное обновление отклоняет повторное использование кода,
+	// в том числе при одновременных запросах.
+	res := tx.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrTwoFactorCodeInvalid
+	}
+	return nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
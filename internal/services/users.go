@@ -0,0 +1,123 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultUserGroup — код группы доступа, которая назначается новым пользователям.
+const DefaultUserGroup = "employee"
+
+var (
+	ErrLoginTaken          = errors.New("login already taken")
+	ErrEmployeeLinked      = errors.New("employee already has a user")
+	ErrAccessGroupNotFound = errors.New("access group not found")
+	ErrAlreadyInGroup      = errors.New("user already in group")
+	ErrNotInGroup          = errors.New("user not in group")
+)
+
+func CreateUser(tx *gorm.DB, login, passwordHash string, employeeID uint, groupCodes ...string) (*models.User, error) {
+	taken, err := LoginTaken(tx, login)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrLoginTaken
+	}
+
+	linked, err := EmployeeHasUser(tx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if linked {
+		return nil, ErrEmployeeLinked
+	}
+
+	user := models.User{
+		Login:      login,
+		Password:   passwordHash,
+		EmployeeID: employeeID,
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	if err := RecordPasswordHistory(tx, user.ID, passwordHash); err != nil {
+		return nil, err
+	}
+
+	for _, code := range groupCodes {
+		if err := AddUserToGroup(tx, user.ID, code); err != nil {
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+func AddUserToGroup(tx *gorm.DB, userID uint, groupCode string) error {
+	var group models.AccessGroup
+	if err := tx.Where("code = ?", groupCode).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAccessGroupNotFound
+		}
+		return err
+	}
+
+	var count int64
+	if err := tx.Model(&models.UserAccessGroup{}).
+		Where("user_id = ? AND access_group_id = ?", userID, group.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAlreadyInGroup
+	}
+
+	return tx.Create(&models.UserAccessGroup{
+		UserID:        userID,
+		AccessGroupID: group.ID,
+	}).Error
+}
+
+func RemoveUserFromGroup(tx *gorm.DB, userID uint, groupCode string) error {
+	res := tx.Where("user_id = ? AND access_group_id IN (?)", userID,
+		tx.Model(&models.AccessGroup{}).Select("id").Where("code = ?", groupCode)).
+		Delete(&models.UserAccessGroup{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotInGroup
+	}
+	return nil
+}
+
+func LoginTaken(tx *gorm.DB, login string) (bool, error) {
+	var count int64
+	err := tx.Model(&models.User{}).
+		Where("LOWER(login) = ?", strings.ToLower(login)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func EmployeeHasUser(tx *gorm.DB, employeeID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&models.User{}).
+		Where("employee_id = ? AND deleted_at IS NULL", employeeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GenerateInviteCode возвращает случайный код приглашения из 10 hex-символов.
+func GenerateInviteCode() (string, error) {
+	buf := make([]byte, 5)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(buf)), nil
+}
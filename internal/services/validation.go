@@ -0,0 +1,204 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+)
+
+// MinEmploymentAge — минимальный возраст сотрудника на дату приёма.
+const MinEmploymentAge = 14
+
+// FieldError — ошибка значения поля формы; Field совпадает с именем поля в JSON запроса.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError возвращается, если данные запроса не прошли проверку.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		fields = append(fields, f.Field+": "+f.Code)
+	}
+	return "validation failed: " + strings.Join(fields, ", ")
+}
+
+// Add добавляет ошибку поля.
+func (e *ValidationError) Add(field, code, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Err возвращает e, если есть ошибки, иначе nil.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ValidateEmployee проверяет карточку сотрудника: ФИО, даты, отдел, должность, оклад
+// и дополнительные поля. current — действующая кадровая запись при изменении (nil при
+// создании): неизменённые отдел, должность и оклад повторно не проверяются. Возвращает
+// разобранные даты и значения дополнительных полей для SaveEmployeeAttributes; nil —
+// при изменении дополнительные поля не переданы и не меняются.
+func ValidateEmployee(conn *gorm.DB, input models.EmployeeCreateRequest, current *models.EmployeeHR) (birthDate, hireDate time.Time, attributes map[uint]string, err error) {
+	v := &ValidationError{}
+
+	checkName(v, "last_name", input.LastName, true, "Укажите фамилию")
+	checkName(v, "first_name", input.FirstName, true, "Укажите имя")
+	checkName(v, "middle_name", input.MiddleName, false, "")
+
+	today := truncateDay(time.Now())
+	birthDate, birthErr := time.Parse("2006-01-02", input.BirthDate)
+	switch {
+	case input.BirthDate == "":
+		v.Add("birth_date", "required", "Укажите дату рождения")
+	case birthErr != nil:
+		v.Add("birth_date", "invalid_format", "Дата должна быть в формате ГГГГ-ММ-ДД")
+	case birthDate.After(today):
+		v.Add("birth_date", "in_future", "Дата рождения не может быть в будущем")
+	case birthDate.Year() < 1900:
+		v.Add("birth_date", "out_of_range", "Дата рождения не может быть раньше 1900 года")
+	}
+
+	hireDate, hireErr := time.Parse("2006-01-02", input.HireDate)
+	switch {
+	case hireErr != nil:
+		v.Add("hire_date", "invalid_format", "Дата должна быть в формате ГГГГ-ММ-ДД")
+	case hireDate.After(today.AddDate(1, 0, 0)):
+		v.Add("hire_date", "too_far", "Дата приёма не может быть позже чем через год")
+	case birthErr == nil && hireDate.Before(birthDate):
+		v.Add("hire_date", "before_birth_date", "Дата приёма раньше даты рождения")
+	case birthErr == nil && hireDate.Before(birthDate.AddDate(MinEmploymentAge, 0, 0)):
+		v.Add("hire_date", "too_young", fmt.Sprintf("На дату приёма сотруднику должно быть не меньше %d лет", MinEmploymentAge))
+	}
+
+	var before models.EmployeeHR
+	if current != nil {
+		before = *current
+	}
+	after := before
+	after.DepartmentID = input.DepartmentID
+	after.PositionID = input.PositionID
+	after.Salary = models.EncryptedFloat(input.Salary)
+	if err := checkAssignment(conn, v, before, after); err != nil {
+		return birthDate, hireDate, nil, err
+	}
+
+	if input.Attributes != nil || current == nil {
+		attrs, err := LoadAttributes(conn)
+		if err != nil {
+			return birthDate, hireDate, nil, err
+		}
+		attributes = checkAttributes(attrs, v, input.Attributes, true)
+	}
+
+	return birthDate, hireDate, attributes, v.Err()
+}
+
+// ValidateAssignment проверяет изменённые отдел, должность и оклад кадровой записи:
+// справочные записи существуют и не удалены, оклад неотрицателен и укладывается в вилку должности.
+func ValidateAssignment(conn *gorm.DB, before, after models.EmployeeHR) error {
+	v := &ValidationError{}
+	if err := checkAssignment(conn, v, before, after); err != nil {
+		return err
+	}
+	return v.Err()
+}
+
+func checkAssignment(conn *gorm.DB, v *ValidationError, before, after models.EmployeeHR) error {
+	if after.DepartmentID != before.DepartmentID {
+		var department models.Department
+		err := conn.Unscoped().First(&department, after.DepartmentID).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			v.Add("department_id", "not_found", "Отдел не найден")
+		case err != nil:
+			return err
+		case department.DeletedAt.Valid:
+			v.Add("department_id", "inactive", "Отдел «"+department.Name+"» удалён из справочника")
+		}
+	}
+
+	positionChanged := after.PositionID != before.PositionID
+	salaryChanged := after.Salary != before.Salary
+	if salaryChanged && after.Salary < 0 {
+		v.Add("salary", "negative", "Оклад не может быть отрицательным")
+		salaryChanged = false
+	}
+	if !positionChanged && !salaryChanged {
+		return nil
+	}
+
+	var position models.Position
+	err := conn.Unscoped().First(&position, after.PositionID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if positionChanged {
+			v.Add("position_id", "not_found", "Должность не найдена")
+		}
+		return nil
+	case err != nil:
+		return err
+	case positionChanged && position.DeletedAt.Valid:
+		v.Add("position_id", "inactive", "Должность «"+position.Name+"» удалена из справочника")
+	}
+
+	if after.Salary < 0 {
+		return nil
+	}
+	salary := float64(after.Salary)
+	if (position.MinSalary != nil && salary < *position.MinSalary) ||
+		(position.MaxSalary != nil && salary > *position.MaxSalary) {
+		v.Add("salary", "out_of_range", "Оклад вне вилки должности «"+position.Name+"»: "+salaryRange(position))
+	}
+	return nil
+}
+
+// ValidateSalaryRange проверяет вилку оклада должности.
+func ValidateSalaryRange(minSalary, maxSalary *float64) error {
+	v := &ValidationError{}
+	if minSalary != nil && *minSalary < 0 {
+		v.Add("min_salary", "negative", "Оклад не может быть отрицательным")
+	}
+	if maxSalary != nil && *maxSalary < 0 {
+		v.Add("max_salary", "negative", "Оклад не может быть отрицательным")
+	}
+	if minSalary != nil && maxSalary != nil && *minSalary > *maxSalary {
+		v.Add("max_salary", "less_than_min", "Максимальный оклад меньше минимального")
+	}
+	return v.Err()
+}
+
+func checkName(v *ValidationError, field, value string, required bool, requiredMessage string) {
+	value = strings.TrimSpace(value)
+	switch {
+	case required && value == "":
+		v.Add(field, "required", requiredMessage)
+	case utf8.RuneCountInString(value) > 255:
+		v.Add(field, "too_long", "Не больше 255 символов")
+	}
+}
+
+func salaryRange(p models.Position) string {
+	switch {
+	case p.MinSalary != nil && p.MaxSalary != nil:
+		return fmt.Sprintf("от %.2f до %.2f", *p.MinSalary, *p.MaxSalary)
+	case p.MinSalary != nil:
+		return fmt.Sprintf("от %.2f", *p.MinSalary)
+	case p.MaxSalary != nil:
+		return fmt.Sprintf("до %.2f", *p.MaxSalary)
+	}
+	return "не задана"
+}
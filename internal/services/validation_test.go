@@ -0,0 +1,149 @@
+package services
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+)
+
+// dictionaryDB отвечает на запросы справочников: отдел 1 действует, отдел 2 удалён;
+// должность 10 с вилкой 50 000–100 000, должность 11 удалена, у должности 12 вилки нет.
+func dictionaryDB(t *testing.T) fakeHandler {
+	deleted := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	departments := map[int64][]driver.Value{
+		1: {int64(1), "Бухгалтерия", nil},
+		2: {int64(2), "Склад", deleted},
+	}
+	positions := map[int64][]driver.Value{
+		10: {int64(10), "Экономист", nil, 50000.0, 100000.0},
+		11: {int64(11), "Кладовщик", deleted, nil, nil},
+		12: {int64(12), "Стажёр", nil, nil, nil},
+	}
+
+	return func(query string, args []driver.NamedValue) (fakeResult, error) {
+		switch {
+		case strings.HasPrefix(query, `SELECT * FROM "departments"`):
+			res := fakeResult{columns: []string{"id", "name", "deleted_at"}}
+			if row, ok := departments[args[0].Value.(int64)]; ok {
+				res.rows = [][]driver.Value{row}
+			}
+			return res, nil
+		case strings.HasPrefix(query, `SELECT * FROM "positions"`):
+			res := fakeResult{columns: []string{"id", "name", "deleted_at", "min_salary", "max_salary"}}
+			if row, ok := positions[args[0].Value.(int64)]; ok {
+				res.rows = [][]driver.Value{row}
+			}
+			return res, nil
+		case strings.HasPrefix(query, `SELECT * FROM "custom_attributes"`):
+			return fakeResult{columns: []string{"id"}}, nil
+		}
+		t.Fatalf("unexpected query: %s", query)
+		return fakeResult{}, nil
+	}
+}
+
+func fieldCodes(err error) []string {
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		return nil
+	}
+	codes := make([]string, 0, len(verr.Fields))
+	for _, f := range verr.Fields {
+		codes = append(codes, f.Field+":"+f.Code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func TestValidateEmployee(t *testing.T) {
+	conn := openFakeDB(t, dictionaryDB(t))
+	today := time.Now()
+	valid := models.EmployeeCreateRequest{
+		LastName:     "Иванова",
+		FirstName:    "Анна",
+		DepartmentID: 1,
+		PositionID:   10,
+		BirthDate:    "1990-05-01",
+		HireDate:     "2020-01-15",
+		Salary:       75000,
+	}
+
+	cases := []struct {
+		name   string
+		modify func(*models.EmployeeCreateRequest)
+		want   []string
+	}{
+		{"valid", func(*models.EmployeeCreateRequest) {}, nil},
+		{"names", func(r *models.EmployeeCreateRequest) {
+			r.LastName, r.FirstName, r.MiddleName = " ", "", strings.Repeat("я", 256)
+		}, []string{"first_name:required", "last_name:required", "middle_name:too_long"}},
+		{"birth date missing", func(r *models.EmployeeCreateRequest) { r.BirthDate = "" }, []string{"birth_date:required"}},
+		{"birth date format", func(r *models.EmployeeCreateRequest) { r.BirthDate = "01.05.1990" }, []string{"birth_date:invalid_format"}},
+		{"birth date in future", func(r *models.EmployeeCreateRequest) {
+			r.BirthDate = today.AddDate(0, 0, 2).Format("2006-01-02")
+			r.HireDate = today.AddDate(0, 0, 3).Format("2006-01-02")
+		}, []string{"birth_date:in_future", "hire_date:too_young"}},
+		{"birth date before 1900", func(r *models.EmployeeCreateRequest) { r.BirthDate = "1899-12-31" }, []string{"birth_date:out_of_range"}},
+		{"hire date format", func(r *models.EmployeeCreateRequest) { r.HireDate = "2020-13-01" }, []string{"hire_date:invalid_format"}},
+		{"hire date too far", func(r *models.EmployeeCreateRequest) {
+			r.HireDate = today.AddDate(1, 0, 2).Format("2006-01-02")
+		}, []string{"hire_date:too_far"}},
+		{"hire date next year", func(r *models.EmployeeCreateRequest) {
+			r.HireDate = today.AddDate(0, 11, 0).Format("2006-01-02")
+		}, nil},
+		{"hire before birth", func(r *models.EmployeeCreateRequest) { r.HireDate = "1989-12-31" }, []string{"hire_date:before_birth_date"}},
+		{"too young", func(r *models.EmployeeCreateRequest) { r.HireDate = "2004-04-30" }, []string{"hire_date:too_young"}},
+		{"minimum age", func(r *models.EmployeeCreateRequest) { r.HireDate = "2004-05-01" }, nil},
+		{"department not found", func(r *models.EmployeeCreateRequest) { r.DepartmentID = 99 }, []string{"department_id:not_found"}},
+		{"department deleted", func(r *models.EmployeeCreateRequest) { r.DepartmentID = 2 }, []string{"department_id:inactive"}},
+		{"position not found", func(r *models.EmployeeCreateRequest) { r.PositionID = 99 }, []string{"position_id:not_found"}},
+		{"position deleted", func(r *models.EmployeeCreateRequest) { r.PositionID, r.Salary = 11, 0 }, []string{"position_id:inactive"}},
+		{"salary below band", func(r *models.EmployeeCreateRequest) { r.Salary = 49999.99 }, []string{"salary:out_of_range"}},
+		{"salary above band", func(r *models.EmployeeCreateRequest) { r.Salary = 100000.01 }, []string{"salary:out_of_range"}},
+		{"salary on band edge", func(r *models.EmployeeCreateRequest) { r.Salary = 100000 }, nil},
+		{"salary negative", func(r *models.EmployeeCreateRequest) { r.Salary = -1 }, []string{"salary:negative"}},
+		{"no band", func(r *models.EmployeeCreateRequest) { r.PositionID, r.Salary = 12, 1 }, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := valid
+			tc.modify(&input)
+			_, _, _, err := ValidateEmployee(conn, input, nil)
+			if got := fieldCodes(err); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("errors = %v (%v), want %v", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateAssignment(t *testing.T) {
+	conn := openFakeDB(t, dictionaryDB(t))
+	// Сотрудник числится в удалённых отделе и должности с окладом вне вилки.
+	current := models.EmployeeHR{DepartmentID: 2, PositionID: 11, Salary: 30000}
+
+	cases := []struct {
+		name   string
+		modify func(*models.EmployeeHR)
+		want   []string
+	}{
+		{"unchanged", func(*models.EmployeeHR) {}, nil},
+		{"salary changed on deleted position", func(hr *models.EmployeeHR) { hr.Salary = 35000 }, nil},
+		{"moved to active", func(hr *models.EmployeeHR) { hr.DepartmentID, hr.PositionID, hr.Salary = 1, 10, 60000 }, nil},
+		{"moved keeping old salary", func(hr *models.EmployeeHR) { hr.PositionID = 10 }, []string{"salary:out_of_range"}},
+		{"salary negative", func(hr *models.EmployeeHR) { hr.Salary = -100 }, []string{"salary:negative"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			after := current
+			tc.modify(&after)
+			if got := fieldCodes(ValidateAssignment(conn, current, after)); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("errors = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
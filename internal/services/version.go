@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/MarBalueva/dashboard_efficiency/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrVersionConflict = errors.New("employee was modified by another request")
+	ErrInvalidIfMatch  = errors.New("invalid If-Match header")
+)
+
+// EmployeeETag возвращает ETag карточки сотрудника для версии version.
+func EmployeeETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// ParseIfMatch разбирает заголовок If-Match с ETag из EmployeeETag (допускается
+// слабый W/"…"). Возвращает 0, если заголовка нет или он равен "*".
+func ParseIfMatch(header string) (uint, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	tag := strings.TrimPrefix(header, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, ErrInvalidIfMatch
+	}
+	version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version == 0 {
+		return 0, ErrInvalidIfMatch
+	}
+	return uint(version), nil
+}
+
+// BumpEmployeeVersion увеличивает версию карточки сотрудника и блокирует её строку
+// до конца транзакции. Если expected не 0, а текущая версия другая, возвращает
+// ErrVersionConflict. Возвращает новую версию.
+func BumpEmployeeVersion(tx *gorm.DB, employeeID, expected uint) (uint, error) {
+	var employee models.Employee
+	query := tx.Model(&employee).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "version"}}}).
+		Where("id = ?", employeeID)
+	if expected != 0 {
+		query = query.Where("version = ?", expected)
+	}
+	res := query.Update("version", gorm.Expr("version + 1"))
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return employee.Version, nil
+	}
+
+	if err := tx.Select("id").First(&employee, employeeID).Error; err != nil {
+		return 0, err
+	}
+	return 0, ErrVersionConflict
+}